@@ -0,0 +1,300 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services"
+)
+
+// component is a services.Serviceable that has been registered with the application
+// along with the names of the components that must be started before it
+type component struct {
+	name      string
+	service   services.Serviceable
+	dependsOn []string
+
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+
+	// starting yields the result of a Start() that failed to complete in time
+	starting <-chan error
+}
+
+// start will invoke the component's Start() returning an error should it fail, or
+// should it fail to complete within the allotted time
+//
+// Start() is given the application's context, which is not cancelled should it fail to
+// complete in time, so it may still go on to start the component... should it do so,
+// stop() will then stop it
+func (c *component) start(ctx context.Context, grp *errgroup.Group) error {
+	result := make(chan error, 1)
+	c.starting = nil
+
+	err := withDeadline(ctx, c.startTimeout, func(ctx context.Context) error {
+		err := c.service.Start(ctx, grp)
+		result <- err
+
+		return err
+	})
+	if err != nil {
+		if errs.GetType(err) == errs.ErrTypeTimeout {
+			c.starting = result
+		}
+
+		return errs.Wrapf(err, errs.GetType(err), "unable to start component '%s'", c.name)
+	}
+
+	return nil
+}
+
+// stop will invoke the component's Stop() returning an error should it fail, or
+// should it fail to complete within the allotted time (or before the deadline)
+func (c *component) stop(ctx context.Context) error {
+	starting := c.starting
+
+	err := withDeadline(ctx, c.stopTimeout, func(context.Context) error {
+		// a component whose Start() was late is only stopped once (and if) it has started...
+		if starting != nil {
+			if err := <-starting; err != nil {
+				return nil
+			}
+		}
+
+		return c.service.Stop()
+	})
+	if err != nil {
+		return errs.Wrapf(err, errs.GetType(err), "unable to stop component '%s'", c.name)
+	}
+
+	return nil
+}
+
+// registerComponent will add the component to the application, replacing any
+// existing component having the same name
+func (a *application) registerComponent(c *component) {
+	for idx, existing := range a.components {
+		if existing.name == c.name {
+			a.components[idx] = c
+			return
+		}
+	}
+
+	a.components = append(a.components, c)
+}
+
+// getComponent will return the registered component with the specified name
+func (a *application) getComponent(name string) (*component, bool) {
+	for _, c := range a.components {
+		if c.name == name {
+			return c, true
+		}
+	}
+
+	return nil, false
+}
+
+// registerDefaultComponents will register the Database and Server found in the
+// application context (if any) unless they have already been registered... the
+// Server depends upon the Database so that we don't accept traffic we can't serve
+func (a *application) registerDefaultComponents() {
+	if a.AppContext.Database != nil {
+		if _, exists := a.getComponent(DatabaseComponentName); !exists {
+			a.registerComponent(newComponent(DatabaseComponentName, a.AppContext.Database))
+		}
+	}
+
+	if a.AppContext.Server != nil {
+		if _, exists := a.getComponent(ServerComponentName); !exists {
+			var dependsOn []string
+			if _, exists := a.getComponent(DatabaseComponentName); exists {
+				dependsOn = append(dependsOn, DatabaseComponentName)
+			}
+
+			a.registerComponent(newComponent(ServerComponentName, a.AppContext.Server, dependsOn...))
+		}
+	}
+}
+
+// applyComponentTimeouts will apply any timeout overrides to the registered components
+func (a *application) applyComponentTimeouts() {
+	for _, c := range a.components {
+		if timeouts, exists := a.timeouts[c.name]; exists {
+			if timeouts.start != 0 {
+				c.startTimeout = timeouts.start
+			}
+			if timeouts.stop != 0 {
+				c.stopTimeout = timeouts.stop
+			}
+		}
+	}
+}
+
+// startComponents will start each of the registered components in dependency order
+//
+// Should any component fail to start, those that have already been started will be
+// stopped (in reverse order) before the error is returned
+func (a *application) startComponents(ctx context.Context, grp *errgroup.Group) error {
+	ordered, err := sortComponents(a.components)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range ordered {
+		a.AppContext.Logger.WithCtx(ctx).Debugf("starting component '%s'", c.name)
+
+		err = c.start(ctx, grp)
+		if err != nil {
+			// a component that was late to start is stopped should it go on to start...
+			if c.starting != nil {
+				a.started = append(a.started, c)
+			}
+
+			a.AppContext.Logger.WithCtx(ctx).Error("component startup failed, rolling back", err)
+			_ = a.stopComponents(context.Background())
+
+			return err
+		}
+
+		a.started = append(a.started, c)
+	}
+
+	return nil
+}
+
+// stopComponents will stop each of the started components in the reverse of the
+// order in which they were started... a failure to stop one component will not
+// prevent the remaining components from being stopped
+//
+// Should the context's deadline pass, the remaining components will be reported as
+// having failed to stop without waiting any further
+func (a *application) stopComponents(ctx context.Context) error {
+	var errList []string
+
+	for idx := len(a.started) - 1; idx >= 0; idx-- {
+		c := a.started[idx]
+
+		a.AppContext.Logger.WithCtx(a.AppContext.RootCtx).Debugf("stopping component '%s'", c.name)
+
+		err := c.stop(ctx)
+		if err != nil {
+			a.AppContext.Logger.WithCtx(a.AppContext.RootCtx).Error("component shutdown failed", err)
+			errList = append(errList, err.Error())
+		}
+	}
+
+	a.started = nil
+
+	if len(errList) > 0 {
+		return errs.Errorf(errs.ErrTypeUnknown, "unable to stop all components: %s", strings.Join(errList, "; "))
+	}
+
+	return nil
+}
+
+// newComponent returns a component configured with the default timeouts
+func newComponent(name string, service services.Serviceable, dependsOn ...string) *component {
+	return &component{
+		name:         name,
+		service:      service,
+		dependsOn:    dependsOn,
+		startTimeout: DefaultComponentStartTimeout,
+		stopTimeout:  DefaultComponentStopTimeout,
+	}
+}
+
+// sortComponents will return the components in topological (dependency) order, where
+// components that do not depend upon one another retain their registration order
+func sortComponents(components []*component) ([]*component, error) {
+	byName := make(map[string]*component, len(components))
+	for _, c := range components {
+		byName[c.name] = c
+	}
+
+	// determine how many dependencies each component is waiting on...
+	pending := make(map[string]int, len(components))
+	for _, c := range components {
+		for _, dep := range c.dependsOn {
+			if _, exists := byName[dep]; !exists {
+				return nil, errs.Errorf(errs.ErrTypeConfiguration, "component '%s' depends on unknown component '%s'", c.name, dep)
+			}
+		}
+
+		pending[c.name] = len(c.dependsOn)
+	}
+
+	ordered := make([]*component, 0, len(components))
+	resolved := make(map[string]bool, len(components))
+
+	for len(ordered) < len(components) {
+		progress := false
+
+		for _, c := range components {
+			if resolved[c.name] || pending[c.name] > 0 {
+				continue
+			}
+
+			ordered = append(ordered, c)
+			resolved[c.name] = true
+			progress = true
+
+			for _, other := range components {
+				for _, dep := range other.dependsOn {
+					if dep == c.name {
+						pending[other.name]--
+					}
+				}
+			}
+
+			// restart so that registration order is honored as much as possible
+			break
+		}
+
+		if !progress {
+			var cyclic []string
+			for _, c := range components {
+				if !resolved[c.name] {
+					cyclic = append(cyclic, c.name)
+				}
+			}
+
+			return nil, errs.Errorf(errs.ErrTypeConfiguration, "circular dependency between components: %s", strings.Join(cyclic, ", "))
+		}
+	}
+
+	return ordered, nil
+}
+
+// withDeadline will invoke the provided function returning its error, or a timeout
+// error should it fail to complete within the specified duration or before the
+// context is done... a timeout of zero will rely solely upon the context
+//
+// The function is given the context itself, rather than one bounded by the timeout, as
+// a component's Start() may hand it to work that outlives the call (i.e. grp.Go)
+func withDeadline(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		expired = timer.C
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- fn(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-expired:
+		return errs.Errorf(errs.ErrTypeTimeout, "did not complete within %s", timeout)
+	case <-ctx.Done():
+		return errs.Wrap(ctx.Err(), errs.ErrTypeTimeout, "did not complete in time")
+	}
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// fakeService records the order in which it is started/stopped
+type fakeService struct {
+	name     string
+	events   *[]string
+	startErr error
+	delay    time.Duration
+}
+
+func (f *fakeService) Start(context.Context, *errgroup.Group) error {
+	time.Sleep(f.delay)
+
+	if f.startErr != nil {
+		return f.startErr
+	}
+
+	*f.events = append(*f.events, "start:"+f.name)
+	return nil
+}
+
+func (f *fakeService) Stop() error {
+	*f.events = append(*f.events, "stop:"+f.name)
+	return nil
+}
+
+// startFunc is a service whose Start() is provided by the test
+type startFunc struct {
+	fn func(context.Context) error
+}
+
+func (s *startFunc) Start(ctx context.Context, _ *errgroup.Group) error { return s.fn(ctx) }
+func (s *startFunc) Stop() error                                        { return nil }
+
+type ComponentTestSuite struct {
+	suite.Suite
+
+	app    application
+	events []string
+}
+
+func (c *ComponentTestSuite) SetupTest() {
+	c.events = nil
+	c.app = application{
+		timeouts: make(map[string]componentTimeouts),
+		AppContext: &shared.ApplicationContext{
+			RootCtx: context.Background(),
+			Logger:  utils.NewLogger("INFO"),
+		},
+	}
+}
+
+func (c *ComponentTestSuite) TestStart_HonorsDependencyOrder() {
+	c.register("api", nil, "cache", "db")
+	c.register("cache", nil, "db")
+	c.register("db", nil)
+	c.register("consumer", nil)
+
+	err := c.app.startComponents(context.Background(), new(errgroup.Group))
+	c.NoError(err)
+	c.Equal([]string{"start:db", "start:cache", "start:api", "start:consumer"}, c.events)
+
+	c.events = nil
+
+	err = c.app.stopComponents(context.Background())
+	c.NoError(err)
+	c.Equal([]string{"stop:consumer", "stop:api", "stop:cache", "stop:db"}, c.events)
+}
+
+func (c *ComponentTestSuite) TestStart_FailureRollsBackStartedComponents() {
+	c.register("db", nil)
+	c.register("cache", nil, "db")
+	c.register("api", fmt.Errorf("port in use"), "cache")
+
+	err := c.app.startComponents(context.Background(), new(errgroup.Group))
+	c.ErrorContains(err, "api")
+	c.Equal([]string{"start:db", "start:cache", "stop:cache", "stop:db"}, c.events)
+}
+
+func (c *ComponentTestSuite) TestStart_TimeoutRollsBackStartedComponents() {
+	c.register("db", nil)
+	c.app.registerComponent(&component{
+		name:         "slow",
+		service:      &fakeService{name: "slow", events: new([]string), delay: 50 * time.Millisecond},
+		dependsOn:    []string{"db"},
+		startTimeout: 5 * time.Millisecond,
+	})
+
+	err := c.app.startComponents(context.Background(), new(errgroup.Group))
+	c.Equal(errs.ErrTypeTimeout, errs.GetType(err))
+	c.Equal([]string{"start:db", "stop:db"}, c.events)
+}
+
+func (c *ComponentTestSuite) TestStart_LateStartIsStoppedOnRollback() {
+	c.register("db", nil)
+	c.app.registerComponent(&component{
+		name:         "slow",
+		service:      &fakeService{name: "slow", events: &c.events, delay: 50 * time.Millisecond},
+		dependsOn:    []string{"db"},
+		startTimeout: 5 * time.Millisecond,
+		stopTimeout:  time.Second,
+	})
+
+	err := c.app.startComponents(context.Background(), new(errgroup.Group))
+	c.Equal(errs.ErrTypeTimeout, errs.GetType(err))
+	c.Equal([]string{"start:db", "start:slow", "stop:slow", "stop:db"}, c.events)
+	c.Empty(c.app.started)
+}
+
+func (c *ComponentTestSuite) TestStart_ContextOutlivesStart() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	grp, gCtx := errgroup.WithContext(ctx)
+
+	running := make(chan struct{})
+	stopped := make(chan struct{})
+
+	c.app.registerComponent(&component{
+		name: "consumer",
+		service: &startFunc{func(ctx context.Context) error {
+			grp.Go(func() error {
+				close(running)
+				<-ctx.Done()
+				close(stopped)
+				return nil
+			})
+			return nil
+		}},
+		startTimeout: 5 * time.Millisecond,
+		stopTimeout:  time.Second,
+	})
+
+	c.Require().NoError(c.app.startComponents(gCtx, grp))
+	<-running
+
+	select {
+	case <-stopped:
+		c.Fail("the component's work was cancelled once it had started")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		c.Fail("the component's work should end with the application's context")
+	}
+}
+
+func (c *ComponentTestSuite) TestSort_CircularDependencyIsAnError() {
+	c.register("a", nil, "b")
+	c.register("b", nil, "a")
+
+	_, err := sortComponents(c.app.components)
+	c.Equal(errs.ErrTypeConfiguration, errs.GetType(err))
+}
+
+func (c *ComponentTestSuite) TestSort_UnknownDependencyIsAnError() {
+	c.register("a", nil, "missing")
+
+	_, err := sortComponents(c.app.components)
+	c.ErrorContains(err, "missing")
+}
+
+func (c *ComponentTestSuite) register(name string, startErr error, dependsOn ...string) {
+	c.app.registerComponent(newComponent(name, &fakeService{name: name, events: &c.events, startErr: startErr}, dependsOn...))
+}
+
+func TestComponents(t *testing.T) {
+	suite.Run(t, new(ComponentTestSuite))
+}
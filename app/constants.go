@@ -1,9 +1,29 @@
 package app
 
+import "time"
+
+// nolint: unused
+const (
+	DefaultComponentStartTimeout = 30 * time.Second
+	DefaultComponentStopTimeout  = 15 * time.Second
+
+	DefaultDrainPeriod     = 0 * time.Second
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// nolint: unused
+const (
+	DatabaseComponentName = "database"
+	ServerComponentName   = "server"
+)
+
 // nolint: unused
 const (
 	AppEnvironEnvKey = "ENV"
 	AppNameEnvKey    = "APPLICATION_NAME"
 	AppVersionEnvKey = "APPLICATION_VERSION"
 	AppCommitEnvKey  = "APPLICATION_COMMIT"
+
+	DrainPeriodEnvKey     = "APPLICATION_DRAIN_PERIOD_SECS"
+	ShutdownTimeoutEnvKey = "APPLICATION_SHUTDOWN_TIMEOUT_SECS"
 )
@@ -3,10 +3,12 @@ package app
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/go-playground/validator"
 
 	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/health"
 	"github.com/djmarrerajr/common-lib/observability/metrics"
 	"github.com/djmarrerajr/common-lib/observability/tracing"
 	"github.com/djmarrerajr/common-lib/services/api"
@@ -17,13 +19,14 @@ import (
 // NewWithApiFromEnv will instantiate and return a standardized, albeit
 // functionally limited, application that includes:
 //   - a structured logger
-//   - a signal handler (USR1 = toggle debug logging, INT = shutdown)
+//   - a signal handler (USR1 = toggle debug logging, INT/TERM/QUIT = shutdown)
+//   - a registry of liveness/readiness health checks
 //   - a basic HTTP API that:
-//     ... responds to '/health' with an HTTP-200
+//     ... responds to '/health', '/health/live' and '/health/ready' with the results of the health checks
 //     ... responds to '/metrics' with Prometheus data
 //
 // NOTE: A Database adapter can be added to the base application via the
-// app.Option (i.e. WithCockroachDBFromEnv)
+// app.Option (i.e. WithCockroachDBFromEnv or, for offline use, WithSQLiteDB)
 func NewWithApiFromEnv(env utils.Environ, opts ...Option) (*application, error) {
 	var err error
 
@@ -37,6 +40,9 @@ func NewWithApiFromEnv(env utils.Environ, opts ...Option) (*application, error)
 		return nil, errs.Wrap(err, errs.ErrTypeConfiguration, "while instantiating metrics collector")
 	}
 
+	// export the results of our health checks now that we have a collector...
+	health.WithCollector(app.AppContext.Collector, shared.MetricFilterLabels(), shared.MetricFilterValues(app.AppContext.RootCtx))(app.AppContext.Health)
+
 	app.AppContext.Tracer, app.AppContext.Closer, err = tracing.NewTracerFromEnv(env, *app.AppContext, app.name, app.version)
 	if err != nil {
 		return nil, errs.Wrap(err, errs.ErrTypeConfiguration, "while instantiating tracer")
@@ -88,18 +94,37 @@ func createInitialApplication(env utils.Environ) (application, error) {
 		ctx = utils.AddFieldToContext(ctx, "commitId", commit)
 	}
 
-	return application{
+	drainPeriod, _, err := env.GetInt(DrainPeriodEnvKey)
+	if err != nil {
+		return application{}, errs.WithType(err, errs.ErrTypeConfiguration)
+	}
+
+	shutdownTimeout, _, err := env.GetInt(ShutdownTimeoutEnvKey)
+	if err != nil {
+		return application{}, errs.WithType(err, errs.ErrTypeConfiguration)
+	}
+
+	app := application{
 		name:    appName,
 		version: appVrsn,
 		commit:  commit,
 
 		env:            env,
 		signalHandlers: make(map[os.Signal]signalHandler),
+		timeouts:       make(map[string]componentTimeouts),
+
+		drainPeriod:     DefaultDrainPeriod,
+		shutdownTimeout: DefaultShutdownTimeout,
 
 		AppContext: &shared.ApplicationContext{
 			RootCtx:   ctx,
 			Logger:    utils.NewLoggerFromEnv().Named(appName).WithCtx(ctx),
 			Validator: validator.New(),
+			Health:    health.NewRegistry(),
 		},
-	}, nil
+	}
+
+	WithShutdownTimeouts(time.Duration(drainPeriod)*time.Second, time.Duration(shutdownTimeout)*time.Second)(&app)
+
+	return app, nil
 }
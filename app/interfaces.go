@@ -5,3 +5,9 @@ type Runnable interface {
 }
 
 type Option func(*application)
+
+// drainable is implemented by those components that are able to stop accepting
+// new work (i.e. report themselves as not ready) while completing existing work
+type drainable interface {
+	Drain()
+}
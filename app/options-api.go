@@ -42,6 +42,30 @@ func WithRequestHandler(path string, handler shared.RequestHandlerFunc, reqStruc
 	}
 }
 
+// WithRequestHandlerOptions operates like WithRequestHandler except that the route is
+// configured using RouteOption(s), i.e. api.RouteMethods, api.RouteSummary, api.RouteResponse
+func WithRequestHandlerOptions(path string, handler shared.RequestHandlerFunc, reqStruct any, options ...api.RouteOption) Option {
+	return func(a *application) {
+		a.AppContext.Server.(*api.Server).DefineRequestHandlerWithOptions(path, handler, reqStruct, options...)
+	}
+}
+
+// WithStreamHandler is a convenience function that allows for the definition of an API
+// stream handler (see api.Server.DefineStreamHandler) without having to replace the default API
+func WithStreamHandler(path string, handler api.StreamHandlerFunc, reqStruct any, options ...api.RouteOption) Option {
+	return func(a *application) {
+		a.AppContext.Server.(*api.Server).DefineStreamHandler(path, handler, reqStruct, options...)
+	}
+}
+
+// WithWebSocketHandler is a convenience function that allows for the definition of an API
+// WebSocket handler (see api.Server.DefineWebSocketHandler) without having to replace the default API
+func WithWebSocketHandler(path string, handler api.WebSocketHandlerFunc, msgStruct any, options ...api.RouteOption) Option {
+	return func(a *application) {
+		a.AppContext.Server.(*api.Server).DefineWebSocketHandler(path, handler, msgStruct, options...)
+	}
+}
+
 // WithEndpoint is a convenience function that allows for the definition of an API
 // route handler without having to replace the default API
 // func WithEndpoint(path string, reqType any, handler shared.HandlerFunc, methods ...string) Option {
@@ -2,6 +2,7 @@ package app
 
 import (
 	"os"
+	"time"
 
 	"github.com/djmarrerajr/common-lib/utils"
 )
@@ -13,6 +14,21 @@ type signalHandler func(utils.Logger)
 func WithSignalHandler(sig os.Signal, fn signalHandler) Option {
 	return func(a *application) {
 		a.signalHandlers[sig] = fn
+	}
+}
 
+// WithShutdownTimeouts will override the length of time the application will
+// continue to serve traffic, while reporting itself as not ready, after being
+// asked to terminate (drain) as well as the total length of time it will wait
+// for its components to shutdown... a zero value will leave the respective
+// value unchanged
+func WithShutdownTimeouts(drain, total time.Duration) Option {
+	return func(a *application) {
+		if drain != 0 {
+			a.drainPeriod = drain
+		}
+		if total != 0 {
+			a.shutdownTimeout = total
+		}
 	}
 }
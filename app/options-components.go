@@ -0,0 +1,35 @@
+package app
+
+import (
+	"time"
+
+	"github.com/djmarrerajr/common-lib/services"
+)
+
+// componentTimeouts holds the per-component overrides of the default timeouts
+type componentTimeouts struct {
+	start time.Duration
+	stop  time.Duration
+}
+
+// WithComponent registers a named services.Serviceable with the application
+// along with the names of the components that must be started before it
+//
+// Components are started in dependency order and stopped in reverse order, the
+// Database and Server found in the application context are registered for you
+// as "database" and "server" respectively (with the server depending upon the
+// database) unless a component with that name has already been registered
+func WithComponent(name string, service services.Serviceable, dependsOn ...string) Option {
+	return func(a *application) {
+		a.registerComponent(newComponent(name, service, dependsOn...))
+	}
+}
+
+// WithComponentTimeouts will override the length of time the application will
+// wait for the named component to start and to stop... a zero value will leave
+// the respective default in place
+func WithComponentTimeouts(name string, start, stop time.Duration) Option {
+	return func(a *application) {
+		a.timeouts[name] = componentTimeouts{start, stop}
+	}
+}
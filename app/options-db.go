@@ -3,6 +3,7 @@ package app
 import (
 	"github.com/djmarrerajr/common-lib/services/db"
 	"github.com/djmarrerajr/common-lib/services/db/cockroach"
+	"github.com/djmarrerajr/common-lib/services/db/sqlite"
 	"github.com/djmarrerajr/common-lib/utils"
 )
 
@@ -22,3 +23,15 @@ func WithCockroachDBFromEnv(env utils.Environ, options ...cockroach.Option) Opti
 		a.AppContext.Database = s
 	}
 }
+
+// WithSQLiteDB will add a SQLite database adapter to the application, allowing it to run
+// without any external database... if path is empty an in-memory database is used
+func WithSQLiteDB(path string, options ...sqlite.Option) Option {
+	return func(a *application) {
+		if path == "" {
+			a.AppContext.Database = sqlite.NewInMemoryAdapter(*a.AppContext, options...)
+		} else {
+			a.AppContext.Database = sqlite.NewAdapter(*a.AppContext, path, options...)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"github.com/djmarrerajr/common-lib/services/httpclient"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// WithHttpClient will make the client available to the application's request handlers
+// (and other components) as AppContext.Client
+func WithHttpClient(client *httpclient.Client) Option {
+	return func(a *application) {
+		a.AppContext.Client = client
+	}
+}
+
+// WithHttpClientFromEnv operates like WithHttpClient except that the client is configured
+// using the values retrieved from the environment
+func WithHttpClientFromEnv(env utils.Environ, options ...httpclient.Option) Option {
+	return func(a *application) {
+		c, err := httpclient.NewClientFromEnv(env, *a.AppContext, options...)
+		if err != nil {
+			a.AppContext.Logger.Fatalf("unable to create http client:  %v", err)
+		}
+
+		a.AppContext.Client = c
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -22,48 +23,43 @@ type application struct {
 	env            utils.Environ               // map of environment values
 	signalHandlers map[os.Signal]signalHandler // map of signal handlers
 
+	components []*component                 // registered components (in registration order)
+	started    []*component                 // started components (in start order)
+	timeouts   map[string]componentTimeouts // per-component timeout overrides
+
+	drainPeriod     time.Duration // time to continue serving once asked to terminate
+	shutdownTimeout time.Duration // total time permitted for the shutdown to complete
+
 	AppContext *shared.ApplicationContext // application wide resources
 }
 
+// Run will start each of the registered components and then block until the
+// application is asked to terminate (INT, TERM or QUIT) or a component fails,
+// at which point the application is drained and shutdown
 func (a *application) Run() (err error) {
 	sigChan := make(chan os.Signal, 1)
 	defer close(sigChan)
 
 	signal.Notify(sigChan)
+	defer signal.Stop(sigChan)
 
 	ctx, cancel := context.WithCancel(a.AppContext.RootCtx)
 
+	a.registerDefaultComponents()
+	a.applyComponentTimeouts()
+
 	grp, gCtx := errgroup.WithContext(ctx)
-	if a.AppContext.Database != nil {
-		err = a.AppContext.Database.Start(gCtx, grp)
-		if err != nil {
-			a.AppContext.Logger.WithCtx(ctx).Fatalf("unable to start application: %v", err)
-		}
-	}
-	if a.AppContext.Server != nil {
-		err = a.AppContext.Server.Start(gCtx, grp)
-		if err != nil {
-			a.AppContext.Logger.WithCtx(ctx).Fatalf("unable to start application: %v", err)
-		}
+
+	err = a.startComponents(gCtx, grp)
+	if err != nil {
+		a.AppContext.Logger.WithCtx(ctx).Error("unable to start application", err)
+		cancel()
+
+		return err
 	}
 
 	a.AppContext.Logger.WithCtx(ctx).Infof("application startup complete")
-	run := true
-	for run {
-		sig := <-sigChan
-
-		switch {
-		case sig == os.Interrupt:
-			run = false
-			// cancel()
-		case sig == syscall.SIGUSR1:
-			a.toggleDebug()
-		default:
-			if fn, exists := a.signalHandlers[sig]; exists {
-				fn(a.AppContext.Logger)
-			}
-		}
-	}
+	a.await(gCtx, sigChan)
 
 	a.Shutdown(cancel)
 
@@ -72,29 +68,112 @@ func (a *application) Run() (err error) {
 	return grp.Wait()
 }
 
+// Shutdown will stop each of the started components, in the reverse of the order
+// in which they were started, and close the tracer before cancelling the context
+// shared by the running components
+//
+// The entire shutdown must complete within the application's shutdown timeout, a
+// failure to stop one component will not prevent the remainder from being stopped
 func (a *application) Shutdown(cancel context.CancelFunc) {
-	if a.AppContext.Server != nil {
-		err := a.AppContext.Server.Stop()
+	defer cancel()
+
+	ctx, done := context.WithTimeout(a.AppContext.RootCtx, a.shutdownTimeout)
+	defer done()
+
+	logger := a.AppContext.Logger.WithCtx(a.AppContext.RootCtx)
+
+	err := a.stopComponents(ctx)
+	if err != nil {
+		logger.Error("unable to shutdown cleanly", err)
+	}
+
+	if a.AppContext.Closer != nil {
+		err = withDeadline(ctx, 0, func(context.Context) error { return a.AppContext.Closer.Close() })
 		if err != nil {
-			a.AppContext.Logger.WithCtx(a.AppContext.RootCtx).Fatalf("unable to shutdown server: %v", err)
+			logger.Error("unable to close tracer", err)
 		}
 	}
 
-	if a.AppContext.Database != nil {
-		err := a.AppContext.Database.Stop()
-		if err != nil {
-			a.AppContext.Logger.WithCtx(a.AppContext.RootCtx).Fatalf("unable to shutdown database: %v", err)
+	logger.Infof("application shutdown complete")
+}
+
+// await will block until the application is asked to terminate (INT, TERM or QUIT), at
+// which point it is drained, or a component fails... any other signal is handled as it
+// is received
+func (a *application) await(gCtx context.Context, sigChan <-chan os.Signal) {
+	logger := a.AppContext.Logger.WithCtx(a.AppContext.RootCtx)
+
+	for {
+		select {
+		case <-gCtx.Done():
+			logger.Warnf("a component has terminated unexpectedly")
+			return
+		case sig := <-sigChan:
+			if a.handleSignal(sig) {
+				logger.Infof("received %s, shutting down", sig)
+				a.drain(gCtx, sigChan)
+				return
+			}
 		}
 	}
+}
 
-	if a.AppContext.Closer != nil {
-		err := a.AppContext.Closer.Close()
-		if err != nil {
-			a.AppContext.Logger.WithCtx(a.AppContext.RootCtx).Fatalf("unable to close tracer: %v", err)
+// handleSignal will invoke the handler for the signal (if any), returning true should
+// the signal be one that asks the application to terminate
+func (a *application) handleSignal(sig os.Signal) bool {
+	switch sig {
+	case os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT:
+		return true
+	case syscall.SIGUSR1:
+		a.toggleDebug()
+	default:
+		if fn, exists := a.signalHandlers[sig]; exists {
+			fn(a.AppContext.Logger)
+		}
+	}
+
+	return false
+}
+
+// drain will inform each of the started components that are able to do so that they
+// should begin to refuse new work (i.e. report as not ready) while continuing to serve
+// existing work and then wait for the drain period to elapse before returning
+//
+// The drain is cut short should the application be asked to terminate once more, or
+// should a component fail while it is draining
+func (a *application) drain(gCtx context.Context, sigChan <-chan os.Signal) {
+	if a.drainPeriod <= 0 {
+		return
+	}
+
+	logger := a.AppContext.Logger.WithCtx(a.AppContext.RootCtx)
+
+	for _, c := range a.started {
+		if d, OK := c.service.(drainable); OK {
+			logger.Debugf("draining component '%s'", c.name)
+			d.Drain()
 		}
 	}
 
-	cancel()
+	logger.Infof("draining for %s before shutting down", a.drainPeriod)
+
+	timer := time.NewTimer(a.drainPeriod)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return
+		case <-gCtx.Done():
+			logger.Warnf("a component has terminated while draining")
+			return
+		case sig := <-sigChan:
+			if a.handleSignal(sig) {
+				logger.Infof("received %s while draining, shutting down now", sig)
+				return
+			}
+		}
+	}
 }
 
 func (a *application) toggleDebug() {
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/djmarrerajr/common-lib/observability/health"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// stopFunc is a service whose Stop() is provided by the test
+type stopFunc struct {
+	fn func() error
+}
+
+func (s *stopFunc) Start(context.Context, *errgroup.Group) error { return nil }
+func (s *stopFunc) Stop() error                                  { return s.fn() }
+
+// closerFunc records that the tracer has been closed
+type closerFunc func() error
+
+func (c closerFunc) Close() error { return c() }
+
+type ShutdownTestSuite struct {
+	suite.Suite
+
+	app application
+}
+
+func (s *ShutdownTestSuite) SetupTest() {
+	s.app = application{
+		timeouts:        make(map[string]componentTimeouts),
+		signalHandlers:  make(map[os.Signal]signalHandler),
+		drainPeriod:     DefaultDrainPeriod,
+		shutdownTimeout: DefaultShutdownTimeout,
+		AppContext: &shared.ApplicationContext{
+			RootCtx: context.Background(),
+			Logger:  utils.NewLogger("INFO"),
+			Health:  health.NewRegistry(),
+		},
+	}
+}
+
+func (s *ShutdownTestSuite) TestDrain_ReadinessFailsWhileRequestsAreServed() {
+	server, err := api.NewServerFromEnv(utils.NewEnviron(map[string]string{}), *s.app.AppContext)
+	s.Require().NoError(err)
+
+	server.DefineRequestHandler("/ping", func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return "pong", 0
+	}, nil, http.MethodGet)
+
+	s.app.started = []*component{newComponent(ServerComponentName, server)}
+	s.app.drainPeriod = 200 * time.Millisecond
+
+	s.Equal(http.StatusOK, s.get(server, api.ReadinessPath))
+
+	sigChan := make(chan os.Signal, 1)
+	sigChan <- syscall.SIGTERM
+
+	done := make(chan struct{})
+	go func() {
+		s.app.await(context.Background(), sigChan)
+		close(done)
+	}()
+
+	s.Eventually(func() bool {
+		return s.get(server, api.ReadinessPath) == http.StatusServiceUnavailable
+	}, time.Second, 5*time.Millisecond)
+
+	s.Equal(http.StatusOK, s.get(server, "/ping"), "requests are served while draining")
+
+	select {
+	case <-done:
+		s.Fail("the drain period has not elapsed")
+	default:
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		s.Fail("the drain period should have elapsed")
+	}
+}
+
+func (s *ShutdownTestSuite) TestDrain_SecondSignalEndsDrain() {
+	s.app.drainPeriod = time.Hour
+
+	sigChan := make(chan os.Signal, 2)
+	sigChan <- syscall.SIGTERM
+	sigChan <- syscall.SIGINT
+
+	s.awaitWithin(context.Background(), sigChan, time.Second)
+}
+
+func (s *ShutdownTestSuite) TestDrain_ComponentFailureEndsDrain() {
+	s.app.drainPeriod = time.Hour
+
+	handled := make(chan struct{})
+	s.app.signalHandlers[syscall.SIGUSR2] = func(utils.Logger) { close(handled) }
+
+	sigChan := make(chan os.Signal, 2)
+	sigChan <- syscall.SIGQUIT
+	sigChan <- syscall.SIGUSR2
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-handled
+		cancel()
+	}()
+
+	s.awaitWithin(ctx, sigChan, time.Second)
+}
+
+func (s *ShutdownTestSuite) TestShutdown_DeadlineBoundsHungStop() {
+	closed := new(atomic.Bool)
+
+	s.app.AppContext.Closer = closerFunc(func() error { closed.Store(true); return nil })
+	s.app.shutdownTimeout = 20 * time.Millisecond
+	s.app.started = []*component{{
+		name:        "hung",
+		service:     &stopFunc{func() error { select {} }},
+		stopTimeout: time.Hour,
+	}}
+
+	cancelled := false
+	start := time.Now()
+
+	s.app.Shutdown(func() { cancelled = true })
+
+	s.Less(time.Since(start), time.Second)
+	s.True(cancelled)
+	s.Empty(s.app.started)
+
+	// the tracer is closed regardless, although we've no longer the time to wait for it...
+	s.Eventually(closed.Load, time.Second, 5*time.Millisecond)
+}
+
+func (s *ShutdownTestSuite) TestShutdown_FailedStopStillClosesTracer() {
+	var events []string
+
+	s.app.AppContext.Closer = closerFunc(func() error { events = append(events, "close"); return nil })
+	s.app.started = []*component{
+		newComponent("db", &stopFunc{func() error { events = append(events, "stop:db"); return nil }}),
+		newComponent("api", &stopFunc{func() error { return fmt.Errorf("boom") }}),
+	}
+
+	s.app.Shutdown(func() {})
+
+	s.Equal([]string{"stop:db", "close"}, events)
+}
+
+func (s *ShutdownTestSuite) awaitWithin(ctx context.Context, sigChan <-chan os.Signal, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		s.app.await(ctx, sigChan)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		s.Fail("the drain was not cut short")
+	}
+}
+
+func (s *ShutdownTestSuite) get(server *api.Server, path string) int {
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return rec.Code
+}
+
+func TestShutdown(t *testing.T) {
+	suite.Run(t, new(ShutdownTestSuite))
+}
@@ -13,4 +13,17 @@ const (
 	ErrTypeValidation     ErrorType = "Validation"
 	ErrTypeInvalidNumber  ErrorType = "InvalidNumber"
 	ErrTypeInvalidBoolean ErrorType = "InvalidBoolean"
+	ErrTypeTimeout        ErrorType = "Timeout"
+	ErrTypeNotFound       ErrorType = "NotFound"
+	ErrTypeDatabase       ErrorType = "Database"
+	ErrTypeInternal       ErrorType = "Internal"
+	ErrTypeAuthentication ErrorType = "Authentication"
+	ErrTypeAuthorization  ErrorType = "Authorization"
+	ErrTypeRateLimited    ErrorType = "RateLimited"
+	ErrTypeUnavailable    ErrorType = "Unavailable"
+	ErrTypeConflict       ErrorType = "Conflict"
+
+	ErrTypeNotAcceptable        ErrorType = "NotAcceptable"
+	ErrTypeUnsupportedMediaType ErrorType = "UnsupportedMediaType"
+	ErrTypeRequestTooLarge      ErrorType = "RequestTooLarge"
 )
@@ -0,0 +1,23 @@
+package health
+
+import "time"
+
+// Status represents the health of an individual check or the application as a whole
+type Status string
+
+// nolint: unused
+const (
+	StatusUp       Status = "up"       // healthy
+	StatusDegraded Status = "degraded" // only non-critical checks are failing
+	StatusDown     Status = "down"     // one or more critical checks are failing
+)
+
+// nolint: unused
+const (
+	DefaultCheckTimeout = 5 * time.Second
+)
+
+// nolint: unused
+const (
+	CheckStatusMetricName = "health_check_status"
+)
@@ -0,0 +1,14 @@
+package health
+
+// NewRegistry will instantiate and return an empty health check Registry
+func NewRegistry(options ...Option) *Registry {
+	registry := &Registry{
+		checks: make(map[string]*registeredCheck),
+	}
+
+	for _, option := range options {
+		option(registry)
+	}
+
+	return registry
+}
@@ -0,0 +1,43 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// LiveHandler returns an http.HandlerFunc that reports the liveness of the application
+func (r *Registry) LiveHandler() http.HandlerFunc {
+	return reportHandler(r.Live)
+}
+
+// ReadyHandler returns an http.HandlerFunc that reports the readiness of the application
+func (r *Registry) ReadyHandler() http.HandlerFunc {
+	return reportHandler(r.Ready)
+}
+
+// Handler returns an http.HandlerFunc that reports the overall health of the application
+// along with a breakdown of each of the registered checks, liveness checks included, and
+// whether each is critical and/or a liveness check
+func (r *Registry) Handler() http.HandlerFunc {
+	return reportHandler(r.Health)
+}
+
+// reportHandler will respond with the JSON encoded Report and an HTTP-200 unless
+// the application is down in which case an HTTP-503 is returned
+func reportHandler(evaluate func(context.Context) Report) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := evaluate(req.Context())
+
+		status := http.StatusOK
+		if report.Status == StatusDown {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+
+		_ = json.NewEncoder(w).Encode(report)
+	}
+}
@@ -0,0 +1,171 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/djmarrerajr/common-lib/observability/health"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+type HealthTestSuite struct {
+	suite.Suite
+
+	registry *health.Registry
+}
+
+func (h *HealthTestSuite) SetupTest() {
+	h.registry = health.NewRegistry()
+}
+
+func (h *HealthTestSuite) TestReady_NoChecks_IsUp() {
+	report := h.registry.Ready(context.Background())
+
+	h.Equal(health.StatusUp, report.Status)
+}
+
+func (h *HealthTestSuite) TestReady_FailingCriticalCheck_IsDown() {
+	h.registry.Register(health.Check{Name: "ok", Checker: passing, Critical: true})
+	h.registry.Register(health.Check{Name: "db", Checker: failing, Critical: true})
+
+	report := h.registry.Ready(context.Background())
+
+	h.Equal(health.StatusDown, report.Status)
+	h.Equal(health.StatusUp, report.Checks["ok"].Status)
+	h.Equal(health.StatusDown, report.Checks["db"].Status)
+	h.Equal("unreachable", report.Checks["db"].Error)
+}
+
+func (h *HealthTestSuite) TestReady_FailingNonCriticalCheck_IsDegraded() {
+	h.registry.Register(health.Check{Name: "cache", Checker: failing})
+
+	report := h.registry.Ready(context.Background())
+
+	h.Equal(health.StatusDegraded, report.Status)
+}
+
+func (h *HealthTestSuite) TestLive_OnlyEvaluatesLivenessChecks() {
+	h.registry.Register(health.Check{Name: "db", Checker: failing, Critical: true})
+	h.registry.Register(health.Check{Name: "deadlock", Checker: passing, Critical: true, Liveness: true})
+
+	report := h.registry.Live(context.Background())
+
+	h.Equal(health.StatusUp, report.Status)
+	h.Len(report.Checks, 1)
+	h.Contains(report.Checks, "deadlock")
+}
+
+func (h *HealthTestSuite) TestCheck_ExceedingTimeout_IsDown() {
+	h.registry.Register(health.Check{
+		Name:     "slow",
+		Critical: true,
+		Timeout:  5 * time.Millisecond,
+		Checker: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+	})
+
+	report := h.registry.Ready(context.Background())
+
+	h.Equal(health.StatusDown, report.Status)
+	h.Contains(report.Checks["slow"].Error, "did not complete")
+}
+
+func (h *HealthTestSuite) TestCheck_ResultIsCachedForTTL() {
+	var calls atomic.Int32
+
+	h.registry.Register(health.Check{
+		Name:     "cached",
+		CacheTTL: time.Minute,
+		Checker: func(context.Context) error {
+			calls.Add(1)
+			return nil
+		},
+	})
+
+	h.registry.Ready(context.Background())
+	h.registry.Ready(context.Background())
+
+	h.Equal(int32(1), calls.Load())
+}
+
+func (h *HealthTestSuite) TestHandler_ReturnsServiceUnavailableWhenDown() {
+	h.registry.Register(health.Check{Name: "db", Checker: failing, Critical: true})
+
+	rec := httptest.NewRecorder()
+	h.registry.ReadyHandler()(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	var report health.Report
+
+	h.Equal(http.StatusServiceUnavailable, rec.Code)
+	h.NoError(json.Unmarshal(rec.Body.Bytes(), &report))
+	h.Equal(health.StatusDown, report.Status)
+}
+
+func (h *HealthTestSuite) TestHandler_ReportsEveryCheck() {
+	h.registry.Register(health.Check{Name: "db", Checker: passing, Critical: true})
+	h.registry.Register(health.Check{Name: "cache", Checker: failing})
+	h.registry.Register(health.Check{Name: "deadlock", Checker: passing, Critical: true, Liveness: true})
+
+	rec := httptest.NewRecorder()
+	h.registry.Handler()(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	var report health.Report
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.NoError(json.Unmarshal(rec.Body.Bytes(), &report))
+	h.Equal(health.StatusDegraded, report.Status)
+	h.Len(report.Checks, 3)
+
+	h.True(report.Checks["db"].Critical)
+	h.False(report.Checks["db"].Liveness)
+	h.False(report.Checks["cache"].Critical)
+	h.Equal("unreachable", report.Checks["cache"].Error)
+	h.True(report.Checks["deadlock"].Critical)
+	h.True(report.Checks["deadlock"].Liveness)
+
+}
+
+func (h *HealthTestSuite) TestReady_FailingLivenessCheck_IsDown() {
+	h.registry.Register(health.Check{Name: "db", Checker: passing, Critical: true})
+	h.registry.Register(health.Check{Name: "deadlock", Checker: failing, Critical: true, Liveness: true})
+
+	report := h.registry.Ready(context.Background())
+
+	h.Equal(health.StatusDown, report.Status)
+	h.Len(report.Checks, 2)
+}
+
+func (h *HealthTestSuite) TestCollector_GaugeIsDimensionedByFilterLabels() {
+	collector, err := metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "my-app")
+	h.Require().NoError(err)
+
+	registry := health.NewRegistry(health.WithCollector(collector, []string{"environ", "hostname"}, []string{"dev", "box"}))
+	registry.Register(health.Check{Name: "db", Checker: passing, Critical: true})
+	registry.Register(health.Check{Name: "cache", Checker: failing})
+
+	registry.Ready(context.Background())
+
+	rec := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	h.Contains(rec.Body.String(), `my_app_health_check_status{check="db",environ="dev",hostname="box"} 1`)
+	h.Contains(rec.Body.String(), `my_app_health_check_status{check="cache",environ="dev",hostname="box"} 0`)
+}
+
+func passing(context.Context) error { return nil }
+func failing(context.Context) error { return errors.New("unreachable") }
+
+func TestHealth(t *testing.T) {
+	suite.Run(t, new(HealthTestSuite))
+}
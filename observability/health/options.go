@@ -0,0 +1,22 @@
+package health
+
+import (
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+)
+
+type Option func(*Registry)
+
+// WithCollector will cause the result of each check to be exported as a
+// gauge (1 = up, 0 = down) dimensioned by the given filter labels, whose
+// values are recorded alongside every result, and the name of the check
+func WithCollector(collector metrics.Collector, filterLabels []string, filterValues []string) Option {
+	return func(r *Registry) {
+		if collector == nil {
+			return
+		}
+
+		gauge := collector.NewDimensionedGauge(CheckStatusMetricName, append(append([]string{}, filterLabels...), "check")...)
+		r.gauge = &gauge
+		r.filterValues = filterValues
+	}
+}
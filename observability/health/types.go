@@ -0,0 +1,205 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+)
+
+// CheckerFunc is invoked to determine the health of a component, it should return
+// a non-nil error when the component is unhealthy
+type CheckerFunc func(context.Context) error
+
+// Check describes a named health check that can be registered with the Registry
+type Check struct {
+	Name     string        // unique name of the check (i.e. database)
+	Checker  CheckerFunc   // function used to determine health
+	Timeout  time.Duration // maximum time the check is permitted to run
+	CacheTTL time.Duration // length of time a result is reused before re-checking
+	Critical bool          // a failing critical check renders the application unhealthy
+	Liveness bool          // also include this check when determining liveness
+}
+
+// Result is the outcome of a single health check
+type Result struct {
+	Status    Status    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Liveness  bool      `json:"liveness"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Report is the aggregated outcome of a set of health checks
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Registry maintains the set of health checks registered by the application's
+// components and evaluates them on demand
+type Registry struct {
+	mu     sync.RWMutex
+	checks map[string]*registeredCheck
+
+	gauge        *metrics.DimensionedGauge
+	filterValues []string
+}
+
+// registeredCheck pairs a Check with its most recent result
+type registeredCheck struct {
+	Check
+
+	mu     sync.Mutex
+	result Result
+}
+
+// Register will add the check to the Registry, replacing any existing check
+// with the same name
+func (r *Registry) Register(check Check) {
+	if check.Timeout <= 0 {
+		check.Timeout = DefaultCheckTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks[check.Name] = &registeredCheck{Check: check}
+}
+
+// Deregister will remove the named check from the Registry
+func (r *Registry) Deregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.checks, name)
+}
+
+// Live evaluates those checks that determine whether or not the application is
+// alive (i.e. should be restarted if failing)
+func (r *Registry) Live(ctx context.Context) Report {
+	return r.evaluate(ctx, func(c *registeredCheck) bool { return c.Liveness })
+}
+
+// Ready evaluates all checks, liveness checks included, to determine whether or not
+// the application is ready to receive traffic; an application that is not alive is
+// not ready either
+func (r *Registry) Ready(ctx context.Context) Report {
+	return r.evaluate(ctx, func(c *registeredCheck) bool { return true })
+}
+
+// Health evaluates all checks, liveness checks included, to determine the overall health of
+// the application
+func (r *Registry) Health(ctx context.Context) Report {
+	return r.evaluate(ctx, func(c *registeredCheck) bool { return true })
+}
+
+// evaluate will concurrently run each of the selected checks and aggregate
+// their results in to a single Report
+func (r *Registry) evaluate(ctx context.Context, include func(*registeredCheck) bool) Report {
+	r.mu.RLock()
+	selected := make([]*registeredCheck, 0, len(r.checks))
+	for _, check := range r.checks {
+		if include(check) {
+			selected = append(selected, check)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+
+	results := make([]Result, len(selected))
+
+	var wg sync.WaitGroup
+	for idx, check := range selected {
+		wg.Add(1)
+		go func(idx int, check *registeredCheck) {
+			defer wg.Done()
+			results[idx] = check.run(ctx)
+		}(idx, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]Result, len(selected)),
+	}
+
+	for idx, check := range selected {
+		result := results[idx]
+		report.Checks[check.Name] = result
+
+		r.recordResult(check.Name, result)
+
+		switch {
+		case result.Status == StatusUp:
+		case check.Critical:
+			report.Status = StatusDown
+		case report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+// recordResult will export the result of a check as a gauge (1 = up, 0 = down)
+func (r *Registry) recordResult(name string, result Result) {
+	if r.gauge == nil {
+		return
+	}
+
+	value := float64(0)
+	if result.Status == StatusUp {
+		value = 1
+	}
+
+	r.gauge.WithLabelValues(append(append([]string{}, r.filterValues...), name)...).Set(value)
+}
+
+// run will execute the check, within its timeout, unless a cached result that
+// has not yet expired is available
+func (c *registeredCheck) run(ctx context.Context) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.CacheTTL > 0 && !c.result.CheckedAt.IsZero() && time.Since(c.result.CheckedAt) < c.CacheTTL {
+		return c.result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	started := time.Now()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Checker(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errs.Wrapf(ctx.Err(), errs.ErrTypeTimeout, "health check did not complete within %s", c.Timeout)
+	}
+
+	c.result = Result{
+		Status:    StatusUp,
+		Critical:  c.Critical,
+		Liveness:  c.Liveness,
+		Duration:  time.Since(started).String(),
+		CheckedAt: started,
+	}
+
+	if err != nil {
+		c.result.Status = StatusDown
+		c.result.Error = err.Error()
+	}
+
+	return c.result
+}
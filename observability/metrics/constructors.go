@@ -3,13 +3,31 @@ package metrics
 import (
 	"strings"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
 	"github.com/djmarrerajr/common-lib/utils"
 )
 
+// NewCollectorFromEnv will instantiate and return a PrometheusCollector that, unless
+// provided with a registry via WithRegistry, has its own registry that includes the
+// standard Go runtime and process metrics
 func NewCollectorFromEnv(env utils.Environ, appName string, options ...Option) (*PrometheusCollector, error) {
 	Collector := &PrometheusCollector{
-		appName:  strings.ReplaceAll(appName, "-", "_"),
-		counters: make(map[string]any),
+		namespace: strings.ReplaceAll(appName, "-", "_"),
+		metrics:   make(map[string]registeredMetric),
+	}
+
+	for _, option := range options {
+		option(Collector)
+	}
+
+	if Collector.registry == nil {
+		Collector.registry = prometheus.NewRegistry()
+		Collector.registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
 	}
 
 	return Collector, nil
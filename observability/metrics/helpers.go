@@ -0,0 +1,11 @@
+package metrics
+
+// DefaultSummaryObjectives returns the quantiles (and their allowable error) that
+// are calculated by a summary when no objectives have been specified
+func DefaultSummaryObjectives() map[float64]float64 {
+	return map[float64]float64{
+		0.5:  0.05,
+		0.9:  0.01,
+		0.99: 0.001,
+	}
+}
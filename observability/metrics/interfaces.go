@@ -1,8 +1,16 @@
 package metrics
 
+import "net/http"
+
 type Collector interface {
 	NewCounter(string) Counter
 	NewDimensionedCounter(string, ...string) DimensionedCounter
 	NewGauge(string) Gauge
 	NewDimensionedGauge(string, ...string) DimensionedGauge
+	NewHistogram(string, ...float64) Histogram
+	NewDimensionedHistogram(string, []float64, ...string) DimensionedHistogram
+	NewSummary(string, map[float64]float64) Summary
+	NewDimensionedSummary(string, map[float64]float64, ...string) DimensionedSummary
+
+	Handler() http.Handler
 }
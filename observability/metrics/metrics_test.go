@@ -0,0 +1,128 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+type MetricsTestSuite struct {
+	suite.Suite
+
+	env utils.Environ
+}
+
+func (m *MetricsTestSuite) SetupTest() {
+	m.env = utils.NewEnviron(map[string]string{})
+}
+
+func (m *MetricsTestSuite) TestNewCollector_MultipleCollectorsDoNotCollide() {
+	first, err := metrics.NewCollectorFromEnv(m.env, "my-app")
+	m.NoError(err)
+
+	second, err := metrics.NewCollectorFromEnv(m.env, "my-app")
+	m.NoError(err)
+
+	m.NotPanics(func() {
+		first.NewCounter("requests_total").Inc()
+		second.NewCounter("requests_total").Inc()
+	})
+}
+
+func (m *MetricsTestSuite) TestNewCounter_SameNameReturnsSameMetric() {
+	collector, _ := metrics.NewCollectorFromEnv(m.env, "my-app")
+
+	collector.NewCounter("requests_total").Inc()
+	collector.NewCounter("requests_total").Inc()
+
+	m.Contains(m.scrape(collector), "my_app_requests_total 2")
+}
+
+func (m *MetricsTestSuite) TestNewGauge_NameOfExistingCounterPanics() {
+	collector, _ := metrics.NewCollectorFromEnv(m.env, "my-app")
+	collector.NewCounter("requests")
+
+	m.PanicsWithError("metric 'requests' has already been registered as a metrics.Counter, not a metrics.Gauge", func() {
+		collector.NewGauge("requests")
+	})
+}
+
+func (m *MetricsTestSuite) TestNewDimensionedCounter_DifferentLabelsPanics() {
+	collector, _ := metrics.NewCollectorFromEnv(m.env, "my-app")
+	collector.NewDimensionedCounter("requests_total", "path", "method")
+
+	m.NotPanics(func() {
+		collector.NewDimensionedCounter("requests_total", "path", "method").WithLabelValues("orders", "GET").Inc()
+	})
+
+	m.PanicsWithError("metric 'requests_total' has already been registered with the labels [path method], not [path]", func() {
+		collector.NewDimensionedCounter("requests_total", "path")
+	})
+}
+
+func (m *MetricsTestSuite) TestNewCounter_ConcurrentRegistrationIsSafe() {
+	collector, _ := metrics.NewCollectorFromEnv(m.env, "my-app")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			collector.NewDimensionedCounter("hits_total", "path").WithLabelValues("root").Inc()
+		}()
+	}
+	wg.Wait()
+
+	m.Contains(m.scrape(collector), `my_app_hits_total{path="root"} 50`)
+}
+
+func (m *MetricsTestSuite) TestOptions_NamespaceConstLabelsAndRegistry() {
+	registry := prometheus.NewRegistry()
+
+	collector, _ := metrics.NewCollectorFromEnv(m.env, "my-app",
+		metrics.WithRegistry(registry),
+		metrics.WithNamespace("custom"),
+		metrics.WithConstLabels(map[string]string{"region": "east"}),
+	)
+
+	collector.NewGauge("queue_depth").Set(3)
+
+	families, err := registry.Gather()
+	m.NoError(err)
+	m.Len(families, 1)
+	m.Equal("custom_queue_depth", families[0].GetName())
+	m.Contains(m.scrape(collector), `custom_queue_depth{region="east"} 3`)
+}
+
+func (m *MetricsTestSuite) TestNewHistogramAndSummary_AreExposed() {
+	collector, _ := metrics.NewCollectorFromEnv(m.env, "my-app")
+
+	collector.NewDimensionedHistogram("latency_seconds", []float64{.1, 1}, "path").WithLabelValues("root").Observe(.5)
+	collector.NewSummary("size_bytes", nil).Observe(42)
+
+	body := m.scrape(collector)
+	m.Contains(body, `my_app_latency_seconds_bucket{path="root",le="1"} 1`)
+	m.Contains(body, `my_app_size_bytes{quantile="0.5"} 42`)
+}
+
+func (m *MetricsTestSuite) scrape(collector *metrics.PrometheusCollector) string {
+	rec := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, err := io.ReadAll(rec.Body)
+	m.Require().NoError(err)
+
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
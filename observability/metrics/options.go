@@ -1,3 +1,35 @@
 package metrics
 
-type Option func(Collector)
+import "github.com/prometheus/client_golang/prometheus"
+
+type Option func(*PrometheusCollector)
+
+// WithRegistry will cause the collector to register its metrics with the provided
+// registry rather than creating a registry of its own
+func WithRegistry(registry *prometheus.Registry) Option {
+	return func(p *PrometheusCollector) {
+		p.registry = registry
+	}
+}
+
+// WithNamespace will override the namespace (the application name by default) that
+// prefixes the name of each metric
+func WithNamespace(namespace string) Option {
+	return func(p *PrometheusCollector) {
+		p.namespace = namespace
+	}
+}
+
+// WithConstLabels will add the provided labels, and their values, to every metric
+// created by the collector
+func WithConstLabels(labels map[string]string) Option {
+	return func(p *PrometheusCollector) {
+		if p.constLabels == nil {
+			p.constLabels = make(prometheus.Labels, len(labels))
+		}
+
+		for name, value := range labels {
+			p.constLabels[name] = value
+		}
+	}
+}
@@ -1,69 +1,165 @@
 package metrics
 
 import (
+	"net/http"
+	"slices"
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/djmarrerajr/common-lib/errs"
 )
 
 var _ Collector = new(PrometheusCollector)
 
+// PrometheusCollector is a Collector that registers each of its metrics with its
+// own (private) Prometheus registry rather than the global default registry
+//
+// Metrics are identified by name... requesting an existing metric returns the one
+// previously created, however, requesting a metric of a different kind (i.e. a
+// gauge having the same name as an existing counter), or having different labels,
+// is considered to be a fatal configuration error
 type PrometheusCollector struct {
-	appName  string
-	counters map[string]any
+	mu sync.Mutex
+
+	registry    *prometheus.Registry
+	namespace   string
+	constLabels prometheus.Labels
+	metrics     map[string]registeredMetric
 }
 
-func (p *PrometheusCollector) NewCounter(name string) Counter {
-	if _, exists := p.counters[name]; !exists {
-		p.counters[name] = Counter{
-			promauto.NewCounter(prometheus.CounterOpts{
-				Namespace: p.appName,
-				Name:      name,
-			}),
-		}
-	}
+// registeredMetric is a metric known to the collector along with the names of its labels
+type registeredMetric struct {
+	metric any
+	labels []string
+}
 
-	return p.counters[name].(Counter)
+func (p *PrometheusCollector) NewCounter(name string) Counter {
+	return getOrRegister(p, name, nil, func() (Counter, prometheus.Collector) {
+		counter := prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   p.namespace,
+			Name:        name,
+			ConstLabels: p.constLabels,
+		})
+
+		return Counter{counter}, counter
+	})
 }
 
 func (p *PrometheusCollector) NewDimensionedCounter(name string, labels ...string) DimensionedCounter {
-	if _, exists := p.counters[name]; !exists {
-		p.counters[name] = DimensionedCounter{
-			promauto.NewCounterVec(prometheus.CounterOpts{
-				Namespace: p.appName,
-				Name:      name,
-			}, labels),
-		}
-	}
+	return getOrRegister(p, name, labels, func() (DimensionedCounter, prometheus.Collector) {
+		counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   p.namespace,
+			Name:        name,
+			ConstLabels: p.constLabels,
+		}, labels)
+
+		return DimensionedCounter{counter}, counter
+	})
+}
+
+func (p *PrometheusCollector) NewGauge(name string) Gauge {
+	return getOrRegister(p, name, nil, func() (Gauge, prometheus.Collector) {
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   p.namespace,
+			Name:        name,
+			ConstLabels: p.constLabels,
+		})
+
+		return Gauge{gauge}, gauge
+	})
+}
 
-	return p.counters[name].(DimensionedCounter)
+func (p *PrometheusCollector) NewDimensionedGauge(name string, labels ...string) DimensionedGauge {
+	return getOrRegister(p, name, labels, func() (DimensionedGauge, prometheus.Collector) {
+		gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   p.namespace,
+			Name:        name,
+			ConstLabels: p.constLabels,
+		}, labels)
+
+		return DimensionedGauge{gauge}, gauge
+	})
+}
 
+// NewHistogram will create a histogram using the provided buckets (upper bounds)
+// or, if none are provided, the default Prometheus buckets
+func (p *PrometheusCollector) NewHistogram(name string, buckets ...float64) Histogram {
+	return getOrRegister(p, name, nil, func() (Histogram, prometheus.Collector) {
+		histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   p.namespace,
+			Name:        name,
+			ConstLabels: p.constLabels,
+			Buckets:     buckets,
+		})
+
+		return Histogram{histogram}, histogram
+	})
 }
 
-func (p *PrometheusCollector) NewGauge(name string) Gauge {
-	if _, exists := p.counters[name]; !exists {
-		p.counters[name] = Gauge{
-			promauto.NewGauge(prometheus.GaugeOpts{
-				Namespace: p.appName,
-				Name:      name,
-			}),
-		}
+// NewDimensionedHistogram will create a histogram, having the specified labels, using the
+// provided buckets (upper bounds) or, if none are provided, the default Prometheus buckets
+func (p *PrometheusCollector) NewDimensionedHistogram(name string, buckets []float64, labels ...string) DimensionedHistogram {
+	return getOrRegister(p, name, labels, func() (DimensionedHistogram, prometheus.Collector) {
+		histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   p.namespace,
+			Name:        name,
+			ConstLabels: p.constLabels,
+			Buckets:     buckets,
+		}, labels)
+
+		return DimensionedHistogram{histogram}, histogram
+	})
+}
+
+// NewSummary will create a summary using the provided objectives (quantile->error)
+// or, if none are provided, the DefaultSummaryObjectives
+func (p *PrometheusCollector) NewSummary(name string, objectives map[float64]float64) Summary {
+	if objectives == nil {
+		objectives = DefaultSummaryObjectives()
 	}
 
-	return p.counters[name].(Gauge)
+	return getOrRegister(p, name, nil, func() (Summary, prometheus.Collector) {
+		summary := prometheus.NewSummary(prometheus.SummaryOpts{
+			Namespace:   p.namespace,
+			Name:        name,
+			ConstLabels: p.constLabels,
+			Objectives:  objectives,
+		})
+
+		return Summary{summary}, summary
+	})
 }
 
-func (p *PrometheusCollector) NewDimensionedGauge(name string, labels ...string) DimensionedGauge {
-	if _, exists := p.counters[name]; !exists {
-		p.counters[name] = DimensionedGauge{
-			promauto.NewGaugeVec(prometheus.GaugeOpts{
-				Namespace: p.appName,
-				Name:      name,
-			}, labels),
-		}
+// NewDimensionedSummary will create a summary, having the specified labels, using the
+// provided objectives (quantile->error) or, if none are provided, the DefaultSummaryObjectives
+func (p *PrometheusCollector) NewDimensionedSummary(name string, objectives map[float64]float64, labels ...string) DimensionedSummary {
+	if objectives == nil {
+		objectives = DefaultSummaryObjectives()
 	}
 
-	return p.counters[name].(DimensionedGauge)
+	return getOrRegister(p, name, labels, func() (DimensionedSummary, prometheus.Collector) {
+		summary := prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Namespace:   p.namespace,
+			Name:        name,
+			ConstLabels: p.constLabels,
+			Objectives:  objectives,
+		}, labels)
 
+		return DimensionedSummary{summary}, summary
+	})
+}
+
+// Handler returns an http.Handler that exposes the metrics held within the
+// collector's registry in the Prometheus exposition format
+func (p *PrometheusCollector) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{Registry: p.registry})
+}
+
+// Registry returns the Prometheus registry to which the collector's metrics are registered
+func (p *PrometheusCollector) Registry() *prometheus.Registry {
+	return p.registry
 }
 
 type Counter struct {
@@ -81,3 +177,53 @@ type Gauge struct {
 type DimensionedGauge struct {
 	*prometheus.GaugeVec
 }
+
+type Histogram struct {
+	prometheus.Histogram
+}
+
+type DimensionedHistogram struct {
+	*prometheus.HistogramVec
+}
+
+type Summary struct {
+	prometheus.Summary
+}
+
+type DimensionedSummary struct {
+	*prometheus.SummaryVec
+}
+
+// getOrRegister will return the existing metric with the specified name or, if one does
+// not already exist, it will create and register a new one... it is safe for concurrent use
+//
+// It will panic (much like prometheus.MustRegister) should the existing metric be of a
+// different kind, or have different labels, or should the metric be rejected by the registry
+func getOrRegister[T any](p *PrometheusCollector, name string, labels []string, create func() (T, prometheus.Collector)) T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if existing, exists := p.metrics[name]; exists {
+		metric, OK := existing.metric.(T)
+		if !OK {
+			panic(errs.Errorf(errs.ErrTypeConfiguration, "metric '%s' has already been registered as a %T, not a %T", name, existing.metric, metric))
+		}
+
+		if !slices.Equal(existing.labels, labels) {
+			panic(errs.Errorf(errs.ErrTypeConfiguration, "metric '%s' has already been registered with the labels %v, not %v", name, existing.labels, labels))
+		}
+
+		return metric
+	}
+
+	metric, collector := create()
+
+	err := p.registry.Register(collector)
+	if err != nil {
+		panic(errs.Wrapf(err, errs.ErrTypeConfiguration, "unable to register metric '%s'", name))
+	}
+
+	p.metrics[name] = registeredMetric{metric, labels}
+
+	return metric
+}
@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -162,6 +164,28 @@ func (s *ApiTestSuite) TestConstructor_NewServerFromEnv_DefaultHttps() {
 	s.T().Cleanup(cleanup)
 }
 
+func (s *ApiTestSuite) TestDrain_ReadinessReportsUnavailable() {
+	var err error
+
+	s.server, err = api.NewServerFromEnv(utils.NewEnviron(map[string]string{}), s.appctx)
+	s.NoError(err)
+
+	rec := httptest.NewRecorder()
+	s.server.Api.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, api.ReadinessPath, nil))
+	s.Equal(http.StatusOK, rec.Code)
+
+	s.server.Drain()
+
+	rec = httptest.NewRecorder()
+	s.server.Api.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, api.ReadinessPath, nil))
+	s.Equal(http.StatusServiceUnavailable, rec.Code)
+
+	// liveness is unaffected by draining...
+	rec = httptest.NewRecorder()
+	s.server.Api.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, api.LivenessPath, nil))
+	s.Equal(http.StatusOK, rec.Code)
+}
+
 func (s *ApiTestSuite) setupEnviron(envs map[string]string) (cleanup func()) {
 	originalEnvs := map[string]string{}
 
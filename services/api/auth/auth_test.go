@@ -0,0 +1,323 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/api/auth"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+type AuthTestSuite struct {
+	suite.Suite
+
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+	secret []byte
+
+	jwksPath string
+	now      time.Time
+}
+
+func (a *AuthTestSuite) SetupTest() {
+	var err error
+
+	a.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	a.Require().NoError(err)
+
+	a.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.Require().NoError(err)
+
+	a.secret = []byte("super-secret-shared-key")
+	a.now = time.Unix(1700000000, 0)
+
+	a.jwksPath = filepath.Join(a.T().TempDir(), "jwks.json")
+	a.writeKeySet(a.rsaJWK("rsa-1"), a.ecJWK("ec-1"), a.octJWK("hmac-1"))
+}
+
+func (a *AuthTestSuite) TestVerify_SupportedAlgorithms() {
+	verifier := a.newVerifier()
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "RS256", token: a.sign(auth.AlgRS256, "rsa-1", a.claims())},
+		{name: "ES256", token: a.sign(auth.AlgES256, "ec-1", a.claims())},
+		{name: "HS256", token: a.sign(auth.AlgHS256, "hmac-1", a.claims())},
+		{name: "no key id", token: a.sign(auth.AlgRS256, "", a.claims())},
+	}
+
+	for _, test := range tests {
+		a.Run(test.name, func() {
+			claims, err := verifier.Verify(context.Background(), test.token)
+
+			a.Require().NoError(err)
+			a.Equal("bruno", claims.Subject())
+			a.Equal([]string{"orders"}, claims.Audience())
+		})
+	}
+}
+
+func (a *AuthTestSuite) TestVerify_RejectsInvalidTokens() {
+	verifier := a.newVerifier()
+
+	tamper := func(token string) string {
+		parts := strings.Split(token, ".")
+		parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory","exp":9999999999}`))
+		return strings.Join(parts, ".")
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "malformed", token: "not-a-token"},
+		{name: "unsigned", token: a.sign("none", "", a.claims())},
+		{name: "tampered", token: tamper(a.sign(auth.AlgRS256, "rsa-1", a.claims()))},
+		{name: "algorithm confusion", token: a.sign(auth.AlgHS256, "rsa-1", a.claims())},
+		{name: "unknown key", token: a.sign(auth.AlgRS256, "rsa-2", a.claims())},
+		{name: "expired", token: a.sign(auth.AlgRS256, "rsa-1", a.claims("exp", a.now.Add(-2*time.Minute).Unix()))},
+		{name: "no expiry", token: a.sign(auth.AlgRS256, "rsa-1", a.claims("exp", nil))},
+		{name: "not yet valid", token: a.sign(auth.AlgRS256, "rsa-1", a.claims("nbf", a.now.Add(2*time.Minute).Unix()))},
+		{name: "wrong issuer", token: a.sign(auth.AlgRS256, "rsa-1", a.claims("iss", "https://evil.example.com"))},
+		{name: "wrong audience", token: a.sign(auth.AlgRS256, "rsa-1", a.claims("aud", []string{"billing"}))},
+	}
+
+	for _, test := range tests {
+		a.Run(test.name, func() {
+			_, err := verifier.Verify(context.Background(), test.token)
+
+			a.Require().Error(err)
+			a.Equal(errs.ErrTypeAuthentication, errs.GetType(err))
+		})
+	}
+}
+
+func (a *AuthTestSuite) TestVerify_ClockSkewIsAllowed() {
+	token := a.sign(auth.AlgRS256, "rsa-1", a.claims("exp", a.now.Add(-30*time.Second).Unix()))
+
+	_, err := a.newVerifier().Verify(context.Background(), token)
+	a.NoError(err, "within the default skew of one minute")
+
+	_, err = a.newVerifier(auth.WithClockSkew(0)).Verify(context.Background(), token)
+	a.Error(err)
+}
+
+func (a *AuthTestSuite) TestKeySet_UnknownKeyCausesReload() {
+	keys := auth.NewKeySet(a.jwksPath, auth.WithRefreshInterval(time.Hour, time.Nanosecond))
+	verifier := auth.NewVerifier(keys, auth.WithClock(func() time.Time { return a.now }))
+
+	_, err := verifier.Verify(context.Background(), a.sign(auth.AlgRS256, "rsa-1", a.claims()))
+	a.Require().NoError(err)
+
+	// rotate the keys...
+	a.rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	a.writeKeySet(a.rsaJWK("rsa-2"))
+
+	_, err = verifier.Verify(context.Background(), a.sign(auth.AlgRS256, "rsa-2", a.claims()))
+	a.NoError(err)
+}
+
+func (a *AuthTestSuite) TestKeySet_FetchedFromURLAndCached() {
+	buff, err := os.ReadFile(a.jwksPath)
+	a.Require().NoError(err)
+
+	var fetches atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write(buff)
+	}))
+	defer server.Close()
+
+	verifier := auth.NewVerifier(auth.NewKeySet(server.URL), auth.WithClock(func() time.Time { return a.now }))
+
+	for i := 0; i < 3; i++ {
+		_, err = verifier.Verify(context.Background(), a.sign(auth.AlgES256, "ec-1", a.claims()))
+		a.Require().NoError(err)
+	}
+
+	a.Equal(int32(1), fetches.Load())
+}
+
+func (a *AuthTestSuite) TestKeySet_FailedFetchesAreThrottled() {
+	var fetches atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	keys := auth.NewKeySet(server.URL, auth.WithRefreshInterval(time.Hour, time.Hour))
+	verifier := auth.NewVerifier(keys, auth.WithClock(func() time.Time { return a.now }))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := verifier.Verify(context.Background(), a.sign(auth.AlgES256, "ec-1", a.claims()))
+			a.Error(err)
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 3; i++ {
+		_, err := keys.Keys(context.Background(), "unknown")
+		a.Error(err, "the failure is reported until the keys can be loaded")
+	}
+
+	a.Equal(int32(1), fetches.Load(), "the key set is not fetched again within the minimum refresh interval")
+
+	a.Error(keys.Refresh(context.Background()))
+	a.Equal(int32(2), fetches.Load(), "a refresh is never throttled")
+}
+
+func (a *AuthTestSuite) TestNewVerifierFromEnv() {
+	_, err := auth.NewVerifierFromEnv(utils.NewEnviron(map[string]string{}))
+	a.Error(err, "a key set is required")
+
+	env := utils.NewEnviron(map[string]string{
+		auth.JWKSEnvKey:     a.jwksPath,
+		auth.IssuerEnvKey:   "https://issuer.example.com",
+		auth.AudienceEnvKey: "billing,orders",
+	})
+
+	verifier, err := auth.NewVerifierFromEnv(env, auth.WithClock(func() time.Time { return a.now }))
+	a.Require().NoError(err)
+
+	_, err = verifier.Verify(context.Background(), a.sign(auth.AlgHS256, "hmac-1", a.claims()))
+	a.NoError(err)
+}
+
+func (a *AuthTestSuite) TestClaimsFromContext() {
+	claims := auth.Claims{"sub": "bruno"}
+
+	ctx := auth.ContextWithClaims(context.Background(), claims)
+
+	found, OK := auth.ClaimsFromContext(ctx)
+	a.True(OK)
+	a.Equal(claims, found)
+
+	subject, _ := utils.GetFieldValueFromContext[string](ctx, shared.SubjectContextKey)
+	a.Equal("bruno", subject)
+}
+
+func (a *AuthTestSuite) newVerifier(options ...auth.Option) *auth.Verifier {
+	options = append([]auth.Option{
+		auth.WithIssuer("https://issuer.example.com"),
+		auth.WithAudience("orders"),
+		auth.WithClock(func() time.Time { return a.now }),
+	}, options...)
+
+	return auth.NewVerifier(auth.NewKeySet(a.jwksPath), options...)
+}
+
+// claims returns a valid set of claims, with any overrides applied (a nil value removes the claim)
+func (a *AuthTestSuite) claims(overrides ...any) map[string]any {
+	claims := map[string]any{
+		"sub": "bruno",
+		"iss": "https://issuer.example.com",
+		"aud": "orders",
+		"iat": a.now.Unix(),
+		"exp": a.now.Add(time.Hour).Unix(),
+	}
+
+	for i := 0; i+1 < len(overrides); i += 2 {
+		if overrides[i+1] == nil {
+			delete(claims, overrides[i].(string))
+		} else {
+			claims[overrides[i].(string)] = overrides[i+1]
+		}
+	}
+
+	return claims
+}
+
+func (a *AuthTestSuite) sign(alg, kid string, claims map[string]any) string {
+	hdr, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+
+	input := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var sig []byte
+
+	switch alg {
+	case auth.AlgRS256:
+		sig, _ = rsa.SignPKCS1v15(rand.Reader, a.rsaKey, crypto.SHA256, digest[:])
+	case auth.AlgES256:
+		r, s, _ := ecdsa.Sign(rand.Reader, a.ecKey, digest[:])
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case auth.AlgHS256:
+		// sign with the RSA modulus when asked to impersonate the RSA key...
+		secret := a.secret
+		if strings.HasPrefix(kid, "rsa") {
+			secret = a.rsaKey.N.Bytes()
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func (a *AuthTestSuite) rsaJWK(kid string) map[string]string {
+	return map[string]string{
+		"kty": "RSA", "kid": kid, "alg": auth.AlgRS256, "use": "sig",
+		"n": b64(a.rsaKey.N), "e": b64(big.NewInt(int64(a.rsaKey.E))),
+	}
+}
+
+func (a *AuthTestSuite) ecJWK(kid string) map[string]string {
+	return map[string]string{
+		"kty": "EC", "kid": kid, "crv": "P-256",
+		"x": base64.RawURLEncoding.EncodeToString(a.ecKey.X.FillBytes(make([]byte, 32))),
+		"y": base64.RawURLEncoding.EncodeToString(a.ecKey.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func (a *AuthTestSuite) octJWK(kid string) map[string]string {
+	return map[string]string{"kty": "oct", "kid": kid, "k": base64.RawURLEncoding.EncodeToString(a.secret)}
+}
+
+func (a *AuthTestSuite) writeKeySet(keys ...map[string]string) {
+	buff, err := json.Marshal(map[string]any{"keys": keys})
+	a.Require().NoError(err)
+	a.Require().NoError(os.WriteFile(a.jwksPath, buff, 0o600))
+}
+
+func b64(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func TestAuth(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
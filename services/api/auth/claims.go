@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// Claims are the (verified) claims carried by a token
+type Claims map[string]any
+
+// Policy decides whether or not the claims permit the request, returning an error explaining
+// why if they do not
+type Policy func(ctx context.Context, claims Claims) error
+
+// ContextWithClaims returns a context whose FieldMap carries the claims, and their subject,
+// so that they are available to request handlers and are included by utils.Logger
+func ContextWithClaims(ctx context.Context, claims Claims) context.Context {
+	ctx = utils.AddFieldToContext(ctx, shared.ClaimsContextKey, claims)
+
+	return utils.AddFieldToContext(ctx, shared.SubjectContextKey, claims.Subject())
+}
+
+// ClaimsFromContext returns the claims of the request's token, if it was authenticated
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	return utils.GetFieldValueFromContext[Claims](ctx, shared.ClaimsContextKey)
+}
+
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+func (c Claims) Issuer() string {
+	return c.String("iss")
+}
+
+// Audience returns the 'aud' claim which may have been provided as either a single
+// string or an array of strings
+func (c Claims) Audience() []string {
+	return c.Strings("aud")
+}
+
+func (c Claims) ExpiresAt() (time.Time, bool) {
+	return c.Time("exp")
+}
+
+func (c Claims) NotBefore() (time.Time, bool) {
+	return c.Time("nbf")
+}
+
+func (c Claims) IssuedAt() (time.Time, bool) {
+	return c.Time("iat")
+}
+
+// Scopes returns the scopes granted to the token, taken from either the space delimited
+// 'scope' claim (RFC 8693) or the 'scp' claim
+func (c Claims) Scopes() []string {
+	if scope, OK := c["scope"].(string); OK {
+		return strings.Fields(scope)
+	}
+
+	return c.Strings("scp")
+}
+
+// Roles returns the roles held by the token's subject, taken from the 'roles' claim
+func (c Claims) Roles() []string {
+	return c.Strings("roles")
+}
+
+// String returns the value of the claim if it is a string
+func (c Claims) String(name string) string {
+	val, _ := c[name].(string)
+	return val
+}
+
+// Strings returns the value of the claim if it is either a string or an array of strings
+func (c Claims) Strings(name string) []string {
+	switch val := c[name].(type) {
+	case string:
+		return []string{val}
+	case []string:
+		return val
+	case []any:
+		values := make([]string, 0, len(val))
+		for _, v := range val {
+			if s, OK := v.(string); OK {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// Time returns the value of the claim if it is a NumericDate (seconds since the epoch)
+func (c Claims) Time(name string) (time.Time, bool) {
+	var secs float64
+
+	switch val := c[name].(type) {
+	case float64:
+		secs = val
+	case int64:
+		secs = float64(val)
+	case int:
+		secs = float64(val)
+	case json.Number:
+		f, err := val.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		secs = f
+	default:
+		return time.Time{}, false
+	}
+
+	whole, frac := math.Modf(secs)
+
+	return time.Unix(int64(whole), int64(frac*float64(time.Second))), true
+}
@@ -0,0 +1,34 @@
+package auth
+
+import "time"
+
+// nolint: unused
+const (
+	DefaultClockSkew          = time.Minute
+	DefaultRefreshInterval    = 15 * time.Minute
+	DefaultMinRefreshInterval = 30 * time.Second
+	DefaultFetchTimeout       = 10 * time.Second
+)
+
+// nolint: unused
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgHS256 = "HS256"
+)
+
+// nolint: unused
+const (
+	JWKSEnvKey      = "AUTH_JWKS" // a file path or an http(s) URL
+	IssuerEnvKey    = "AUTH_ISSUER"
+	AudienceEnvKey  = "AUTH_AUDIENCE" // comma separated
+	ClockSkewEnvKey = "AUTH_CLOCK_SKEW"
+)
+
+// nolint: unused
+const (
+	HeaderAuthorization   = "Authorization"
+	HeaderWWWAuthenticate = "WWW-Authenticate"
+
+	BearerScheme = "Bearer"
+)
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// NewVerifierFromEnv will instantiate and return a Verifier for the key set, issuer and
+// audience named within the environment... only the key set is required
+func NewVerifierFromEnv(env utils.Environ, options ...Option) (*Verifier, error) {
+	location, err := env.GetRequired(JWKSEnvKey)
+	if err != nil {
+		return nil, err
+	}
+
+	newopt := []Option{}
+
+	if issuer, OK := env.Get(IssuerEnvKey); OK && issuer != "" {
+		newopt = append(newopt, WithIssuer(issuer))
+	}
+
+	if audience, OK := env.Get(AudienceEnvKey); OK && audience != "" {
+		newopt = append(newopt, WithAudience(strings.Split(audience, ",")...))
+	}
+
+	skew, OK, err := env.GetInt(ClockSkewEnvKey)
+	if err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
+	} else if OK {
+		newopt = append(newopt, WithClockSkew(time.Duration(skew)*time.Second))
+	}
+
+	return NewVerifier(NewKeySet(location), append(newopt, options...)...), nil
+}
+
+// NewVerifier will instantiate and return a Verifier that verifies tokens using the keys
+func NewVerifier(keys KeySource, options ...Option) *Verifier {
+	verifier := Verifier{
+		keys: keys,
+		skew: DefaultClockSkew,
+		now:  time.Now,
+	}
+
+	WithAlgorithms(AlgRS256, AlgES256, AlgHS256)(&verifier)
+
+	for _, option := range options {
+		option(&verifier)
+	}
+
+	return &verifier
+}
+
+// NewKeySet will instantiate and return a KeySet that is read from the location, which is
+// either a local file path or an http(s) URL.  The key set is not read until it is needed.
+func NewKeySet(location string, options ...KeySetOption) *KeySet {
+	keys := KeySet{
+		location:           location,
+		client:             http.DefaultClient,
+		refreshInterval:    DefaultRefreshInterval,
+		minRefreshInterval: DefaultMinRefreshInterval,
+		now:                time.Now,
+	}
+
+	for _, option := range options {
+		option(&keys)
+	}
+
+	return &keys
+}
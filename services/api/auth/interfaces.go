@@ -0,0 +1,10 @@
+package auth
+
+import "context"
+
+// KeySource provides the keys against which the signature of a token is verified
+type KeySource interface {
+	// Keys returns the candidate keys for the key id (kid) of a token, which may be
+	// empty in which case every known key is a candidate
+	Keys(ctx context.Context, kid string) ([]Key, error)
+}
@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/djmarrerajr/common-lib/errs"
+)
+
+// Key is a single verification key from a JSON Web Key Set, the public key being
+// one of *rsa.PublicKey, *ecdsa.PublicKey or []byte (the secret of an HMAC key)
+type Key struct {
+	ID        string
+	Algorithm string // when provided, the only algorithm for which the key may be used
+	Public    any
+}
+
+// supports determines whether or not the key can be used to verify the algorithm
+func (k Key) supports(alg string) bool {
+	if k.Algorithm != "" && k.Algorithm != alg {
+		return false
+	}
+
+	switch k.Public.(type) {
+	case *rsa.PublicKey:
+		return alg == AlgRS256
+	case *ecdsa.PublicKey:
+		return alg == AlgES256
+	case []byte:
+		return alg == AlgHS256
+	default:
+		return false
+	}
+}
+
+// KeySet is a KeySource backed by a JSON Web Key Set (RFC 7517) that is read from either
+// a local file or a URL
+//
+// The keys are cached and reloaded once the refresh interval has elapsed so that rotated
+// keys are picked up... a token signed by an unknown key will also cause the keys to be
+// reloaded, though no more often than the minimum refresh interval.  Should a reload fail
+// the previously loaded keys continue to be used, and it is not attempted again until the
+// minimum refresh interval has elapsed.  The keys are reloaded by a single caller, without
+// holding up those verifying tokens with the keys already loaded.
+type KeySet struct {
+	mu    sync.RWMutex
+	group singleflight.Group
+
+	location string
+	client   *http.Client
+
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+
+	keys      []Key
+	err       error     // the error of the most recent reload, should it have failed
+	fetched   time.Time // when the keys were last loaded
+	attempted time.Time // when the keys were last reloaded, successfully or not
+	now       func() time.Time
+}
+
+// Keys returns the candidate keys for the key id, reloading the key set if necessary
+func (s *KeySet) Keys(ctx context.Context, kid string) ([]Key, error) {
+	s.mu.RLock()
+	loaded := s.keys != nil
+	stale := !loaded || s.now().Sub(s.fetched) >= s.refreshInterval
+	s.mu.RUnlock()
+
+	if stale {
+		_ = s.reloadOnce(ctx, false)
+	}
+
+	s.mu.RLock()
+	keys, err := s.match(kid), s.err
+	loaded = s.keys != nil
+	s.mu.RUnlock()
+
+	if !loaded {
+		return nil, err
+	}
+
+	// the key may have been rotated since we last looked...
+	if len(keys) == 0 {
+		if err := s.reloadOnce(ctx, false); err == nil {
+			s.mu.RLock()
+			keys = s.match(kid)
+			s.mu.RUnlock()
+		}
+	}
+
+	return keys, nil
+}
+
+// Refresh will immediately reload the key set
+func (s *KeySet) Refresh(ctx context.Context) error {
+	return s.reloadOnce(ctx, true)
+}
+
+// mayReload determines whether or not the minimum refresh interval has elapsed since the
+// key set was last reloaded (or an attempt was made to)
+func (s *KeySet) mayReload() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.attempted.IsZero() || s.now().Sub(s.attempted) >= s.minRefreshInterval
+}
+
+// match returns the loaded keys for the key id, the caller must hold the read lock
+func (s *KeySet) match(kid string) []Key {
+	if kid == "" {
+		return s.keys
+	}
+
+	var keys []Key
+
+	for _, key := range s.keys {
+		if key.ID == kid {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// reloadOnce will reload the key set, unless forced only should the minimum refresh interval
+// have elapsed, concurrent callers sharing the one reload... which is not abandoned should
+// the caller that made it go away, as the others still wait on it
+func (s *KeySet) reloadOnce(ctx context.Context, force bool) error {
+	_, err, _ := s.group.Do("reload", func() (any, error) {
+		if !force && !s.mayReload() {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+
+			return nil, s.err
+		}
+
+		return nil, s.reload(context.WithoutCancel(ctx))
+	})
+
+	return err
+}
+
+func (s *KeySet) reload(ctx context.Context) error {
+	attempted := s.now()
+
+	keys, err := s.load(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempted, s.err = attempted, err
+	if err != nil {
+		return err
+	}
+
+	s.keys, s.fetched = keys, attempted
+
+	return nil
+}
+
+func (s *KeySet) load(ctx context.Context) ([]Key, error) {
+	buff, err := s.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKeySet(buff)
+}
+
+func (s *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !isURL(s.location) {
+		buff, err := os.ReadFile(s.location)
+		if err != nil {
+			return nil, errs.Wrapf(err, errs.ErrTypeConfiguration, "unable to read key set '%s'", s.location)
+		}
+
+		return buff, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.location, nil)
+	if err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errs.Wrapf(err, errs.ErrTypeUnknown, "unable to fetch key set '%s'", s.location)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errs.Errorf(errs.ErrTypeUnknown, "unable to fetch key set '%s': %s", s.location, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// jwk is the JSON representation of a single key within a key set
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// ParseKeySet returns the verification keys of the JSON Web Key Set... keys that are not
+// for signing, or whose type is not supported, are ignored
+func ParseKeySet(buff []byte) ([]Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(buff, &set); err != nil {
+		return nil, errs.Wrap(err, errs.ErrTypeConfiguration, "invalid key set")
+	}
+
+	keys := make([]Key, 0, len(set.Keys))
+
+	for _, entry := range set.Keys {
+		if entry.Use != "" && entry.Use != "sig" {
+			continue
+		}
+
+		public, err := entry.publicKey()
+		if err != nil {
+			return nil, errs.Wrapf(err, errs.ErrTypeConfiguration, "invalid key '%s'", entry.Kid)
+		}
+
+		if public != nil {
+			keys = append(keys, Key{ID: entry.Kid, Algorithm: entry.Alg, Public: public})
+		}
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		// ensure that the point is actually on the curve...
+		if _, err = ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	buff, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buff) == 0 {
+		return nil, fmt.Errorf("missing value")
+	}
+
+	return new(big.Int).SetBytes(buff), nil
+}
+
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+)
+
+type Option func(*Verifier)
+
+type KeySetOption func(*KeySet)
+
+// WithIssuer will require that tokens were issued by the specified issuer
+func WithIssuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithAudience will require that tokens are intended for at least one of the audiences
+func WithAudience(audience ...string) Option {
+	return func(v *Verifier) {
+		v.audience = append(v.audience, audience...)
+	}
+}
+
+// WithClockSkew will set the leeway allowed when checking the validity period of tokens
+func WithClockSkew(skew time.Duration) Option {
+	return func(v *Verifier) {
+		v.skew = skew
+	}
+}
+
+// WithAlgorithms will restrict the signing algorithms that are accepted (by default
+// RS256, ES256 and HS256 are all accepted)
+func WithAlgorithms(algorithms ...string) Option {
+	return func(v *Verifier) {
+		v.algorithms = make(map[string]struct{}, len(algorithms))
+		for _, alg := range algorithms {
+			v.algorithms[alg] = struct{}{}
+		}
+	}
+}
+
+// WithClock will replace the function used to obtain the current time
+func WithClock(now func() time.Time) Option {
+	return func(v *Verifier) {
+		v.now = now
+	}
+}
+
+// WithHTTPClient will set the client used to fetch a key set from a URL
+func WithHTTPClient(client *http.Client) KeySetOption {
+	return func(s *KeySet) {
+		s.client = client
+	}
+}
+
+// WithRefreshInterval will set how often the key set is reloaded and the minimum interval
+// between the reloads caused by tokens signed with an unknown key
+func WithRefreshInterval(refresh, minRefresh time.Duration) KeySetOption {
+	return func(s *KeySet) {
+		if refresh > 0 {
+			s.refreshInterval = refresh
+		}
+		if minRefresh > 0 {
+			s.minRefreshInterval = minRefresh
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/djmarrerajr/common-lib/errs"
+)
+
+// header is the JOSE header of a compact JWS
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// token is a parsed, but not yet verified, JWT
+type token struct {
+	header    header
+	claims    Claims
+	signed    []byte // the signing input, i.e. <header>.<payload>
+	signature []byte
+}
+
+// parseToken will decode the compact serialization of a JWT (<header>.<payload>.<signature>)
+func parseToken(raw string) (token, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return token{}, errs.New(errs.ErrTypeAuthentication, "malformed token")
+	}
+
+	var tkn token
+
+	buff, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(buff, &tkn.header) != nil {
+		return token{}, errs.New(errs.ErrTypeAuthentication, "malformed token header")
+	}
+
+	buff, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return token{}, errs.New(errs.ErrTypeAuthentication, "malformed token claims")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(buff))
+	decoder.UseNumber()
+
+	if err = decoder.Decode(&tkn.claims); err != nil || tkn.claims == nil {
+		return token{}, errs.New(errs.ErrTypeAuthentication, "malformed token claims")
+	}
+
+	tkn.signature, err = base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return token{}, errs.New(errs.ErrTypeAuthentication, "malformed token signature")
+	}
+
+	tkn.signed = []byte(parts[0] + "." + parts[1])
+
+	return tkn, nil
+}
+
+// verify determines whether or not the token was signed by the key
+func (t token) verify(key Key) bool {
+	if !key.supports(t.header.Alg) {
+		return false
+	}
+
+	digest := sha256.Sum256(t.signed)
+
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], t.signature) == nil
+	case *ecdsa.PublicKey:
+		// the signature is the concatenation of R and S rather than ASN.1...
+		if len(t.signature) != 64 {
+			return false
+		}
+
+		r := new(big.Int).SetBytes(t.signature[:32])
+		s := new(big.Int).SetBytes(t.signature[32:])
+
+		return ecdsa.Verify(public, digest[:], r, s)
+	case []byte:
+		mac := hmac.New(sha256.New, public)
+		mac.Write(t.signed)
+
+		return hmac.Equal(mac.Sum(nil), t.signature)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+)
+
+// Verifier verifies the signature and registered claims (issuer, audience and validity
+// period) of bearer tokens
+type Verifier struct {
+	keys KeySource
+
+	issuer     string
+	audience   []string
+	skew       time.Duration
+	algorithms map[string]struct{}
+	now        func() time.Time
+}
+
+// Verify will verify the token, returning its claims if it is valid... any failure is
+// reported as an errs.ErrTypeAuthentication error
+func (v *Verifier) Verify(ctx context.Context, raw string) (Claims, error) {
+	tkn, err := parseToken(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, OK := v.algorithms[tkn.header.Alg]; !OK {
+		return nil, errs.Errorf(errs.ErrTypeAuthentication, "unsupported signing algorithm '%s'", tkn.header.Alg)
+	}
+
+	keys, err := v.keys.Keys(ctx, tkn.header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	verified := false
+	for idx := 0; idx < len(keys) && !verified; idx++ {
+		verified = tkn.verify(keys[idx])
+	}
+
+	if !verified {
+		return nil, errs.New(errs.ErrTypeAuthentication, "invalid token signature")
+	}
+
+	if err = v.validate(tkn.claims); err != nil {
+		return nil, err
+	}
+
+	return tkn.claims, nil
+}
+
+// VerifyRequest will verify the bearer token carried by the request's Authorization header
+func (v *Verifier) VerifyRequest(r *http.Request) (Claims, error) {
+	scheme, raw, _ := strings.Cut(r.Header.Get(HeaderAuthorization), " ")
+	if !strings.EqualFold(scheme, BearerScheme) || strings.TrimSpace(raw) == "" {
+		return nil, errs.New(errs.ErrTypeAuthentication, "missing bearer token")
+	}
+
+	return v.Verify(r.Context(), strings.TrimSpace(raw))
+}
+
+// validate checks the registered claims... the expiry is required while the 'not before'
+// and 'issued at' claims are only checked when present
+func (v *Verifier) validate(claims Claims) error {
+	now := v.now()
+
+	exp, OK := claims.ExpiresAt()
+	if !OK {
+		return errs.New(errs.ErrTypeAuthentication, "token has no expiry")
+	}
+
+	if now.After(exp.Add(v.skew)) {
+		return errs.New(errs.ErrTypeAuthentication, "token has expired")
+	}
+
+	if nbf, OK := claims.NotBefore(); OK && now.Add(v.skew).Before(nbf) {
+		return errs.New(errs.ErrTypeAuthentication, "token is not yet valid")
+	}
+
+	if iat, OK := claims.IssuedAt(); OK && now.Add(v.skew).Before(iat) {
+		return errs.New(errs.ErrTypeAuthentication, "token was issued in the future")
+	}
+
+	if v.issuer != "" && claims.Issuer() != v.issuer {
+		return errs.Errorf(errs.ErrTypeAuthentication, "token issuer '%s' is not trusted", claims.Issuer())
+	}
+
+	if len(v.audience) > 0 && !intersects(v.audience, claims.Audience()) {
+		return errs.New(errs.ErrTypeAuthentication, "token is not intended for this audience")
+	}
+
+	return nil
+}
+
+func intersects(want, have []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if w == h {
+				return true
+			}
+		}
+	}
+
+	return false
+}
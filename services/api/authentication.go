@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/api/auth"
+)
+
+// WithAuthentication will add a middleware function that requires each request to carry a
+// bearer token that is valid according to the auth.Verifier, i.e.
+//
+//	verifier, _ := auth.NewVerifierFromEnv(env)
+//	api.WithAuthentication(verifier, "/public")
+//
+// The token's claims are added to the request's context (see auth.ClaimsFromContext) while
+// requests without a valid token are rejected with an errs.ErrTypeAuthentication error.  The
+// routes to skip may be given as either paths or route templates, the health and metrics
+// routes, and CORS preflight requests, are always skipped.
+func WithAuthentication(verifier *auth.Verifier, routesToSkip ...string) Option {
+	return func(s *Server) {
+		WithRequestMiddleware(authenticationMiddleware(s, verifier, routesToSkip...))(s)
+	}
+}
+
+func authenticationMiddleware(s *Server, verifier *auth.Verifier, routesToSkip ...string) mux.MiddlewareFunc {
+	routes := newSkippedRoutes(routesToSkip...)
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isSkipped(routes, r) || isPreflight(r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := verifier.VerifyRequest(r)
+			if err != nil {
+				if errs.GetType(err) == errs.ErrTypeAuthentication {
+					challenge := auth.BearerScheme
+					if r.Header.Get(auth.HeaderAuthorization) != "" {
+						challenge = fmt.Sprintf(`%s error="invalid_token"`, auth.BearerScheme)
+					}
+
+					w.Header().Set(auth.HeaderWWWAuthenticate, challenge)
+				}
+
+				returnMiddlewareError(s, &s.AppCtx, w, r, err, 0)
+
+				return
+			}
+
+			h.ServeHTTP(w, r.WithContext(auth.ContextWithClaims(r.Context(), claims)))
+		})
+	}
+}
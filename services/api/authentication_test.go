@@ -0,0 +1,110 @@
+package api_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/services/api/auth"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+var testSecret = []byte("super-secret-shared-key")
+
+func (h *HandlerTestSuite) TestAuthentication_ClaimsAvailableToHandler() {
+	server := h.newAuthServer()
+
+	var subject string
+
+	server.DefineRequestHandler("/whoami", func(ctx context.Context, _ *shared.ApplicationContext, _ any) (any, int) {
+		claims, _ := auth.ClaimsFromContext(ctx)
+		subject, _ = utils.GetFieldValueFromContext[string](ctx, shared.SubjectContextKey)
+		return claims.Subject(), 0
+	}, nil, http.MethodGet)
+
+	rec := h.authGet(server, "/whoami", "Bearer "+signHS256(map[string]any{"sub": "bruno", "exp": time.Now().Add(time.Hour).Unix()}))
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.Equal("bruno", rec.Body.String())
+	h.Equal("bruno", subject)
+}
+
+func (h *HandlerTestSuite) TestAuthentication_InvalidTokensAreUnauthorized() {
+	server := h.newAuthServer()
+
+	tests := []struct {
+		name      string
+		header    string
+		challenge string
+	}{
+		{name: "no token", header: "", challenge: `Bearer`},
+		{name: "wrong scheme", header: "Basic YnJ1bm86c2VjcmV0", challenge: `Bearer error="invalid_token"`},
+		{name: "expired token", header: "Bearer " + signHS256(map[string]any{"sub": "bruno", "exp": time.Now().Add(-time.Hour).Unix()}), challenge: `Bearer error="invalid_token"`},
+	}
+
+	for _, test := range tests {
+		h.Run(test.name, func() {
+			rec := h.authGet(server, "/greeting", test.header)
+
+			h.Equal(http.StatusUnauthorized, rec.Code)
+			h.Equal(test.challenge, rec.Header().Get(auth.HeaderWWWAuthenticate))
+			h.Equal(errs.ErrTypeAuthentication, h.decodeError(rec).Type)
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestAuthentication_SkippedRoutes() {
+	server := h.newAuthServer("/public/{name}")
+
+	server.DefineRequestHandler("/public/{name}", func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return "hello", 0
+	}, nil, http.MethodGet)
+
+	h.Equal(http.StatusOK, h.authGet(server, "/public/bruno", "").Code)
+	h.Equal(http.StatusUnauthorized, h.authGet(server, "/greeting", "").Code)
+}
+
+func (h *HandlerTestSuite) newAuthServer(routesToSkip ...string) *api.Server {
+	path := filepath.Join(h.T().TempDir(), "jwks.json")
+
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "test", "k": base64.RawURLEncoding.EncodeToString(testSecret)},
+	}})
+	h.Require().NoError(os.WriteFile(path, jwks, 0o600))
+
+	return h.newGreetingServer(api.WithAuthentication(auth.NewVerifier(auth.NewKeySet(path)), routesToSkip...))
+}
+
+func (h *HandlerTestSuite) authGet(server *api.Server, path, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if authorization != "" {
+		req.Header.Set(auth.HeaderAuthorization, authorization)
+	}
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func signHS256(claims map[string]any) string {
+	hdr, _ := json.Marshal(map[string]string{"alg": auth.AlgHS256, "kid": "test"})
+	payload, _ := json.Marshal(claims)
+
+	input := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(input))
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/services/api/auth"
+	"github.com/djmarrerajr/common-lib/shared"
+)
+
+// authorize will ensure that the claims of the caller's token satisfy the scopes, roles
+// and policies required by the route (see RouteScopes, RouteRoles and RoutePolicy)
+//
+// A route having no such requirements is open to any caller, otherwise a caller that has
+// not been authenticated (see WithAuthentication) is refused with an errs.ErrTypeAuthentication
+// error and one that lacks the necessary permissions with an errs.ErrTypeAuthorization error.
+// Each refusal is counted by the 'authorization_denials_total' metric.
+func (h ContextualHandler) authorize(ctx context.Context, r *http.Request) error {
+	if !requiresAuthorization(h.config) {
+		return nil
+	}
+
+	reason, err := h.evaluate(ctx)
+	if err != nil && h.denials != nil {
+		h.denials.WithLabelValues(append(shared.MetricFilterValues(h.RootCtx), metricPath(r), reason)...).Inc()
+	}
+
+	return err
+}
+
+// requiresAuthorization determines whether or not the route has any scopes, roles or
+// policies that its callers must satisfy
+func requiresAuthorization(config RouteConfig) bool {
+	return len(config.Scopes) > 0 || len(config.Roles) > 0 || len(config.Policies) > 0
+}
+
+// newDenialCounter returns the counter by which the route's refusals are counted, or nil
+// should the route have no requirements (or the application no Collector)
+func newDenialCounter(appCtx shared.ApplicationContext, config RouteConfig) *metrics.DimensionedCounter {
+	if !requiresAuthorization(config) {
+		return nil
+	}
+
+	return newDimensionedCounter(appCtx, "authorization_denials_total", "path", "reason")
+}
+
+// evaluate returns the reason (unauthenticated, scope, role or policy) for which the
+// request is refused, if it is
+func (h ContextualHandler) evaluate(ctx context.Context) (string, error) {
+	claims, OK := auth.ClaimsFromContext(ctx)
+	if !OK {
+		return "unauthenticated", errs.New(errs.ErrTypeAuthentication, "request has not been authenticated")
+	}
+
+	if missing := difference(h.config.Scopes, claims.Scopes()); len(missing) > 0 {
+		return "scope", errs.Errorf(errs.ErrTypeAuthorization, "missing required scopes: %s", strings.Join(missing, ", "))
+	}
+
+	if len(h.config.Roles) > 0 && len(difference(h.config.Roles, claims.Roles())) == len(h.config.Roles) {
+		return "role", errs.Errorf(errs.ErrTypeAuthorization, "requires one of the roles: %s", strings.Join(h.config.Roles, ", "))
+	}
+
+	for _, policy := range h.config.Policies {
+		if err := policy(ctx); err != nil {
+			return "policy", errs.WithTypeFallback(err, errs.ErrTypeAuthorization)
+		}
+	}
+
+	return "", nil
+}
+
+// difference returns those values that were wanted but are not held
+func difference(want, have []string) []string {
+	held := make(map[string]struct{}, len(have))
+	for _, value := range have {
+		held[value] = struct{}{}
+	}
+
+	var missing []string
+
+	for _, value := range want {
+		if _, OK := held[value]; !OK {
+			missing = append(missing, value)
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,98 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/services/api/auth"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+func (h *HandlerTestSuite) TestAuthorization_RouteRequirements() {
+	server := h.newAuthServer("/open")
+
+	server.DefineRequestHandlerWithOptions("/orders", noop, nil,
+		api.RouteMethods(http.MethodGet),
+		api.RouteScopes("orders:read", "orders:write"),
+		api.RouteRoles("admin", "clerk"),
+		api.RoutePolicy(func(_ context.Context, claims auth.Claims) error {
+			if claims.String("tenant") != "acme" {
+				return fmt.Errorf("tenant '%s' may not access orders", claims.String("tenant"))
+			}
+			return nil
+		}),
+	)
+	server.DefineRequestHandlerWithOptions("/open", noop, nil, api.RouteMethods(http.MethodGet), api.RouteScopes("orders:read"))
+
+	valid := map[string]any{"scope": "orders:read orders:write", "roles": []string{"clerk"}, "tenant": "acme"}
+
+	tests := []struct {
+		name     string
+		path     string
+		override map[string]any
+		status   int
+		errType  errs.ErrorType
+	}{
+		{name: "permitted", path: "/orders", status: http.StatusOK},
+		{name: "missing scope", path: "/orders", override: map[string]any{"scope": "orders:read"}, status: http.StatusForbidden, errType: errs.ErrTypeAuthorization},
+		{name: "scp claim", path: "/orders", override: map[string]any{"scope": nil, "scp": []string{"orders:read", "orders:write"}}, status: http.StatusOK},
+		{name: "missing role", path: "/orders", override: map[string]any{"roles": []string{"guest"}}, status: http.StatusForbidden, errType: errs.ErrTypeAuthorization},
+		{name: "policy refusal", path: "/orders", override: map[string]any{"tenant": "initech"}, status: http.StatusForbidden, errType: errs.ErrTypeAuthorization},
+		{name: "unauthenticated", path: "/open", status: http.StatusUnauthorized, errType: errs.ErrTypeAuthentication},
+	}
+
+	for _, test := range tests {
+		h.Run(test.name, func() {
+			claims := map[string]any{"sub": "bruno", "exp": time.Now().Add(time.Hour).Unix()}
+			for key, value := range valid {
+				claims[key] = value
+			}
+			for key, value := range test.override {
+				if value == nil {
+					delete(claims, key)
+				} else {
+					claims[key] = value
+				}
+			}
+
+			authorization := "Bearer " + signHS256(claims)
+			if test.errType == errs.ErrTypeAuthentication {
+				authorization = ""
+			}
+
+			rec := h.authGet(server, test.path, authorization)
+
+			h.Equal(test.status, rec.Code)
+			if test.errType != "" {
+				h.Equal(test.errType, h.decodeError(rec).Type)
+			}
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestAuthorization_DenialsAreCounted() {
+	collector, err := metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "test")
+	h.Require().NoError(err)
+
+	h.appctx.Collector = collector
+
+	server := h.newAuthServer()
+	server.DefineRequestHandlerWithOptions("/admin", func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return nil, 0
+	}, nil, api.RouteMethods(http.MethodGet), api.RouteRoles("admin"))
+
+	rec := h.authGet(server, "/admin", "Bearer "+signHS256(map[string]any{"sub": "bruno", "exp": time.Now().Add(time.Hour).Unix()}))
+	h.Equal(http.StatusForbidden, rec.Code)
+
+	scrape := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, api.MetricsPath, nil))
+
+	h.Contains(scrape.Body.String(), `test_authorization_denials_total{appName="",appVersion="",environ="",hostname="",path="admin",reason="role"} 1`)
+}
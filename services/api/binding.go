@@ -0,0 +1,182 @@
+package api
+
+import (
+	"encoding"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/djmarrerajr/common-lib/errs"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// valueSource returns the values, if any, that were provided for a name
+type valueSource func(name string) []string
+
+// bindRequest will populate each of the fields within the request struct that have been
+// tagged with one of 'path', 'query', 'header' or 'form' from the corresponding part of
+// the request, i.e.
+//
+//	type GetAccountRequest struct {
+//		ID     uuid.UUID `path:"id"`
+//		Limit  int       `query:"limit"`
+//		Tenant string    `header:"X-Tenant"`
+//	}
+//
+// Slices may be provided either as repeated values or as a single comma separated value
+func bindRequest(r *http.Request, data any) error {
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	vars := mux.Vars(r)
+
+	sources := map[string]valueSource{
+		TagPath: func(name string) []string {
+			if v, OK := vars[name]; OK {
+				return []string{v}
+			}
+			return nil
+		},
+		TagQuery: func(name string) []string {
+			return r.URL.Query()[name]
+		},
+		TagHeader: func(name string) []string {
+			return r.Header.Values(name)
+		},
+		TagForm: func(name string) []string {
+			return r.PostForm[name]
+		},
+	}
+
+	return bindStruct(value.Elem(), sources)
+}
+
+// bindStruct will bind each of the tagged fields of the struct, descending in to any
+// embedded (anonymous) structs
+func bindStruct(value reflect.Value, sources map[string]valueSource) error {
+	for idx := 0; idx < value.NumField(); idx++ {
+		field := value.Type().Field(idx)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := bindStruct(value.Field(idx), sources); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, tag := range []string{TagPath, TagQuery, TagHeader, TagForm} {
+			name, OK := field.Tag.Lookup(tag)
+			if !OK || name == "" || name == "-" {
+				continue
+			}
+
+			values := sources[tag](name)
+			if len(values) == 0 {
+				continue
+			}
+
+			if err := setField(value.Field(idx), values); err != nil {
+				return errs.Wrapf(err, errs.ErrTypeUnmarshal, "invalid %s parameter '%s'", tag, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// setField will convert the values to the type of the field and assign them
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && !field.Type().Implements(textUnmarshalerType) && field.Type().Elem().Kind() != reflect.Uint8 {
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for idx, v := range values {
+			if err := setValue(slice.Index(idx), strings.TrimSpace(v)); err != nil {
+				return err
+			}
+		}
+
+		field.Set(slice)
+
+		return nil
+	}
+
+	return setValue(field, values[0])
+}
+
+// setValue will convert the string to the type of the target and assign it
+func setValue(target reflect.Value, value string) error {
+	if target.Kind() == reflect.Pointer {
+		ptr := reflect.New(target.Type().Elem())
+		if err := setValue(ptr.Elem(), value); err != nil {
+			return err
+		}
+
+		target.Set(ptr)
+
+		return nil
+	}
+
+	if target.CanAddr() && target.Addr().Type().Implements(textUnmarshalerType) {
+		return target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	if target.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+
+		target.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		target.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetFloat(f)
+	default:
+		return errs.Errorf(errs.ErrTypeUnmarshal, "unsupported type: %s", target.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+)
+
+type boundRequest struct {
+	ID      uuid.UUID     `path:"id"`
+	Limit   int           `query:"limit" validate:"max=100"`
+	Verbose *bool         `query:"verbose"`
+	Tags    []string      `query:"tag"`
+	Wait    time.Duration `query:"wait"`
+	Tenant  string        `header:"X-Tenant"`
+	Name    string        `json:"name" form:"name"`
+}
+
+func (h *HandlerTestSuite) TestBinding_PathQueryAndHeader() {
+	id := uuid.New()
+
+	var bound *boundRequest
+	server := h.newBindingServer(&bound)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/"+id.String()+"?limit=10&verbose=true&tag=a,b&wait=1s", nil)
+	req.Header.Set("X-Tenant", "acme")
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.Equal(boundRequest{ID: id, Limit: 10, Verbose: bound.Verbose, Tags: []string{"a", "b"}, Wait: time.Second, Tenant: "acme"}, *bound)
+	h.True(*bound.Verbose)
+}
+
+func (h *HandlerTestSuite) TestBinding_RepeatedQueryValuesAndJsonBody() {
+	var bound *boundRequest
+	server := h.newBindingServer(&bound)
+
+	req := httptest.NewRequest(http.MethodPost, "/accounts/"+uuid.NewString()+"?tag=a&tag=b", strings.NewReader(`{"name":"bruno"}`))
+	req.Header.Set(api.HeaderContentType, api.ValueApplicationJson)
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.Equal([]string{"a", "b"}, bound.Tags)
+	h.Equal("bruno", bound.Name)
+}
+
+func (h *HandlerTestSuite) TestBinding_Form() {
+	var bound *boundRequest
+	server := h.newBindingServer(&bound)
+
+	req := httptest.NewRequest(http.MethodPost, "/accounts/"+uuid.NewString(), strings.NewReader(url.Values{"name": {"bruno"}}.Encode()))
+	req.Header.Set(api.HeaderContentType, api.ValueFormUrlEncoded)
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.Equal("bruno", bound.Name)
+}
+
+func (h *HandlerTestSuite) TestBinding_ConversionFailureIsUnmarshalError() {
+	var bound *boundRequest
+	server := h.newBindingServer(&bound)
+
+	for _, target := range []string{"/accounts/not-a-uuid", "/accounts/" + uuid.NewString() + "?limit=ten"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set(api.HeaderContentType, api.ValueApplicationJson)
+
+		rec := httptest.NewRecorder()
+		server.Api.Handler.ServeHTTP(rec, req)
+
+		h.Equal(http.StatusBadRequest, rec.Code, target)
+		h.Equal(errs.ErrTypeUnmarshal, h.decodeError(rec).Type, target)
+		h.Nil(bound)
+	}
+}
+
+func (h *HandlerTestSuite) TestBinding_BoundValuesAreValidated() {
+	var bound *boundRequest
+	server := h.newBindingServer(&bound)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/"+uuid.NewString()+"?limit=500", nil)
+	req.Header.Set(api.HeaderContentType, api.ValueApplicationJson)
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	h.Equal(http.StatusUnprocessableEntity, rec.Code)
+}
+
+func (h *HandlerTestSuite) newBindingServer(bound **boundRequest) *api.Server {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) { return nil, 0 })
+
+	server.DefineRequestHandler("/accounts/{id}", func(_ context.Context, _ *shared.ApplicationContext, data any) (any, int) {
+		*bound = data.(*boundRequest)
+		return nil, http.StatusOK
+	}, boundRequest{}, http.MethodGet, http.MethodPost)
+
+	return server
+}
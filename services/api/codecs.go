@@ -0,0 +1,377 @@
+package api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/djmarrerajr/common-lib/errs"
+)
+
+// defaultCodec is used whenever a response cannot be encoded as the API caller requested
+var defaultCodec = NewCodec(ValueApplicationJson, json.Marshal, json.Unmarshal)
+
+// MarshalFunc and UnmarshalFunc share the signatures of json.Marshal/json.Unmarshal which
+// most encoding packages (i.e. YAML, MessagePack) follow
+type MarshalFunc func(any) ([]byte, error)
+type UnmarshalFunc func([]byte, any) error
+
+// NewCodec will return a Codec for the media type that uses the provided functions, i.e.
+//
+//	api.WithCodec(api.NewCodec(api.ValueYaml, yaml.Marshal, yaml.Unmarshal))
+func NewCodec(mediaType string, marshal MarshalFunc, unmarshal UnmarshalFunc) Codec {
+	return funcCodec{mediaType, marshal, unmarshal}
+}
+
+// funcCodec is a Codec built from a pair of marshal/unmarshal functions
+type funcCodec struct {
+	mediaType string
+	marshal   MarshalFunc
+	unmarshal UnmarshalFunc
+}
+
+func (c funcCodec) MediaType() string { return c.mediaType }
+
+func (c funcCodec) Decode(r *http.Request, v any) error {
+	buff, err := io.ReadAll(r.Body)
+	if err != nil || len(buff) == 0 {
+		return err
+	}
+
+	return c.unmarshal(buff, v)
+}
+
+func (c funcCodec) Encode(w io.Writer, v any) error {
+	buff, err := c.marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(buff)
+
+	return err
+}
+
+// decodeStrictJson decodes a JSON request body, as the JSON Codec would, except that any
+// field unknown to the request struct (or any data following the JSON) is refused
+func decodeStrictJson(r *http.Request, v any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+
+		return err
+	}
+
+	switch _, err := decoder.Token(); {
+	case err == io.EOF:
+		return nil
+	case err != nil:
+		return err
+	default:
+		return fmt.Errorf("unexpected data following the json request body")
+	}
+}
+
+// isJson reports whether the media type is that of JSON, i.e. application/json or
+// application/vnd.api+json
+func isJson(mediaType string) bool {
+	return mediaType == ValueApplicationJson || strings.HasSuffix(mediaType, "+json")
+}
+
+// formCodec parses url-encoded and multipart forms, the values of which are then bound
+// to the request struct using its 'form' tags... only url.Values can be encoded
+type formCodec struct {
+	mediaType string
+}
+
+func (c formCodec) MediaType() string { return c.mediaType }
+
+func (c formCodec) Decode(r *http.Request, _ any) error {
+	if c.mediaType == ValueMultipartForm {
+		return r.ParseMultipartForm(DefaultMaxMultipartMemory)
+	}
+
+	return r.ParseForm()
+}
+
+func (c formCodec) Encode(w io.Writer, v any) error {
+	values, OK := v.(url.Values)
+	if !OK || c.mediaType == ValueMultipartForm {
+		return errs.Errorf(errs.ErrTypeMarshal, "unable to encode %T as %s", v, c.mediaType)
+	}
+
+	_, err := io.WriteString(w, values.Encode())
+
+	return err
+}
+
+// textCodec writes strings, byte slices and fmt.Stringers as they are
+type textCodec struct{}
+
+func (textCodec) MediaType() string { return ValueTextPlain }
+
+func (textCodec) Decode(*http.Request, any) error { return nil }
+
+func (textCodec) Encode(w io.Writer, v any) error {
+	var err error
+
+	switch v := v.(type) {
+	case string:
+		_, err = io.WriteString(w, v)
+	case []byte:
+		_, err = w.Write(v)
+	case fmt.Stringer:
+		_, err = io.WriteString(w, v.String())
+	default:
+		err = errs.Errorf(errs.ErrTypeMarshal, "unable to encode %T as %s", v, ValueTextPlain)
+	}
+
+	return err
+}
+
+// CodecRegistry holds the Codecs, keyed by media type, that are available to a Server
+// for the decoding of requests and the encoding of responses
+type CodecRegistry struct {
+	codecs map[string]Codec
+	order  []string
+}
+
+// NewCodecRegistry returns a CodecRegistry containing only the provided Codecs
+func NewCodecRegistry(codecs ...Codec) *CodecRegistry {
+	registry := CodecRegistry{codecs: make(map[string]Codec)}
+
+	for _, codec := range codecs {
+		registry.Register(codec)
+	}
+
+	return &registry
+}
+
+// DefaultCodecRegistry returns a CodecRegistry containing the JSON, XML, form and plain
+// text Codecs... JSON being the default response type
+func DefaultCodecRegistry() *CodecRegistry {
+	return NewCodecRegistry(
+		defaultCodec,
+		NewCodec(ValueApplicationXml, xml.Marshal, xml.Unmarshal),
+		NewCodec(ValueTextXml, xml.Marshal, xml.Unmarshal),
+		formCodec{ValueFormUrlEncoded},
+		formCodec{ValueMultipartForm},
+		textCodec{},
+	)
+}
+
+// Register adds the Codec to the registry, replacing any existing Codec for its media type
+func (c *CodecRegistry) Register(codec Codec) {
+	mediaType := strings.ToLower(codec.MediaType())
+
+	if _, exists := c.codecs[mediaType]; !exists {
+		c.order = append(c.order, mediaType)
+	}
+
+	c.codecs[mediaType] = codec
+}
+
+// Lookup returns the Codec for the media type, any parameters (i.e. charset) are ignored
+func (c *CodecRegistry) Lookup(mediaType string) (Codec, bool) {
+	parsed, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return nil, false
+	}
+
+	codec, OK := c.codecs[parsed]
+
+	return codec, OK
+}
+
+// Negotiate will select the Codec best matching the Accept header, honoring q-values... a
+// wildcard is satisfied by the first of the preferred media types that has a Codec, or else
+// by the first registered Codec, that has not been excluded (q=0).  An empty Accept header
+// is treated as '*/*'.
+func (c *CodecRegistry) Negotiate(accept string, preferred ...string) (Codec, bool) {
+	return c.negotiate(accept, func(Codec) bool { return true }, preferred...)
+}
+
+// negotiate operates like Negotiate except that only those Codecs that are usable are
+// considered (i.e. those that are able to encode a particular response)
+func (c *CodecRegistry) negotiate(accept string, usable func(Codec) bool, preferred ...string) (Codec, bool) {
+	ranges := parseAccept(accept)
+
+	candidate := func(mediaType string) (Codec, bool) {
+		codec, OK := c.Lookup(mediaType)
+		if !OK || !usable(codec) || isExcluded(ranges, codec.MediaType()) {
+			return nil, false
+		}
+
+		return codec, true
+	}
+
+	for _, rng := range ranges {
+		if rng.quality == 0 {
+			continue
+		}
+
+		switch {
+		case rng.mediaType == "*/*":
+			for _, mediaType := range append(append([]string{}, preferred...), c.order...) {
+				if codec, OK := candidate(mediaType); OK {
+					return codec, true
+				}
+			}
+		case strings.HasSuffix(rng.mediaType, "/*"):
+			prefix := strings.TrimSuffix(rng.mediaType, "*")
+
+			for _, mediaType := range append(append([]string{}, preferred...), c.order...) {
+				if !strings.HasPrefix(strings.ToLower(mediaType), prefix) {
+					continue
+				}
+
+				if codec, OK := candidate(mediaType); OK {
+					return codec, true
+				}
+			}
+		default:
+			if codec, OK := candidate(rng.mediaType); OK {
+				return codec, true
+			}
+
+			// i.e. application/problem+json is satisfied by application/json...
+			if idx := strings.LastIndex(rng.mediaType, "+"); idx > 0 {
+				if codec, OK := candidate("application/" + rng.mediaType[idx+1:]); OK {
+					return codec, true
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// mediaRange is a single entry within an Accept header
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept returns the media ranges of the Accept header, most preferred first... those
+// that are explicitly unacceptable (q=0) are retained, last, as they exclude the media types
+// they match from any wildcard (see isExcluded)
+func parseAccept(accept string) []mediaRange {
+	if strings.TrimSpace(accept) == "" {
+		return []mediaRange{{"*/*", 1}}
+	}
+
+	var ranges []mediaRange
+
+	for _, entry := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(entry))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, exists := params["q"]; exists {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mediaType, quality})
+	}
+
+	// the more specific of two equally weighted ranges takes precedence...
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+		return strings.Count(ranges[i].mediaType, "*") < strings.Count(ranges[j].mediaType, "*")
+	})
+
+	return ranges
+}
+
+// isExcluded determines whether or not the media type has been explicitly excluded (q=0),
+// i.e. that the most specific of the ranges matching it is unacceptable
+func isExcluded(ranges []mediaRange, mediaType string) bool {
+	mediaType = strings.ToLower(mediaType)
+
+	specificity, quality := -1, 1.0
+	for _, rng := range ranges {
+		var matched int
+
+		switch {
+		case rng.mediaType == mediaType:
+			matched = 2
+		case rng.mediaType != "*/*" && strings.HasSuffix(rng.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(rng.mediaType, "*")):
+			matched = 1
+		case rng.mediaType == "*/*":
+			matched = 0
+		default:
+			continue
+		}
+
+		if matched > specificity {
+			specificity, quality = matched, rng.quality
+		}
+	}
+
+	return specificity >= 0 && quality == 0
+}
+
+// canEncode determines whether or not the Codec is able to encode the value, those Codecs
+// that only encode values of particular types (i.e. forms, plain text) being unable to
+// encode any other
+func canEncode(codec Codec, v any) bool {
+	switch codec := codec.(type) {
+	case formCodec:
+		_, OK := v.(url.Values)
+		return OK && codec.mediaType != ValueMultipartForm
+	case textCodec:
+		switch v.(type) {
+		case string, []byte, fmt.Stringer:
+			return true
+		default:
+			return false
+		}
+	default:
+		return true
+	}
+}
+
+// isUnspecified determines whether or not the Accept header expresses any preference
+func isUnspecified(accept string) bool {
+	accept = strings.TrimSpace(accept)
+	return accept == "" || accept == "*/*"
+}
+
+// isStructured determines whether or not the Codec is able to encode any response (i.e.
+// JSON, XML) rather than only values of particular types (i.e. forms, plain text)
+func isStructured(codec Codec) bool {
+	switch codec.(type) {
+	case formCodec, textCodec:
+		return false
+	default:
+		return true
+	}
+}
+
+// isRawContent determines whether or not the response is already encoded
+func isRawContent(resp any) bool {
+	switch resp.(type) {
+	case string, []byte:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,240 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+)
+
+type greeting struct {
+	Message string `json:"message" xml:"message"`
+}
+
+func (h *HandlerTestSuite) TestNegotiation_ResponseTypeFollowsAccept() {
+	tests := []struct {
+		name   string
+		accept string
+		ctype  string
+	}{
+		{name: "no accept header defaults to json", accept: "", ctype: api.ValueApplicationJson},
+		{name: "explicit xml", accept: api.ValueApplicationXml, ctype: api.ValueApplicationXml},
+		{name: "highest q-value wins", accept: "application/json;q=0.5, text/xml;q=0.9", ctype: api.ValueTextXml},
+		{name: "specific beats wildcard", accept: "*/*, application/xml", ctype: api.ValueApplicationXml},
+		{name: "unacceptable types are skipped", accept: "application/xml;q=0, */*;q=0.1", ctype: api.ValueApplicationJson},
+		{name: "custom codec", accept: api.ValueYaml, ctype: api.ValueYaml},
+	}
+
+	server := h.newGreetingServer(api.WithCodec(api.NewCodec(api.ValueYaml, yamlish, nil)))
+
+	for _, test := range tests {
+		h.Run(test.name, func() {
+			rec := h.get(server, test.accept)
+
+			h.Equal(http.StatusOK, rec.Code)
+			h.Equal(test.ctype, rec.Header().Get(api.HeaderContentType))
+			h.Contains(rec.Body.String(), "hello")
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestNegotiation_ExclusionsOverrideWildcards() {
+	tests := []struct {
+		name   string
+		accept string
+		code   int
+		ctype  string
+	}{
+		{name: "excluded type is not chosen for a wildcard", accept: "application/json;q=0, */*", code: http.StatusOK, ctype: api.ValueApplicationXml},
+		{name: "excluded type is not chosen for a subtype wildcard", accept: "application/*, application/json;q=0", code: http.StatusOK, ctype: api.ValueApplicationXml},
+		{name: "more specific range overrides an exclusion", accept: "application/*;q=0, application/json", code: http.StatusOK, ctype: api.ValueApplicationJson},
+		{name: "everything excluded", accept: "*/*;q=0", code: http.StatusNotAcceptable, ctype: api.ValueApplicationJson},
+	}
+
+	server := h.newGreetingServer()
+
+	for _, test := range tests {
+		h.Run(test.name, func() {
+			rec := h.get(server, test.accept)
+
+			h.Equal(test.code, rec.Code)
+			h.Equal(test.ctype, rec.Header().Get(api.HeaderContentType))
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestNegotiation_CodecsThatCannotEncodeAreSkipped() {
+	tests := []struct {
+		name   string
+		accept string
+		code   int
+		ctype  string
+	}{
+		{name: "text falls back to the next acceptable", accept: "text/plain, application/xml;q=0.5", code: http.StatusOK, ctype: api.ValueApplicationXml},
+		{name: "text falls back to a wildcard", accept: "text/plain, */*;q=0.1", code: http.StatusOK, ctype: api.ValueApplicationJson},
+		{name: "form falls back to a wildcard", accept: "application/x-www-form-urlencoded, */*;q=0.1", code: http.StatusOK, ctype: api.ValueApplicationJson},
+		{name: "only text is not acceptable", accept: "text/plain", code: http.StatusNotAcceptable, ctype: api.ValueApplicationJson},
+	}
+
+	server := h.newGreetingServer()
+
+	for _, test := range tests {
+		h.Run(test.name, func() {
+			rec := h.get(server, test.accept)
+
+			h.Equal(test.code, rec.Code)
+			h.Equal(test.ctype, rec.Header().Get(api.HeaderContentType))
+
+			if test.code == http.StatusNotAcceptable {
+				h.Equal(errs.ErrTypeNotAcceptable, h.decodeError(rec).Type)
+			}
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestNegotiation_NothingAcceptableIsNotAcceptable() {
+	called := false
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		called = true
+		return greeting{"hello"}, 0
+	})
+
+	rec := h.serve(server, `{"name":"bruno"}`, "image/png")
+
+	h.Equal(http.StatusNotAcceptable, rec.Code)
+	h.Equal(errs.ErrTypeNotAcceptable, h.decodeError(rec).Type)
+	h.False(called, "the handler should not be invoked")
+}
+
+func (h *HandlerTestSuite) TestNegotiation_UnknownContentTypeIsUnsupported() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return greeting{"hello"}, 0
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("name: bruno"))
+	req.Header.Set(api.HeaderContentType, "application/toml")
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	h.Equal(http.StatusUnsupportedMediaType, rec.Code)
+	h.Equal(api.ValueApplicationJson, rec.Header().Get(api.HeaderContentType))
+}
+
+func (h *HandlerTestSuite) TestNegotiation_ContentTypeParametersAreIgnored() {
+	server := h.newServer(func(_ context.Context, _ *shared.ApplicationContext, data any) (any, int) {
+		return greeting{"hello " + data.(*testRequest).Name}, 0
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"name":"bruno"}`))
+	req.Header.Set(api.HeaderContentType, "application/json; charset=utf-8")
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.JSONEq(`{"message":"hello bruno"}`, rec.Body.String())
+}
+
+func (h *HandlerTestSuite) TestNegotiation_ResponseTypeFollowsStructuredContentType() {
+	type named struct {
+		Name string `json:"name" xml:"name" form:"name" validate:"required"`
+	}
+
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) { return nil, 0 })
+	server.DefineRequestHandler("/named", func(_ context.Context, _ *shared.ApplicationContext, data any) (any, int) {
+		return greeting{"hello " + data.(*named).Name}, 0
+	}, named{}, http.MethodPost)
+	server.DefineRequestHandler("/unnamed", func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return greeting{"hello"}, 0
+	}, nil, http.MethodPost)
+
+	tests := []struct {
+		name  string
+		path  string
+		body  string
+		ctype string
+		resp  string
+	}{
+		{name: "form posts get json", path: "/named", body: "name=bruno", ctype: api.ValueFormUrlEncoded, resp: api.ValueApplicationJson},
+		{name: "text posts get json", path: "/unnamed", body: "bruno", ctype: api.ValueTextPlain, resp: api.ValueApplicationJson},
+		{name: "xml posts get xml", path: "/named", body: "<named><name>bruno</name></named>", ctype: api.ValueApplicationXml, resp: api.ValueApplicationXml},
+	}
+
+	for _, test := range tests {
+		h.Run(test.name, func() {
+			req := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+			req.Header.Set(api.HeaderContentType, test.ctype)
+
+			rec := httptest.NewRecorder()
+			server.Api.Handler.ServeHTTP(rec, req)
+
+			h.Equal(http.StatusOK, rec.Code, rec.Body.String())
+			h.Equal(test.resp, rec.Header().Get(api.HeaderContentType))
+			h.Contains(rec.Body.String(), "hello")
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestNegotiation_RawContentWithoutPreferenceIsText() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return "pong", 0
+	})
+	server.DefineRequestHandler("/ping", func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return "pong", 0
+	}, nil, http.MethodGet)
+
+	rec := h.get(server, "", "/ping")
+
+	h.Equal(api.ValueTextPlain, rec.Header().Get(api.HeaderContentType))
+	h.Equal("pong", rec.Body.String())
+}
+
+func (h *HandlerTestSuite) newGreetingServer(options ...api.Option) *api.Server {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) { return nil, 0 }, options...)
+
+	server.DefineRequestHandler("/greeting", func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return greeting{"hello"}, 0
+	}, nil, http.MethodGet)
+
+	return server
+}
+
+func (h *HandlerTestSuite) get(server *api.Server, accept string, path ...string) *httptest.ResponseRecorder {
+	target := "/greeting"
+	if len(path) > 0 {
+		target = path[0]
+	}
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set(api.HeaderAccept, accept)
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// yamlish stands in for yaml.Marshal
+func yamlish(v any) ([]byte, error) {
+	buff, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	_ = json.Unmarshal(buff, &fields)
+
+	var out strings.Builder
+	for key, value := range fields {
+		fmt.Fprintf(&out, "%s: %v\n", key, value)
+	}
+
+	return []byte(out.String()), nil
+}
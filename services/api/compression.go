@@ -0,0 +1,403 @@
+package api
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gorilla/mux"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/shared"
+)
+
+// NewCompressor will return a Compressor for the content-coding that uses the provided
+// function to create its writers, i.e.
+//
+//	api.NewCompressor(api.EncodingGzip, func(w io.Writer) (io.WriteCloser, error) {
+//		return gzip.NewWriterLevel(w, gzip.BestSpeed)
+//	})
+func NewCompressor(encoding string, newWriter func(io.Writer) (io.WriteCloser, error)) Compressor {
+	return funcCompressor{encoding, newWriter}
+}
+
+// funcCompressor is a Compressor built from a function that creates its writers
+type funcCompressor struct {
+	encoding  string
+	newWriter func(io.Writer) (io.WriteCloser, error)
+}
+
+func (c funcCompressor) Encoding() string { return c.encoding }
+
+func (c funcCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) { return c.newWriter(w) }
+
+// DefaultCompressors returns the brotli, zstd, gzip and deflate Compressors, in that order
+// of preference
+func DefaultCompressors() []Compressor {
+	return []Compressor{
+		NewCompressor(EncodingBrotli, func(w io.Writer) (io.WriteCloser, error) {
+			return brotli.NewWriterLevel(w, brotli.DefaultCompression), nil
+		}),
+		NewCompressor(EncodingZstd, func(w io.Writer) (io.WriteCloser, error) {
+			// a response is compressed by the goroutine serving it, rather than by one per CPU...
+			return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		}),
+		NewCompressor(EncodingGzip, func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriterLevel(w, gzip.DefaultCompression)
+		}),
+		NewCompressor(EncodingDeflate, func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, flate.DefaultCompression)
+		}),
+	}
+}
+
+// CompressionConfig describes which responses are compressed, and how
+type CompressionConfig struct {
+	MinSize      int          // responses smaller than this are sent uncompressed, defaults to DefaultCompressionMinSize
+	ContentTypes []string     // the media types that are compressed (i.e. text/*), defaults to DefaultCompressibleTypes
+	Compressors  []Compressor // in order of preference, defaults to DefaultCompressors
+}
+
+// WithCompression will add a middleware function that compresses responses using the
+// content-coding negotiated from the Accept-Encoding header, i.e.
+//
+//	api.WithCompression(api.CompressionConfig{MinSize: 512})
+//
+// Only responses of a compressible media type, that reach the minimum size, are compressed
+// while gzip encoded request bodies are transparently decompressed (any other encoding being
+// refused with an errs.ErrTypeUnsupportedMediaType error).  The number of bytes passing in
+// to, and out of, each Compressor are counted by the 'compression_input_bytes_total' and
+// 'compression_output_bytes_total' metrics.
+func WithCompression(config CompressionConfig) Option {
+	return func(s *Server) {
+		WithRequestMiddleware(compressionMiddleware(s, config))(s)
+	}
+}
+
+func compressionMiddleware(s *Server, config CompressionConfig) mux.MiddlewareFunc {
+	if config.MinSize <= 0 {
+		config.MinSize = DefaultCompressionMinSize
+	}
+
+	if len(config.ContentTypes) == 0 {
+		config.ContentTypes = DefaultCompressibleTypes
+	}
+
+	if len(config.Compressors) == 0 {
+		config.Compressors = DefaultCompressors()
+	}
+
+	in := newDimensionedCounter(s.AppCtx, "compression_input_bytes_total", "encoding")
+	out := newDimensionedCounter(s.AppCtx, "compression_output_bytes_total", "encoding")
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := decompressRequest(r); err != nil {
+				returnMiddlewareError(s, &s.AppCtx, w, r, err, 0)
+				return
+			}
+
+			w.Header().Add(HeaderVary, HeaderAcceptEncoding)
+
+			compressor := negotiateEncoding(r.Header.Get(HeaderAcceptEncoding), config.Compressors)
+			if compressor == nil || r.Method == http.MethodHead {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressResponseWriter{writer: w, config: &config, compressor: compressor}
+
+			// should the handler panic the buffered response is abandoned...
+			h.ServeHTTP(cw, r)
+
+			if err := cw.Close(); err != nil {
+				s.Logger.WithCtx(r.Context()).Error("error compressing response", err)
+			}
+
+			if in != nil && cw.encoder != nil {
+				labels := append(shared.MetricFilterValues(s.AppCtx.RootCtx), compressor.Encoding())
+
+				in.WithLabelValues(labels...).Add(float64(cw.in))
+				out.WithLabelValues(labels...).Add(float64(cw.out))
+			}
+		})
+	}
+}
+
+// decompressRequest will replace the body of a gzip encoded request with one that
+// decompresses it
+func decompressRequest(r *http.Request) error {
+	switch strings.ToLower(strings.TrimSpace(r.Header.Get(HeaderContentEncoding))) {
+	case "", EncodingIdentity:
+		return nil
+	case EncodingGzip, "x-gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return errs.Wrap(err, errs.ErrTypeUnmarshal, "invalid gzip request body")
+		}
+
+		r.Body = gzipBody{gz, r.Body}
+		r.ContentLength = -1
+		r.Header.Del(HeaderContentEncoding)
+		r.Header.Del(HeaderContentLength)
+
+		return nil
+	default:
+		return errs.Errorf(errs.ErrTypeUnsupportedMediaType, "unsupported content encoding: %s", r.Header.Get(HeaderContentEncoding))
+	}
+}
+
+// gzipBody decompresses the request body, closing both when it is closed
+type gzipBody struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (b gzipBody) Close() error {
+	_ = b.Reader.Close()
+	return b.body.Close()
+}
+
+// negotiateEncoding returns the most preferred of the Compressors acceptable to the API
+// caller, or nil should the response not be compressed
+func negotiateEncoding(accept string, compressors []Compressor) Compressor {
+	if strings.TrimSpace(accept) == "" {
+		return nil
+	}
+
+	qualities := make(map[string]float64)
+
+	for _, entry := range strings.Split(accept, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+
+		qualities[strings.ToLower(strings.TrimSpace(coding))] = quality
+	}
+
+	var best Compressor
+	var bestQuality float64
+
+	for _, compressor := range compressors {
+		quality, exists := qualities[compressor.Encoding()]
+		if !exists {
+			quality = qualities["*"]
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = compressor, quality
+		}
+	}
+
+	return best
+}
+
+// compressResponseWriter buffers the response until it is known whether or not it should be
+// compressed... it is compressed once it reaches the minimum size (or is flushed) provided
+// that its media type is compressible and it has not already been encoded
+type compressResponseWriter struct {
+	writer     http.ResponseWriter
+	config     *CompressionConfig
+	compressor Compressor
+
+	status  int
+	buff    []byte
+	decided bool
+	encoder io.WriteCloser // set once the response is being compressed
+
+	in  int // bytes written to the encoder
+	out int // bytes written by the encoder
+}
+
+func (c *compressResponseWriter) Header() http.Header {
+	return c.writer.Header()
+}
+
+func (c *compressResponseWriter) WriteHeader(statusCode int) {
+	switch {
+	case c.decided:
+		c.writer.WriteHeader(statusCode)
+	case statusCode < http.StatusOK:
+		// informational responses are sent immediately...
+		c.writer.WriteHeader(statusCode)
+	case c.status == 0:
+		c.status = statusCode
+	}
+}
+
+func (c *compressResponseWriter) Write(data []byte) (int, error) {
+	if !c.decided {
+		if c.Header().Get(HeaderContentType) == "" {
+			c.Header().Set(HeaderContentType, http.DetectContentType(data))
+		}
+
+		if !c.compressible() {
+			if err := c.passthrough(); err != nil {
+				return 0, err
+			}
+		} else {
+			c.buff = append(c.buff, data...)
+
+			if len(c.buff) < c.config.MinSize {
+				return len(data), nil
+			}
+
+			return len(data), c.compress()
+		}
+	}
+
+	if c.encoder != nil {
+		c.in += len(data)
+		return c.encoder.Write(data)
+	}
+
+	return c.writer.Write(data)
+}
+
+// Close will send any response that is still buffered, or complete the compressed response
+func (c *compressResponseWriter) Close() error {
+	if !c.decided {
+		return c.passthrough()
+	}
+
+	if c.encoder != nil {
+		return c.encoder.Close()
+	}
+
+	return nil
+}
+
+// Flush will compress the response, regardless of its size, if it is compressible so that
+// streamed responses are compressed
+func (c *compressResponseWriter) Flush() {
+	if !c.decided {
+		var err error
+
+		if c.compressible() && (len(c.buff) > 0 || c.Header().Get(HeaderContentType) != "") {
+			err = c.compress()
+		} else {
+			err = c.passthrough()
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	if flusher, OK := c.encoder.(interface{ Flush() error }); OK {
+		_ = flusher.Flush()
+	}
+
+	if flusher, OK := c.writer.(http.Flusher); OK {
+		flusher.Flush()
+	}
+}
+
+func (c *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, OK := c.writer.(http.Hijacker); OK {
+		return hijacker.Hijack()
+	}
+
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap allows an http.ResponseController to reach the underlying http.ResponseWriter
+func (c *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return c.writer
+}
+
+// compressible determines whether or not the response is able to be compressed
+func (c *compressResponseWriter) compressible() bool {
+	if c.Header().Get(HeaderContentEncoding) != "" {
+		return false
+	}
+
+	switch c.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(c.Header().Get(HeaderContentType))
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range c.config.ContentTypes {
+		allowed = strings.ToLower(allowed)
+
+		if allowed == mediaType || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compress begins the compressed response, writing any buffered content to the encoder
+func (c *compressResponseWriter) compress() error {
+	c.decided = true
+
+	encoder, err := c.compressor.NewWriter(countingWriter{c.writer, &c.out})
+	if err != nil {
+		return c.passthrough()
+	}
+
+	c.Header().Set(HeaderContentEncoding, c.compressor.Encoding())
+	c.Header().Del(HeaderContentLength)
+	c.writeStatus()
+
+	c.encoder = encoder
+	c.in += len(c.buff)
+
+	_, err = encoder.Write(c.buff)
+	c.buff = nil
+
+	return err
+}
+
+// passthrough begins the uncompressed response, writing any buffered content as it is
+func (c *compressResponseWriter) passthrough() error {
+	c.decided = true
+	c.writeStatus()
+
+	if len(c.buff) == 0 {
+		return nil
+	}
+
+	_, err := c.writer.Write(c.buff)
+	c.buff = nil
+
+	return err
+}
+
+func (c *compressResponseWriter) writeStatus() {
+	if c.status != 0 {
+		c.writer.WriteHeader(c.status)
+	}
+}
+
+// countingWriter counts the bytes written to the underlying writer
+type countingWriter struct {
+	writer io.Writer
+	count  *int
+}
+
+func (c countingWriter) Write(data []byte) (int, error) {
+	n, err := c.writer.Write(data)
+	*c.count += n
+
+	return n, err
+}
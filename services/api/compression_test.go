@@ -0,0 +1,217 @@
+package api_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+var lengthyText = strings.Repeat("the quick brown fox jumps over the lazy dog ", 100)
+
+func (h *HandlerTestSuite) TestCompression_NegotiatedEncoding() {
+	server := h.newLengthyServer(api.WithCompression(api.CompressionConfig{}))
+
+	tests := []struct {
+		accept   string
+		encoding string
+	}{
+		{accept: "gzip", encoding: api.EncodingGzip},
+		{accept: "gzip, deflate, br", encoding: api.EncodingBrotli},
+		{accept: "br;q=0.5, deflate", encoding: api.EncodingDeflate},
+		{accept: "*", encoding: api.EncodingBrotli},
+		{accept: "*, br;q=0", encoding: api.EncodingZstd},
+		{accept: "gzip, zstd", encoding: api.EncodingZstd},
+		{accept: "identity", encoding: ""},
+		{accept: "", encoding: ""},
+	}
+
+	for _, test := range tests {
+		h.Run(test.accept, func() {
+			rec := h.getEncoded(server, "/lengthy", test.accept)
+
+			h.Equal(http.StatusOK, rec.Code)
+			h.Equal(test.encoding, rec.Header().Get(api.HeaderContentEncoding))
+			h.Contains(rec.Header().Values(api.HeaderVary), api.HeaderAcceptEncoding)
+			h.Contains(h.decompress(rec), lengthyText)
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestCompression_SmallResponsesAreNotCompressed() {
+	server := h.newLengthyServer(api.WithCompression(api.CompressionConfig{}))
+
+	rec := h.getEncoded(server, "/greeting", "gzip")
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.Empty(rec.Header().Get(api.HeaderContentEncoding))
+	h.JSONEq(`{"message":"hello"}`, rec.Body.String())
+}
+
+func (h *HandlerTestSuite) TestCompression_OnlyCompressibleTypes() {
+	server := h.newLengthyServer(api.WithCompression(api.CompressionConfig{ContentTypes: []string{"text/*"}}))
+
+	rec := h.getEncoded(server, "/lengthy", "gzip")
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.Empty(rec.Header().Get(api.HeaderContentEncoding))
+	h.Contains(rec.Body.String(), lengthyText)
+}
+
+func (h *HandlerTestSuite) TestCompression_GzipRequestBodiesAreDecompressed() {
+	var name string
+
+	server := h.newServer(func(_ context.Context, _ *shared.ApplicationContext, req any) (any, int) {
+		name = req.(*testRequest).Name
+		return nil, http.StatusNoContent
+	}, api.WithCompression(api.CompressionConfig{}))
+
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, _ = gz.Write([]byte(`{"name":"bruno"}`))
+	h.Require().NoError(gz.Close())
+
+	rec := h.postEncoded(server, body.Bytes(), api.EncodingGzip)
+
+	h.Equal(http.StatusNoContent, rec.Code)
+	h.Equal("bruno", name)
+
+	rec = h.postEncoded(server, []byte(`{"name":"bruno"}`), api.EncodingGzip)
+
+	h.Equal(http.StatusBadRequest, rec.Code)
+	h.Equal(errs.ErrTypeUnmarshal, h.decodeError(rec).Type)
+
+	rec = h.postEncoded(server, []byte(`{"name":"bruno"}`), api.EncodingBrotli)
+
+	h.Equal(http.StatusUnsupportedMediaType, rec.Code)
+	h.Equal(errs.ErrTypeUnsupportedMediaType, h.decodeError(rec).Type)
+}
+
+func (h *HandlerTestSuite) TestCompression_BytesAreCounted() {
+	collector, err := metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "test")
+	h.Require().NoError(err)
+
+	h.appctx.Collector = collector
+
+	server := h.newLengthyServer(api.WithRequestMiddleware(api.MetricsMiddleware(h.appctx)), api.WithCompression(api.CompressionConfig{}))
+
+	rec := h.getEncoded(server, "/lengthy", "gzip")
+	h.Equal(api.EncodingGzip, rec.Header().Get(api.HeaderContentEncoding))
+
+	scrape := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, api.MetricsPath, nil))
+
+	h.Contains(scrape.Body.String(), `test_compression_input_bytes_total{appName="",appVersion="",encoding="gzip",environ="",hostname=""}`)
+	h.Contains(scrape.Body.String(), `test_compression_output_bytes_total{appName="",appVersion="",encoding="gzip",environ="",hostname=""}`)
+	h.Contains(scrape.Body.String(), `test_response_bytes_total{appName="",appVersion="",environ="",hostname="",path="lengthy"} `+strconv.Itoa(rec.Body.Len()))
+}
+
+func (h *HandlerTestSuite) TestMetricsResponseWriter_FlushAndHijack() {
+	h.appctx.Collector, _ = metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "test")
+
+	var flushErr error
+
+	server := h.newGreetingServer(api.WithRequestMiddleware(api.MetricsMiddleware(h.appctx)), api.WithRequestMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Has("hijack") {
+				conn, rw, err := http.NewResponseController(w).Hijack()
+				h.Require().NoError(err)
+
+				_, _ = rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+				_ = rw.Flush()
+				_ = conn.Close()
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			flushErr = http.NewResponseController(w).Flush()
+		})
+	}))
+
+	rec := h.get(server, "")
+
+	h.NoError(flushErr)
+	h.True(rec.Flushed)
+
+	live := httptest.NewServer(server.Api.Handler)
+	defer live.Close()
+
+	resp, err := http.Get(live.URL + "/greeting?hijack")
+	h.Require().NoError(err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	h.Equal("hijacked", string(body))
+}
+
+func (h *HandlerTestSuite) newLengthyServer(options ...api.Option) *api.Server {
+	server := h.newGreetingServer(options...)
+
+	server.DefineRequestHandler("/lengthy", func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return map[string]string{"text": lengthyText}, 0
+	}, nil, http.MethodGet)
+
+	return server
+}
+
+func (h *HandlerTestSuite) getEncoded(server *api.Server, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set(api.HeaderAcceptEncoding, acceptEncoding)
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (h *HandlerTestSuite) postEncoded(server *api.Server, body []byte, contentEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewReader(body))
+	req.Header.Set(api.HeaderContentType, api.ValueApplicationJson)
+	req.Header.Set(api.HeaderContentEncoding, contentEncoding)
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+// decompress returns the body of the response, decoded according to its Content-Encoding
+func (h *HandlerTestSuite) decompress(rec *httptest.ResponseRecorder) string {
+	var reader io.Reader = rec.Body
+
+	switch rec.Header().Get(api.HeaderContentEncoding) {
+	case api.EncodingGzip:
+		gz, err := gzip.NewReader(rec.Body)
+		h.Require().NoError(err)
+		reader = gz
+	case api.EncodingDeflate:
+		reader = flate.NewReader(rec.Body)
+	case api.EncodingBrotli:
+		reader = brotli.NewReader(rec.Body)
+	case api.EncodingZstd:
+		zr, err := zstd.NewReader(rec.Body)
+		h.Require().NoError(err)
+		defer zr.Close()
+		reader = zr
+	}
+
+	decoded, err := io.ReadAll(reader)
+	h.Require().NoError(err)
+
+	return string(decoded)
+}
@@ -1,6 +1,38 @@
 package api
 
-import "time"
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds (in seconds) of the buckets used by
+// the response time histogram
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultCORSMethods and DefaultCORSHeaders are those permitted for cross-origin requests
+// when a CORSConfig does not specify its own
+var (
+	DefaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	DefaultCORSHeaders = []string{HeaderAccept, HeaderAuthorization, HeaderContentType, HeaderRequestId}
+)
+
+// DefaultCompressibleTypes are the media types compressed when a CompressionConfig does
+// not specify its own
+var DefaultCompressibleTypes = []string{
+	"text/*", ValueApplicationJson, ValueApplicationXml, "application/javascript",
+	ValueProblemJson, ValueProblemXml, ValueYaml, "image/svg+xml",
+}
+
+// DefaultStreamTypes are the media types a stream handler may produce unless the route
+// specifies its own using RouteStreams
+var DefaultStreamTypes = []string{ValueEventStream, ValueNDJson}
+
+// DefaultCompressionMinSize is the number of bytes a response must reach before it is compressed
+const DefaultCompressionMinSize = 1024
+
+// DefaultWebSocketMaxMessageSize is the size (in bytes) of the largest message a WebSocket
+// handler will accept unless the route specifies its own using RouteMaxMessageSize
+const DefaultWebSocketMaxMessageSize = 1 << 20
 
 // nolint: unused
 const (
@@ -9,12 +41,34 @@ const (
 	DefaultWriteTimeout      = 15 * time.Second
 	DefaultIdleTimeout       = 15 * time.Second
 	DefaultShutdownTimeout   = 15 * time.Second
+	DefaultStreamHeartbeat   = 15 * time.Second
+
+	DefaultWebSocketPingInterval = 15 * time.Second
+	DefaultWebSocketCloseTimeout = 1 * time.Second
 
 	DefaultBindToAddress   = "0.0.0.0"
 	DefaultHttpBindToPort  = 8080
 	DefaultHttpsBindToPort = 8443
 )
 
+// nolint: unused
+const (
+	HealthPath    = "/health"
+	LivenessPath  = "/health/live"
+	ReadinessPath = "/health/ready"
+	MetricsPath   = "/metrics"
+
+	HealthCheckName = "api"
+
+	OpenAPIPath   = "/openapi.json"
+	SwaggerUIPath = "/docs"
+)
+
+// nolint: unused
+const (
+	OpenAPIVersion = "3.1.0"
+)
+
 // nolint: unused
 const (
 	BindToAddressEnvKey = "API_BIND_ADDRESS"
@@ -26,17 +80,76 @@ const (
 	ReadHeaderTimeoutEnvKey = "API_READHEADER_TIMEOUT"
 	WriteTimeoutEnvKey      = "API_WRITE_TIMEOUT"
 	IdleTimeoutEnvKey       = "API_IDLE_TIMEOUT"
+
+	// comma separated, CORS is only enabled if the allowed origins are provided
+	CORSAllowedOriginsEnvKey   = "API_CORS_ALLOWED_ORIGINS"
+	CORSAllowedMethodsEnvKey   = "API_CORS_ALLOWED_METHODS"
+	CORSAllowedHeadersEnvKey   = "API_CORS_ALLOWED_HEADERS"
+	CORSExposedHeadersEnvKey   = "API_CORS_EXPOSED_HEADERS"
+	CORSAllowCredentialsEnvKey = "API_CORS_ALLOW_CREDENTIALS"
+	CORSMaxAgeEnvKey           = "API_CORS_MAX_AGE"
 )
 
 // nolint: unused
 const (
+	HeaderAccept        = "Accept"
 	HeaderContentType   = "Content-Type"
 	HeaderContentLength = "Content-Length"
 	HeaderRequestId     = "X-Request-Id"
+	HeaderVary          = "Vary"
+	HeaderAuthorization = "Authorization"
+	HeaderOrigin        = "Origin"
+
+	HeaderAcceptEncoding  = "Accept-Encoding"
+	HeaderContentEncoding = "Content-Encoding"
+	HeaderCacheControl    = "Cache-Control"
+	HeaderLastEventID     = "Last-Event-ID"
+
+	HeaderAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	HeaderAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	HeaderAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	HeaderAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	HeaderAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	HeaderAccessControlMaxAge           = "Access-Control-Max-Age"
+	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
+	HeaderAccessControlRequestHeaders   = "Access-Control-Request-Headers"
 )
 
 // nolint: unused
 const (
 	ValueTextPlain       = "text/plain"
 	ValueApplicationJson = "application/json"
+	ValueApplicationXml  = "application/xml"
+	ValueTextXml         = "text/xml"
+	ValueProblemJson     = "application/problem+json"
+	ValueProblemXml      = "application/problem+xml"
+	ValueFormUrlEncoded  = "application/x-www-form-urlencoded"
+	ValueMultipartForm   = "multipart/form-data"
+	ValueEventStream     = "text/event-stream"
+	ValueNDJson          = "application/x-ndjson"
+
+	// media types for which a Codec may be registered using WithCodec
+	ValueYaml     = "application/yaml"
+	ValueMsgPack  = "application/msgpack"
+	ValueProtobuf = "application/x-protobuf"
+)
+
+// nolint: unused
+const (
+	EncodingGzip     = "gzip"
+	EncodingDeflate  = "deflate"
+	EncodingBrotli   = "br"
+	EncodingZstd     = "zstd"
+	EncodingIdentity = "identity"
+)
+
+// nolint: unused
+const (
+	TagPath   = "path"
+	TagQuery  = "query"
+	TagHeader = "header"
+	TagForm   = "form"
 )
+
+// DefaultMaxMultipartMemory is the number of bytes of a multipart form held in memory
+const DefaultMaxMultipartMemory = 32 << 20
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/health"
 	"github.com/djmarrerajr/common-lib/observability/tracing"
 	"github.com/djmarrerajr/common-lib/shared"
 	"github.com/djmarrerajr/common-lib/utils"
@@ -43,8 +47,12 @@ func NewHttpsServer(addr, port, cert, key string, options ...Option) (*Server, e
 // the values retrieved from the environment
 //
 // It will, by default, have the following endpoints available:
-// ... /health	- returns an HTTP-200
-// ... /metrics	- returns the full set of Prometheus metrics being collected
+// ... /health		- returns the results of all health checks
+// ... /health/live	- returns the results of the liveness checks
+// ... /health/ready	- returns the results of the readiness checks (which fail once the Server is draining)
+// ... /metrics		- returns the full set of Prometheus metrics being collected
+//
+// Each of the health endpoints returns an HTTP-503 should any critical check fail
 func NewServerFromEnv(env utils.Environ, appCtx shared.ApplicationContext, options ...Option) (*Server, error) {
 	logger := appCtx.Logger.Named("api")
 	newopt := []Option{WithLogger(logger)}
@@ -100,18 +108,48 @@ func NewServerFromEnv(env utils.Environ, appCtx shared.ApplicationContext, optio
 		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
 	}
 
+	cors, err := corsConfigFromEnv(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// serve the metrics held within the collector's registry (if we have one)
+	metricsHandler := promhttp.Handler()
+	if appCtx.Collector != nil {
+		metricsHandler = appCtx.Collector.Handler()
+	}
+
+	// use the application's health registry, if it has one, so that the checks
+	// registered by other components are reflected by our health endpoints
+	if appCtx.Health == nil {
+		appCtx.Health = health.NewRegistry(health.WithCollector(appCtx.Collector, shared.MetricFilterLabels(), shared.MetricFilterValues(appCtx.RootCtx)))
+	}
+
 	newopt = append(newopt,
+		WithApplicationContext(appCtx),
 		WithTimeoutDurationSecs(readTimeout, readHeaderTimeout, writeTimeout, idleTimeout),
-		WithRequestMiddleware(tracing.RequestTracing(appCtx, "/health", "/metrics")),
+		WithRequestMiddleware(tracing.RequestTracing(appCtx, HealthPath, LivenessPath, ReadinessPath, MetricsPath)),
 		WithRequestMiddleware(MetricsMiddleware(appCtx)),
-		WithRouteHandler("/health", defaultHealthCheckHandler),
-		WithRouteHandler("/metrics", http.HandlerFunc(promhttp.Handler().ServeHTTP)),
+		WithPanicRecovery(appCtx),
+		WithRouteHandler(HealthPath, appCtx.Health.Handler()),
+		WithRouteHandler(LivenessPath, appCtx.Health.LiveHandler()),
+		WithRouteHandler(ReadinessPath, appCtx.Health.ReadyHandler()),
+		WithRouteHandler(MetricsPath, metricsHandler.ServeHTTP),
 	)
 
+	if cors != nil {
+		newopt = append(newopt, WithCORS(*cors))
+	}
+
 	newopt = append(newopt, options...)
 
 	server := createServer(addr, fmt.Sprint(port), newopt...)
-	server.AppCtx = appCtx
+
+	appCtx.Health.Register(health.Check{
+		Name:     HealthCheckName,
+		Checker:  server.readinessCheck,
+		Critical: true,
+	})
 
 	return server, nil
 }
@@ -128,6 +166,11 @@ func createServer(addr, port string, options ...Option) *Server {
 			WriteTimeout:      DefaultWriteTimeout,
 			IdleTimeout:       DefaultIdleTimeout,
 		},
+		ErrorStatuses: DefaultErrorStatusMap(),
+		Codecs:        DefaultCodecRegistry(),
+		draining:      new(atomic.Bool),
+		sockets:       new(socketRegistry),
+		routes:        new(routeRegistry),
 	}
 
 	for _, option := range options {
@@ -136,3 +179,47 @@ func createServer(addr, port string, options ...Option) *Server {
 
 	return &server
 }
+
+// corsConfigFromEnv returns the CORSConfig described by the environment, or nil should
+// no allowed origins have been provided
+func corsConfigFromEnv(env utils.Environ) (*CORSConfig, error) {
+	list := func(key string) []string {
+		val, _ := env.Get(key)
+		if strings.TrimSpace(val) == "" {
+			return nil
+		}
+
+		values := strings.Split(val, ",")
+		for idx := range values {
+			values[idx] = strings.TrimSpace(values[idx])
+		}
+
+		return values
+	}
+
+	config := CORSConfig{
+		AllowedOrigins: list(CORSAllowedOriginsEnvKey),
+		AllowedMethods: list(CORSAllowedMethodsEnvKey),
+		AllowedHeaders: list(CORSAllowedHeadersEnvKey),
+		ExposedHeaders: list(CORSExposedHeadersEnvKey),
+	}
+
+	if len(config.AllowedOrigins) == 0 {
+		return nil, nil
+	}
+
+	credentials, _, err := env.GetBool(CORSAllowCredentialsEnvKey)
+	if err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
+	}
+
+	maxAge, _, err := env.GetInt(CORSMaxAgeEnvKey)
+	if err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
+	}
+
+	config.AllowCredentials = credentials
+	config.MaxAge = time.Duration(maxAge) * time.Second
+
+	return &config, nil
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CORSConfig describes the cross-origin requests permitted by the Server
+type CORSConfig struct {
+	AllowedOrigins   []string // exact origins, '*' or wildcard subdomains, i.e. https://*.example.com
+	AllowedMethods   []string // defaults to DefaultCORSMethods
+	AllowedHeaders   []string // defaults to DefaultCORSHeaders, '*' allows any header
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration // how long a preflight response may be cached, omitted if zero
+}
+
+// WithCORS will add a middleware function that applies the CORS headers to the responses
+// of requests from permitted origins, and answers their preflight requests, i.e.
+//
+//	api.WithCORS(api.CORSConfig{AllowedOrigins: []string{"https://*.example.com"}})
+//
+// Preflight requests are answered even for routes restricted to other methods (which would
+// otherwise be refused by the router with an HTTP-405).  This should be added before any
+// middleware, such as WithAuthentication, that would refuse a preflight request.
+func WithCORS(config CORSConfig) Option {
+	return func(s *Server) {
+		if s.Api.Handler == nil {
+			s.Api.Handler = mux.NewRouter()
+		}
+
+		policy := newCORSPolicy(config)
+		router := s.Api.Handler.(*mux.Router)
+
+		// the router does not invoke its middleware for a request whose method does not match...
+		notAllowed := router.MethodNotAllowedHandler
+		if notAllowed == nil {
+			notAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			})
+		}
+
+		router.MethodNotAllowedHandler = policy.handler(notAllowed)
+
+		WithRequestMiddleware(policy.handler)(s)
+	}
+}
+
+// corsPolicy is the CORSConfig prepared for the evaluation of requests
+type corsPolicy struct {
+	anyOrigin   bool
+	origins     map[string]struct{}
+	wildcards   []string // the suffix of each wildcard subdomain, i.e. .example.com
+	anyHeader   bool
+	headers     map[string]struct{}
+	methods     map[string]struct{}
+	allowed     string // the Access-Control-Allow-Methods header value
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+func newCORSPolicy(config CORSConfig) corsPolicy {
+	policy := corsPolicy{
+		origins:     make(map[string]struct{}),
+		headers:     make(map[string]struct{}),
+		methods:     make(map[string]struct{}),
+		exposed:     strings.Join(config.ExposedHeaders, ", "),
+		credentials: config.AllowCredentials,
+	}
+
+	for _, origin := range config.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+
+		switch {
+		case origin == "*":
+			policy.anyOrigin = true
+		case strings.Contains(origin, "://*."):
+			scheme, host, _ := strings.Cut(origin, "://*")
+			policy.wildcards = append(policy.wildcards, scheme+"://|"+host)
+		default:
+			policy.origins[origin] = struct{}{}
+		}
+	}
+
+	methods := config.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+
+	for _, method := range methods {
+		policy.methods[strings.ToUpper(method)] = struct{}{}
+	}
+
+	policy.allowed = strings.ToUpper(strings.Join(methods, ", "))
+
+	headers := config.AllowedHeaders
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+
+	for _, header := range headers {
+		if header == "*" {
+			policy.anyHeader = true
+		}
+		policy.headers[http.CanonicalHeaderKey(strings.TrimSpace(header))] = struct{}{}
+	}
+
+	if config.MaxAge > 0 {
+		policy.maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+
+	return policy
+}
+
+// handler answers preflight requests from permitted origins, or otherwise applies the
+// CORS headers before handing the request on
+func (p corsPolicy) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get(HeaderOrigin)
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add(HeaderVary, HeaderOrigin)
+
+		if isPreflight(r) {
+			w.Header().Add(HeaderVary, HeaderAccessControlRequestMethod)
+			w.Header().Add(HeaderVary, HeaderAccessControlRequestHeaders)
+
+			if p.permitsPreflight(origin, r) {
+				p.applyOrigin(w, origin)
+				w.Header().Set(HeaderAccessControlAllowMethods, p.allowed)
+
+				if requested := r.Header.Get(HeaderAccessControlRequestHeaders); requested != "" {
+					w.Header().Set(HeaderAccessControlAllowHeaders, requested)
+				}
+				if p.maxAge != "" {
+					w.Header().Set(HeaderAccessControlMaxAge, p.maxAge)
+				}
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if p.permitsOrigin(origin) {
+			p.applyOrigin(w, origin)
+
+			if p.exposed != "" {
+				w.Header().Set(HeaderAccessControlExposeHeaders, p.exposed)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// applyOrigin sets the Access-Control-Allow-Origin (and -Credentials) headers... the origin is
+// reflected, rather than '*', whenever credentials are allowed as browsers require
+func (p corsPolicy) applyOrigin(w http.ResponseWriter, origin string) {
+	if p.anyOrigin && !p.credentials {
+		w.Header().Set(HeaderAccessControlAllowOrigin, "*")
+	} else {
+		w.Header().Set(HeaderAccessControlAllowOrigin, origin)
+	}
+
+	if p.credentials {
+		w.Header().Set(HeaderAccessControlAllowCredentials, "true")
+	}
+}
+
+func (p corsPolicy) permitsOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+
+	if _, OK := p.origins[origin]; OK {
+		return true
+	}
+
+	for _, wildcard := range p.wildcards {
+		scheme, suffix, _ := strings.Cut(wildcard, "|")
+
+		// there must be at least one label in place of the '*'...
+		if host := strings.TrimPrefix(origin, scheme); host != origin && strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p corsPolicy) permitsPreflight(origin string, r *http.Request) bool {
+	if !p.permitsOrigin(origin) {
+		return false
+	}
+
+	if _, OK := p.methods[strings.ToUpper(r.Header.Get(HeaderAccessControlRequestMethod))]; !OK {
+		return false
+	}
+
+	if p.anyHeader {
+		return true
+	}
+
+	for _, header := range strings.Split(r.Header.Get(HeaderAccessControlRequestHeaders), ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+
+		if _, OK := p.headers[http.CanonicalHeaderKey(header)]; !OK {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isPreflight determines whether or not the request is a CORS preflight request
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get(HeaderOrigin) != "" && r.Header.Get(HeaderAccessControlRequestMethod) != ""
+}
@@ -0,0 +1,105 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+func (h *HandlerTestSuite) TestCORS_Preflight() {
+	server := h.newGreetingServer(api.WithCORS(api.CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"},
+		MaxAge:         10 * time.Minute,
+	}))
+
+	tests := []struct {
+		name    string
+		path    string
+		origin  string
+		method  string
+		headers string
+		allowed bool
+	}{
+		{name: "route restricted to another method", path: "/greeting", origin: "https://app.example.com", method: http.MethodGet, allowed: true},
+		{name: "permitted headers", path: "/test", origin: "https://app.example.com", method: http.MethodPost, headers: "content-type, authorization", allowed: true},
+		{name: "wildcard subdomain", path: "/test", origin: "https://shop.eu.example.org", method: http.MethodPost, allowed: true},
+		{name: "wildcard requires a subdomain", path: "/test", origin: "https://example.org", method: http.MethodPost},
+		{name: "unknown origin", path: "/test", origin: "https://evil.example.net", method: http.MethodPost},
+		{name: "method not permitted", path: "/test", origin: "https://app.example.com", method: "PURGE"},
+		{name: "header not permitted", path: "/test", origin: "https://app.example.com", method: http.MethodPost, headers: "X-Secret"},
+	}
+
+	for _, test := range tests {
+		h.Run(test.name, func() {
+			req := httptest.NewRequest(http.MethodOptions, test.path, nil)
+			req.Header.Set(api.HeaderOrigin, test.origin)
+			req.Header.Set(api.HeaderAccessControlRequestMethod, test.method)
+			if test.headers != "" {
+				req.Header.Set(api.HeaderAccessControlRequestHeaders, test.headers)
+			}
+
+			rec := httptest.NewRecorder()
+			server.Api.Handler.ServeHTTP(rec, req)
+
+			h.Equal(http.StatusNoContent, rec.Code)
+
+			if !test.allowed {
+				h.Empty(rec.Header().Get(api.HeaderAccessControlAllowOrigin))
+				return
+			}
+
+			h.Equal(test.origin, rec.Header().Get(api.HeaderAccessControlAllowOrigin))
+			h.Contains(rec.Header().Get(api.HeaderAccessControlAllowMethods), test.method)
+			h.Equal(test.headers, rec.Header().Get(api.HeaderAccessControlAllowHeaders))
+			h.Equal("600", rec.Header().Get(api.HeaderAccessControlMaxAge))
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestCORS_ActualRequests() {
+	server := h.newGreetingServer(api.WithCORS(api.CORSConfig{
+		AllowedOrigins: []string{"*"},
+		ExposedHeaders: []string{api.HeaderRequestId},
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/greeting", nil)
+	req.Header.Set(api.HeaderOrigin, "https://anywhere.example.com")
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.Equal("*", rec.Header().Get(api.HeaderAccessControlAllowOrigin))
+	h.Equal(api.HeaderRequestId, rec.Header().Get(api.HeaderAccessControlExposeHeaders))
+	h.Contains(rec.Header().Values(api.HeaderVary), api.HeaderOrigin)
+
+	// an OPTIONS request that is not a preflight is still refused...
+	rec = httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/greeting", nil))
+
+	h.Equal(http.StatusMethodNotAllowed, rec.Code)
+}
+
+func (s *ApiTestSuite) TestConstructor_NewServerFromEnv_CORS() {
+	var err error
+
+	s.server, err = api.NewServerFromEnv(utils.NewEnviron(map[string]string{
+		api.CORSAllowedOriginsEnvKey:   "https://app.example.com",
+		api.CORSAllowCredentialsEnvKey: "true",
+	}), s.appctx)
+	s.Require().NoError(err)
+
+	req := httptest.NewRequest(http.MethodOptions, api.MetricsPath, nil)
+	req.Header.Set(api.HeaderOrigin, "https://app.example.com")
+	req.Header.Set(api.HeaderAccessControlRequestMethod, http.MethodGet)
+
+	rec := httptest.NewRecorder()
+	s.server.Api.Handler.ServeHTTP(rec, req)
+
+	s.Equal(http.StatusNoContent, rec.Code)
+	s.Equal("https://app.example.com", rec.Header().Get(api.HeaderAccessControlAllowOrigin))
+	s.Equal("true", rec.Header().Get(api.HeaderAccessControlAllowCredentials))
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/djmarrerajr/common-lib/errs"
+)
+
+// defaultErrorStatuses is the baseline mapping of errs.ErrorType to the HTTP
+// status that will be returned to the API caller
+var defaultErrorStatuses = ErrorStatusMap{
+	errs.ErrTypeUnknown:        http.StatusInternalServerError,
+	errs.ErrTypeConfiguration:  http.StatusInternalServerError,
+	errs.ErrTypeUnmarshal:      http.StatusBadRequest,
+	errs.ErrTypeMarshal:        http.StatusInternalServerError,
+	errs.ErrTypeValidation:     http.StatusUnprocessableEntity,
+	errs.ErrTypeInvalidNumber:  http.StatusBadRequest,
+	errs.ErrTypeInvalidBoolean: http.StatusBadRequest,
+	errs.ErrTypeTimeout:        http.StatusRequestTimeout,
+	errs.ErrTypeNotFound:       http.StatusNotFound,
+	errs.ErrTypeDatabase:       http.StatusInternalServerError,
+	errs.ErrTypeInternal:       http.StatusInternalServerError,
+	errs.ErrTypeAuthentication: http.StatusUnauthorized,
+	errs.ErrTypeAuthorization:  http.StatusForbidden,
+	errs.ErrTypeRateLimited:    http.StatusTooManyRequests,
+	errs.ErrTypeUnavailable:    http.StatusServiceUnavailable,
+	errs.ErrTypeConflict:       http.StatusConflict,
+
+	errs.ErrTypeNotAcceptable:        http.StatusNotAcceptable,
+	errs.ErrTypeUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	errs.ErrTypeRequestTooLarge:      http.StatusRequestEntityTooLarge,
+}
+
+// ErrorStatusMap is a registry that maps an errs.ErrorType to the HTTP status
+// code that should be returned when an error of that type is encountered
+type ErrorStatusMap map[errs.ErrorType]int
+
+// DefaultErrorStatusMap returns a copy of the default ErrorType->status mapping
+// that can be extended/modified without affecting other Servers
+func DefaultErrorStatusMap() ErrorStatusMap {
+	statuses := make(ErrorStatusMap, len(defaultErrorStatuses))
+	for errType, status := range defaultErrorStatuses {
+		statuses[errType] = status
+	}
+
+	return statuses
+}
+
+// StatusFor will return the HTTP status code associated with the type of the
+// provided error... any type that has not been mapped results in an HTTP-500
+func (m ErrorStatusMap) StatusFor(err error) int {
+	if status, exists := m[errs.GetType(err)]; exists {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
@@ -1,15 +1,16 @@
 package api
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
-	"encoding/xml"
-	"io"
+	"errors"
 	"net/http"
 	"reflect"
 	"runtime"
+	"time"
 
 	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
 	"github.com/djmarrerajr/common-lib/observability/tracing"
 	"github.com/djmarrerajr/common-lib/shared"
 	"github.com/djmarrerajr/common-lib/utils"
@@ -29,14 +30,27 @@ type ContextualHandler struct {
 	*shared.ApplicationContext
 
 	CustomHandlerFunc shared.RequestHandlerFunc
+	ErrorStatuses     ErrorStatusMap
+	Codecs            *CodecRegistry
+	problems          problemConfig
+	config            RouteConfig
+	streamFunc        StreamHandlerFunc    // set, in place of the CustomHandlerFunc, for a stream handler
+	socketFunc        WebSocketHandlerFunc // set, in place of the CustomHandlerFunc, for a WebSocket handler
+	sockets           *socketRegistry
+	socketCollectors  *socketCollectors
+	denials           *metrics.DimensionedCounter // counts the refusals of a route that requires authorization
 	any
 }
 
 // ServeHTTP is central to the operation of our API, it will:
 //
 //	... retrieve the content-type header value
+//	... apply the route's write timeout and body size limit, if it has them
+//	... negotiate the content-type of the response from the accept header value
+//	... authorize the API caller against the route's required scopes, roles and policies
 //	...	create a span that can be used to trace the request
-//	... transform the incoming body in to a domain object
+//	... upgrade the connection to a WebSocket, for a WebSocket handler
+//	... transform the incoming request in to a domain object
 //	... optionally validate the domain object
 //	... invoke the domain logic
 //	... transform the domain response
@@ -47,48 +61,105 @@ func (h ContextualHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// get the incoming content-type as it drives our behavior...
 	ctype := r.Header.Get(HeaderContentType)
 
-	// set our outgoing content-type to match...
-	w.Header().Add(HeaderContentType, ctype)
-
 	// combine our app-wide content with the context of this request...
 	reqCtx := utils.AddMapToContext(r.Context(), utils.GetFieldMapFromContext(h.RootCtx))
 
 	// start our outer (parent) span for this request...
-	span, spanCtx := tracing.StartChildSpan(reqCtx, runtime.FuncForPC(reflect.ValueOf(h.CustomHandlerFunc).Pointer()).Name())
+	span, spanCtx := tracing.StartChildSpan(reqCtx, h.name())
 	defer tracing.FinishChildSpan(span)
 
-	// turn our request body in to something more useful...
-	data, err := h.unmarshalRequest(ctype, r.Body)
-	if err != nil {
-		h.returnErrorResponse(w, reqCtx, ctype, errs.WithType(err, errs.ErrTypeUnmarshal))
+	// a WebSocket handler, once upgraded, has the connection to itself...
+	if h.socketFunc != nil {
+		h.serveWebSocket(w, r, reqCtx, spanCtx)
 		return
 	}
 
-	// if we *have* any data validate it - if we *have* a validator
-	if data != nil {
-		if h.ApplicationContext.Validator != nil {
-			err = h.ApplicationContext.Validator.Struct(data)
-			if err != nil {
-				h.returnErrorResponse(w, reqCtx, ctype, errs.WithType(err, errs.ErrTypeValidation))
-				return
-			}
-		}
+	h.applyWriteTimeout(reqCtx, w)
+	h.limitBody(w, r)
+
+	// a stream handler negotiates, and sends, its response differently...
+	if h.streamFunc != nil {
+		h.serveStream(w, r, reqCtx, spanCtx, ctype)
+		return
+	}
+
+	// determine how our response will be encoded *before* we do any work...
+	w.Header().Add(HeaderVary, HeaderAccept)
+
+	// (the request's media type being preferred only should it be able to encode any response)
+	preferred := []string{ValueApplicationJson}
+	if requested, OK := h.codecs().Lookup(ctype); OK && isStructured(requested) {
+		preferred = []string{ctype, ValueApplicationJson}
+	}
+
+	codec, OK := h.codecs().Negotiate(r.Header.Get(HeaderAccept), preferred...)
+	if !OK {
+		err = errs.Errorf(errs.ErrTypeNotAcceptable, "unable to produce any of: %s", r.Header.Get(HeaderAccept))
+		h.returnErrorResponse(w, r, reqCtx, defaultCodec, err, 0)
+		return
+	}
+
+	// authorize the caller, then unmarshal and validate the request...
+	data, err := h.requestData(reqCtx, ctype, r)
+	if err != nil {
+		h.returnErrorResponse(w, r, reqCtx, codec, err, 0)
+		return
 	}
 
 	var resp any
 	var status int
-	var buff []byte
 
-	// invoke our business logic/handler...
-	resp, status = h.CustomHandlerFunc(spanCtx, h.ApplicationContext, data)
+	// invoke our business logic/handler, within the route's deadline if it has one...
+	handlerCtx := spanCtx
+	if h.config.Timeout > 0 {
+		var cancel context.CancelFunc
 
-	// turn our response in to something more interesting...
-	buff, err = h.marshalRequest(ctype, resp)
-	if err != nil {
-		h.returnErrorResponse(w, reqCtx, ctype, errs.WithType(err, errs.ErrTypeMarshal))
+		handlerCtx, cancel = context.WithTimeout(spanCtx, h.config.Timeout)
+		defer cancel()
+	}
+
+	resp, status = h.CustomHandlerFunc(handlerCtx, h.ApplicationContext, data)
+
+	// a handler that gave up once its deadline had passed has timed out, whereas one that
+	// completed regardless keeps its response...
+	if err, isErr := resp.(error); isErr && h.config.Timeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+		resp, status = errs.Wrapf(err, errs.ErrTypeTimeout, "request did not complete within %s", h.config.Timeout), 0
+	}
+
+	// the handler may have returned an error in place of a domain response...
+	if err, isErr := resp.(error); isErr {
+		h.returnErrorResponse(w, r, reqCtx, codec, errs.WithTypeFallback(err, errs.ErrTypeUnknown), status)
 		return
 	}
 
+	// raw content is returned as-is to a caller that has expressed no preference...
+	if isRawContent(resp) && ctype == "" && isUnspecified(r.Header.Get(HeaderAccept)) {
+		codec = textCodec{}
+	}
+
+	// while a response the negotiated Codec cannot encode is encoded by the next acceptable...
+	if resp != nil && !canEncode(codec, resp) {
+		codec, OK = h.codecs().negotiate(r.Header.Get(HeaderAccept), func(c Codec) bool { return canEncode(c, resp) }, preferred...)
+		if !OK {
+			err = errs.Errorf(errs.ErrTypeNotAcceptable, "unable to produce any of: %s", r.Header.Get(HeaderAccept))
+			h.returnErrorResponse(w, r, reqCtx, defaultCodec, err, 0)
+			return
+		}
+	}
+
+	// turn our response in to something more interesting...
+	var buff bytes.Buffer
+
+	if resp != nil {
+		err = codec.Encode(&buff, resp)
+		if err != nil {
+			h.returnErrorResponse(w, r, reqCtx, codec, errs.WithType(err, errs.ErrTypeMarshal), 0)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, codec.MediaType())
+	}
+
 	// ensure we return a valid status...
 	if status == 0 {
 		status = http.StatusOK
@@ -97,114 +168,207 @@ func (h ContextualHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(status)
 
 	// send our response...
-	_, err = w.Write(buff)
+	_, err = w.Write(buff.Bytes())
 	if err != nil {
 		h.Logger.WithCtx(reqCtx).Error("error writing response", err)
 	}
 }
 
-// unmarshalRequest will, based on the incoming content-type, transform the incoming
-// request body in to a pointer object that can be cast to the correct type by the receiver
-func (h ContextualHandler) unmarshalRequest(ctype string, body io.ReadCloser) (any, error) {
+// requestData will ensure the caller is permitted to make the request before turning the
+// request (body, path, query and headers) in to a domain object which, if we *have* a
+// validator, is then validated
+func (h ContextualHandler) requestData(reqCtx context.Context, ctype string, r *http.Request) (any, error) {
+	if err := h.authorize(reqCtx, r); err != nil {
+		return nil, err
+	}
+
+	// a body that is known to be too large need not be read...
+	if h.config.MaxBodySize > 0 && r.ContentLength > h.config.MaxBodySize {
+		return nil, errRequestTooLarge(h.config.MaxBodySize)
+	}
+
+	data, err := h.unmarshalRequest(ctype, r)
+	if err != nil {
+		return nil, errs.WithTypeFallback(err, errs.ErrTypeUnmarshal)
+	}
+
+	if data != nil && h.ApplicationContext.Validator != nil {
+		if err = h.ApplicationContext.Validator.Struct(data); err != nil {
+			return nil, errs.WithType(err, errs.ErrTypeValidation)
+		}
+	}
+
+	return data, nil
+}
+
+// applyWriteTimeout will replace the Server's write timeout with that of the route... a
+// stream handler, being long-lived, has no write timeout unless the route is given one
+func (h ContextualHandler) applyWriteTimeout(reqCtx context.Context, w http.ResponseWriter) {
+	var deadline time.Time
+
+	switch {
+	case h.config.WriteTimeout > 0:
+		deadline = time.Now().Add(h.config.WriteTimeout)
+	case h.streamFunc == nil:
+		return
+	}
+
+	err := http.NewResponseController(w).SetWriteDeadline(deadline)
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.Logger.WithCtx(reqCtx).Error("unable to apply write timeout", err)
+	}
+}
+
+// limitBody will, should the route have a body size limit, prevent any more than that
+// being read from the request body
+func (h ContextualHandler) limitBody(w http.ResponseWriter, r *http.Request) {
+	if h.config.MaxBodySize > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, h.config.MaxBodySize)
+	}
+}
+
+// name returns the name of the domain handler function, by which its span is known
+func (h ContextualHandler) name() string {
+	var fn any = h.CustomHandlerFunc
+
+	switch {
+	case h.streamFunc != nil:
+		fn = h.streamFunc
+	case h.socketFunc != nil:
+		fn = h.socketFunc
+	}
+
+	return runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+}
+
+// unmarshalRequest will, using the Codec registered for the incoming content-type, transform
+// the incoming request body in to a pointer object that can be cast to the correct type by
+// the receiver
+//
+// Any fields tagged with 'path', 'query', 'header' or 'form' are then bound from the
+// corresponding part of the request (see bindRequest)
+func (h ContextualHandler) unmarshalRequest(ctype string, r *http.Request) (any, error) {
 	if h.any != nil {
 		data := reflect.New(reflect.TypeOf(h.any)).Interface()
 
-		switch ctype {
-		case "application/json":
-			err := json.NewDecoder(body).Decode(data)
-			if err != nil {
-				return nil, err
+		if ctype != "" && r.ContentLength != 0 {
+			codec, OK := h.codecs().Lookup(ctype)
+			if !OK {
+				return nil, errs.Errorf(errs.ErrTypeUnsupportedMediaType, "unsupported content type: %s", ctype)
 			}
-		case "text/xml", "application/xml":
-			err := xml.NewDecoder(body).Decode(data)
+
+			decode := codec.Decode
+			if h.config.StrictJson && isJson(codec.MediaType()) {
+				decode = decodeStrictJson
+			}
+
+			err := decode(r, data)
 			if err != nil {
-				return nil, err
+				if isRequestTooLarge(err) {
+					return nil, errRequestTooLarge(h.config.MaxBodySize)
+				}
+
+				return nil, errs.WithType(err, errs.ErrTypeUnmarshal)
+			}
+		}
+
+		err := bindRequest(r, data)
+		if err != nil {
+			if isRequestTooLarge(err) {
+				return nil, errRequestTooLarge(h.config.MaxBodySize)
 			}
+
+			return nil, err
 		}
+
 		return data, nil
 	}
 
 	return nil, nil
 }
 
-// marshalRequest will, based on the incoming content-type, transform the outgoing
-// domain object to an http response
-func (h ContextualHandler) marshalRequest(ctype string, body any) ([]byte, error) {
-	var buff []byte
-	var err error
+// errRequestTooLarge returns the error with which a request body exceeding the limit is refused
+func errRequestTooLarge(limit int64) error {
+	return errs.Errorf(errs.ErrTypeRequestTooLarge, "request body exceeds %d bytes", limit)
+}
 
-	if body != nil {
-		switch ctype {
-		case "application/json":
-			buff, err = json.Marshal(body)
-			if err != nil {
-				return nil, err
-			}
-		case "text/xml", "application/xml":
-			buff, err = xml.Marshal(body)
-			if err != nil {
-				return nil, err
-			}
-		default:
-			switch body := body.(type) {
-			case string:
-				buff = []byte(body)
-			case []byte:
-				buff = body
-			default:
-				if reflect.TypeOf(body).Kind() == reflect.Struct {
-					buff, err = json.Marshal(body)
-					if err != nil {
-						return nil, err
-					}
-				} else {
-					return nil, errs.New(errs.ErrTypeMarshal, "unsupport type")
-				}
-			}
-		}
+// isRequestTooLarge reports whether the error is that of a request body having exceeded
+// the route's body size limit
+func isRequestTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
 
-		return buff, nil
+	return errors.As(err, &maxBytesErr)
+}
+
+// codecs returns the Server's CodecRegistry or, if the handler was constructed without
+// one, the default registry
+func (h ContextualHandler) codecs() *CodecRegistry {
+	if h.Codecs == nil {
+		return DefaultCodecRegistry()
 	}
 
-	return nil, nil
+	return h.Codecs
 }
 
 // returnErrorResponse will, as the name states, return a standardized error to the API caller
-func (h ContextualHandler) returnErrorResponse(w http.ResponseWriter, reqCtx context.Context, ctype string, err error) {
-	var buff []byte
+//
+// The HTTP status is derived from the type of the error unless the domain handler has
+// explicitly provided an error status (>= 400) of its own.  The error will be returned
+// as RFC 7807 problem details if the server has been configured to do so, or if the API
+// caller has asked for them, otherwise an ErrorResponse is returned.
+func (h ContextualHandler) returnErrorResponse(w http.ResponseWriter, r *http.Request, reqCtx context.Context, codec Codec, err error, status int) {
+	var buff bytes.Buffer
 
 	h.Logger.WithCtx(reqCtx).Error("error processing request", err)
 
 	reqID, _ := utils.GetFieldValueFromContext[string](reqCtx, shared.RequestIdContextKey)
 
-	w.(*metricsResponseWriter).errorType = errs.GetType(err)
+	errType := errs.GetType(err)
+	if mw, OK := findMetricsWriter(w); OK {
+		mw.errorType = errType
+	}
 
-	resp := ErrorResponse{
-		RequestId:   reqID,
-		Type:        w.(*metricsResponseWriter).errorType,
-		Code:        http.StatusBadRequest,
-		Description: err.Error(),
+	if status < http.StatusBadRequest {
+		status = h.ErrorStatuses.StatusFor(err)
+	}
+
+	if mediaType, OK := h.problems.wantsProblemDetails(r, codec.MediaType()); OK {
+		problem := NewProblemDetails(err, status, r.URL.Path, reqID, h.problems.typeBaseURI)
+
+		var encoded []byte
+
+		encoded, err = marshalProblem(mediaType, problem)
+		buff.Write(encoded)
+		w.Header().Set(HeaderContentType, mediaType)
+	} else {
+		resp := ErrorResponse{
+			RequestId:   reqID,
+			Type:        errType,
+			Code:        status,
+			Description: err.Error(),
+		}
+
+		// not every codec (i.e. text/plain) is able to encode an ErrorResponse...
+		err = codec.Encode(&buff, resp)
+		if err != nil {
+			codec = defaultCodec
+			buff.Reset()
+
+			err = codec.Encode(&buff, resp)
+		}
+
+		w.Header().Set(HeaderContentType, codec.MediaType())
 	}
 
-	buff, err = h.marshalRequest(ctype, resp)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 
-	_, err = w.Write(buff)
+	_, err = w.Write(buff.Bytes())
 	if err != nil {
 		h.Logger.WithCtx(reqCtx).Error("error writing response", err)
 	}
 }
-
-// defaultHealthCheckHandler will respond with a simple HTTP-200
-//
-//	... this should be probably replaced with something with more
-//		insight in to the applications health as it will cause the
-//		application to always 'appear' healthy
-func defaultHealthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-}
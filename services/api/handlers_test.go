@@ -0,0 +1,344 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+type testRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type HandlerTestSuite struct {
+	suite.Suite
+
+	appctx shared.ApplicationContext
+}
+
+func (h *HandlerTestSuite) SetupTest() {
+	h.appctx = shared.ApplicationContext{
+		RootCtx:   context.Background(),
+		Logger:    utils.NewLogger("INFO"),
+		Validator: validator.New(),
+	}
+}
+
+func (h *HandlerTestSuite) TestErrorResponse_StatusDerivedFromErrorType() {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "validation error", err: errs.New(errs.ErrTypeValidation, "invalid"), status: http.StatusUnprocessableEntity},
+		{name: "unmarshal error", err: errs.New(errs.ErrTypeUnmarshal, "garbled"), status: http.StatusBadRequest},
+		{name: "untyped error", err: fmt.Errorf("boom"), status: http.StatusInternalServerError},
+		{name: "unmapped error type", err: errs.New("SomethingElse", "huh"), status: http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		h.Run(test.name, func() {
+			server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+				return test.err, 0
+			})
+
+			rec := h.serve(server, `{"name":"bruno"}`)
+
+			h.Equal(test.status, rec.Code)
+			h.Equal(test.status, h.decodeError(rec).Code)
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestErrorResponse_ExplicitHandlerStatusIsHonored() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return errs.New(errs.ErrTypeValidation, "duplicate"), http.StatusConflict
+	})
+
+	rec := h.serve(server, `{"name":"bruno"}`)
+
+	h.Equal(http.StatusConflict, rec.Code)
+}
+
+func (h *HandlerTestSuite) TestErrorResponse_CustomStatusMapping() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return errs.New("Teapot", "short and stout"), 0
+	}, api.WithErrorStatus("Teapot", http.StatusTeapot))
+
+	rec := h.serve(server, `{"name":"bruno"}`)
+
+	h.Equal(http.StatusTeapot, rec.Code)
+	h.Equal(errs.ErrorType("Teapot"), h.decodeError(rec).Type)
+}
+
+func (h *HandlerTestSuite) TestErrorResponse_MalformedBodyIsBadRequest() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return nil, http.StatusOK
+	})
+
+	rec := h.serve(server, `{"name":`)
+
+	h.Equal(http.StatusBadRequest, rec.Code)
+	h.Equal(errs.ErrTypeUnmarshal, h.decodeError(rec).Type)
+}
+
+func (h *HandlerTestSuite) TestProblemDetails_ReturnedWhenRequested() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return errs.New(errs.ErrTypeUnknown, "boom"), 0
+	})
+
+	rec := h.serve(server, `{"name":"bruno"}`, api.ValueProblemJson)
+
+	h.Equal(http.StatusInternalServerError, rec.Code)
+	h.Equal(api.ValueProblemJson, rec.Header().Get(api.HeaderContentType))
+
+	problem := h.decodeProblem(rec)
+	h.Equal("about:blank", problem.Type)
+	h.Equal(http.StatusText(http.StatusInternalServerError), problem.Title)
+	h.Equal(http.StatusInternalServerError, problem.Status)
+	h.Equal("boom", problem.Detail)
+	h.Equal("/test", problem.Instance)
+}
+
+func (h *HandlerTestSuite) TestProblemDetails_AcceptQualitiesAreHonored() {
+	tests := []struct {
+		name    string
+		enabled bool
+		accept  string
+		ctype   string
+	}{
+		{name: "excluded problem json", accept: "application/problem+json;q=0, application/json", ctype: api.ValueApplicationJson},
+		{name: "excluded although enabled", enabled: true, accept: "application/problem+json;q=0, application/json", ctype: api.ValueApplicationJson},
+		{name: "preferred problem xml", accept: "application/problem+json;q=0.5, application/problem+xml", ctype: api.ValueProblemXml},
+		{name: "preferred problem json", accept: "application/problem+xml;q=0.5, application/problem+json", ctype: api.ValueProblemJson},
+		{name: "wildcards do not ask for problems", accept: "application/*", ctype: api.ValueApplicationJson},
+	}
+
+	for _, test := range tests {
+		h.Run(test.name, func() {
+			options := []api.Option{}
+			if test.enabled {
+				options = append(options, api.WithProblemDetails(""))
+			}
+
+			server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+				return errs.New(errs.ErrTypeUnknown, "boom"), 0
+			}, options...)
+
+			rec := h.serve(server, `{"name":"bruno"}`, test.accept)
+
+			h.Equal(http.StatusInternalServerError, rec.Code)
+			h.Equal(test.ctype, rec.Header().Get(api.HeaderContentType))
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestProblemDetails_ValidationFailuresBecomeInvalidParams() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return nil, http.StatusOK
+	}, api.WithProblemDetails("https://errors.example.com/"))
+
+	rec := h.serve(server, `{}`)
+
+	h.Equal(http.StatusUnprocessableEntity, rec.Code)
+	h.Equal(api.ValueProblemJson, rec.Header().Get(api.HeaderContentType))
+
+	problem := h.decodeProblem(rec)
+	h.Equal("https://errors.example.com/validation", problem.Type)
+	h.Equal("Validation", problem.Title)
+	h.Equal([]api.InvalidParam{{Name: "Name", Reason: "failed on the 'required' validation"}}, problem.InvalidParams)
+}
+
+func (h *HandlerTestSuite) TestProblemDetails_XmlRequestReceivesProblemXml() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return errs.New(errs.ErrTypeValidation, "nope"), 0
+	}, api.WithProblemDetails(""))
+
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`<testRequest><Name>bruno</Name></testRequest>`))
+	req.Header.Set(api.HeaderContentType, api.ValueApplicationXml)
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	var problem api.ProblemDetails
+
+	h.Equal(api.ValueProblemXml, rec.Header().Get(api.HeaderContentType))
+	h.Require().NoError(xml.Unmarshal(rec.Body.Bytes(), &problem))
+	h.Equal(http.StatusUnprocessableEntity, problem.Status)
+	h.Equal("urn:ietf:rfc:7807", problem.XMLName.Space)
+}
+
+func (h *HandlerTestSuite) TestRouteTimeout_OverrunningHandlerTimesOut() {
+	h.appctx.Collector, _ = metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "test")
+
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) { return nil, 0 },
+		api.WithRequestMiddleware(api.MetricsMiddleware(h.appctx)))
+
+	server.DefineRequestHandlerWithOptions("/slow", func(ctx context.Context, _ *shared.ApplicationContext, _ any) (any, int) {
+		<-ctx.Done()
+		return ctx.Err(), 0
+	}, nil, api.RouteMethods(http.MethodGet), api.RouteTimeout(10*time.Millisecond))
+
+	rec := h.get(server, api.ValueApplicationJson, "/slow")
+
+	h.Equal(http.StatusRequestTimeout, rec.Code)
+	h.Equal(errs.ErrTypeTimeout, h.decodeError(rec).Type)
+
+	scrape := httptest.NewRecorder()
+	h.appctx.Collector.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	h.Contains(scrape.Body.String(), `test_request_timeouts_total{appName="",appVersion="",environ="",hostname="",path="slow"} 1`)
+}
+
+func (h *HandlerTestSuite) TestRouteTimeout_LateResponseIsKept() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) { return nil, 0 })
+
+	server.DefineRequestHandlerWithOptions("/late", func(ctx context.Context, _ *shared.ApplicationContext, _ any) (any, int) {
+		time.Sleep(20 * time.Millisecond)
+		return greeting{"hello"}, http.StatusOK
+	}, nil, api.RouteMethods(http.MethodGet), api.RouteTimeout(5*time.Millisecond))
+
+	rec := h.get(server, api.ValueApplicationJson, "/late")
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.JSONEq(`{"message":"hello"}`, rec.Body.String())
+}
+
+func (h *HandlerTestSuite) TestRouteMaxBodySize_LargeBodiesAreRefused() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) { return nil, 0 })
+	server.DefineRequestHandlerWithOptions("/test", func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return nil, http.StatusNoContent
+	}, testRequest{}, api.RouteMethods(http.MethodPost), api.RouteMaxBodySize(20))
+
+	tests := []struct {
+		name   string
+		body   string
+		length int64
+		status int
+	}{
+		{name: "within the limit", body: `{"name":"bruno"}`, status: http.StatusNoContent},
+		{name: "declared too large", body: `{"name":"bruno the great"}`, status: http.StatusRequestEntityTooLarge},
+		{name: "too large without a declared length", body: `{"name":"bruno the great"}`, length: -1, status: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, test := range tests {
+		h.Run(test.name, func() {
+			req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(test.body))
+			req.Header.Set(api.HeaderContentType, api.ValueApplicationJson)
+			if test.length != 0 {
+				req.ContentLength = test.length
+			}
+
+			rec := httptest.NewRecorder()
+			server.Api.Handler.ServeHTTP(rec, req)
+
+			h.Equal(test.status, rec.Code)
+			if test.status == http.StatusRequestEntityTooLarge {
+				h.Equal(errs.ErrTypeRequestTooLarge, h.decodeError(rec).Type)
+			}
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestRouteStrictJson_UnknownFieldsAreRefused() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) { return nil, 0 })
+	server.DefineRequestHandlerWithOptions("/strict", func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		return nil, http.StatusNoContent
+	}, testRequest{}, api.RouteMethods(http.MethodPost), api.RouteStrictJson())
+
+	for body, status := range map[string]int{
+		`{"name":"bruno"}`:                  http.StatusNoContent,
+		`{"name":"bruno","nickname":"b"}`:   http.StatusBadRequest,
+		`{"name":"bruno"} {"name":"again"}`: http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/strict", strings.NewReader(body))
+		req.Header.Set(api.HeaderContentType, api.ValueApplicationJson)
+
+		rec := httptest.NewRecorder()
+		server.Api.Handler.ServeHTTP(rec, req)
+
+		h.Equal(status, rec.Code, body)
+	}
+
+	// whereas routes that are not strict ignore unknown fields
+	h.Equal(http.StatusOK, h.serve(server, `{"name":"bruno","nickname":"b"}`).Code)
+}
+
+func (h *HandlerTestSuite) TestDefineRouteWithOptions_AppliesDeadlineAndBodyLimit() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) { return nil, 0 })
+	server.DefineRouteWithOptions("/raw", func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline := r.Context().Deadline()
+
+		if _, err := io.ReadAll(r.Body); err != nil || !hasDeadline {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}, api.RouteMethods(http.MethodPut), api.RouteTimeout(time.Second), api.RouteMaxBodySize(4))
+
+	for body, status := range map[string]int{"tiny": http.StatusNoContent, "too large": http.StatusRequestEntityTooLarge} {
+		rec := httptest.NewRecorder()
+		server.Api.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/raw", strings.NewReader(body)))
+
+		h.Equal(status, rec.Code, body)
+	}
+}
+
+func (h *HandlerTestSuite) newServer(handler shared.RequestHandlerFunc, options ...api.Option) *api.Server {
+	options = append([]api.Option{api.WithLogger(h.appctx.Logger), api.WithApplicationContext(h.appctx)}, options...)
+
+	server, err := api.NewHttpServer("127.0.0.1", "0", options...)
+	h.Require().NoError(err)
+
+	server.DefineRequestHandler("/test", handler, testRequest{}, http.MethodPost)
+
+	return server
+}
+
+func (h *HandlerTestSuite) serve(server *api.Server, body string, accept ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+	req.Header.Set(api.HeaderContentType, api.ValueApplicationJson)
+	req.Header.Set(api.HeaderAccept, strings.Join(accept, ","))
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (h *HandlerTestSuite) decodeError(rec *httptest.ResponseRecorder) api.ErrorResponse {
+	var resp api.ErrorResponse
+
+	h.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+
+	return resp
+}
+
+func (h *HandlerTestSuite) decodeProblem(rec *httptest.ResponseRecorder) api.ProblemDetails {
+	var problem api.ProblemDetails
+
+	h.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &problem))
+
+	return problem
+}
+
+func TestHandlers(t *testing.T) {
+	suite.Run(t, new(HandlerTestSuite))
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"reflect"
+
+	"github.com/gorilla/mux"
+
+	"github.com/djmarrerajr/common-lib/services/api/idempotency"
+	"github.com/djmarrerajr/common-lib/shared"
+)
+
+// WithIdempotency will add a middleware function that gives POST (and PATCH) requests which
+// carry an Idempotency-Key header 'at most once' semantics, i.e.
+//
+//	store, _ := idempotency.NewDatabaseStore(ctx, adapter)
+//	guard, _ := idempotency.NewGuardFromEnv(env, idempotency.WithStore(store))
+//	api.WithIdempotency(guard)
+//
+// The response to the first request with a key is stored, for the Guard's TTL, and replayed
+// (along with an Idempotent-Replayed header) to any later request with the same key, each
+// replay being counted by the 'idempotent_replays_total' metric.  A request made while the
+// first is in progress is refused with an errs.ErrTypeConflict error, unless the Guard waits
+// for it, and one that differs from the first is refused with an errs.ErrTypeValidation error.
+//
+// A response with a 5xx status is not stored so that the request may be retried, while a
+// request whose key cannot be reserved (i.e. the Store is unavailable) is refused rather than
+// risk it being processed twice.  As keys are scoped to the client (see idempotency.BySubject)
+// this should be added after WithAuthentication.
+func WithIdempotency(guard *idempotency.Guard, routesToSkip ...string) Option {
+	return func(s *Server) {
+		WithRequestMiddleware(idempotencyMiddleware(s, guard, routesToSkip...))(s)
+	}
+}
+
+func idempotencyMiddleware(s *Server, guard *idempotency.Guard, routesToSkip ...string) mux.MiddlewareFunc {
+	routes := newSkippedRoutes(routesToSkip...)
+
+	replayed := newDimensionedCounter(s.AppCtx, "idempotent_replays_total", "path")
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isSkipped(routes, r) || !guard.Applies(r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			// the request body is read before the route can limit it, so the route's limit is
+			// applied here as well as the Guard's own...
+			if rt, OK := s.routes.lookup(routeTemplate(r)); OK && rt.config.MaxBodySize > 0 && r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, rt.config.MaxBodySize)
+			}
+
+			key, replay, err := guard.Reserve(r)
+			if err != nil {
+				returnMiddlewareError(s, &s.AppCtx, w, r, err, 0)
+				return
+			}
+
+			if replay != nil {
+				if replayed != nil {
+					replayed.WithLabelValues(append(shared.MetricFilterValues(s.AppCtx.RootCtx), metricPath(r))...).Inc()
+				}
+
+				replayResponse(s, w, r, replay)
+				return
+			}
+
+			iw := &idempotentResponseWriter{writer: w, before: w.Header().Clone()}
+
+			// the key is released should the request fail (or panic) so that it may be retried,
+			// the Guard ensuring that it is not left behind should the request be cancelled...
+			stored := false
+			defer func() {
+				if !stored {
+					if err := guard.Release(r.Context(), key); err != nil {
+						s.Logger.WithCtx(r.Context()).Error("unable to release idempotency key", err)
+					}
+				}
+			}()
+
+			h.ServeHTTP(iw, r)
+
+			// a hijacked connection's response is unknown to us...
+			if iw.hijacked || iw.StatusCode() >= http.StatusInternalServerError {
+				return
+			}
+
+			stored = true
+
+			if err := guard.Complete(r.Context(), key, iw.response()); err != nil {
+				s.Logger.WithCtx(r.Context()).Error("unable to store idempotent response", err)
+			}
+		})
+	}
+}
+
+// replayResponse will write the stored response, as it was originally written, with the
+// addition of the Idempotent-Replayed header
+func replayResponse(s *Server, w http.ResponseWriter, r *http.Request, replay *idempotency.Response) {
+	for name, values := range replay.Header {
+		w.Header()[name] = values
+	}
+
+	w.Header().Set(idempotency.HeaderReplayed, "true")
+	w.WriteHeader(replay.Status)
+
+	if _, err := w.Write(replay.Body); err != nil {
+		s.Logger.WithCtx(r.Context()).Error("error writing response", err)
+	}
+}
+
+// idempotentResponseWriter records the response written by the handler so that it may be
+// stored, the headers recorded being only those that the handler itself set
+type idempotentResponseWriter struct {
+	writer   http.ResponseWriter
+	before   http.Header
+	header   http.Header
+	code     int
+	body     bytes.Buffer
+	hijacked bool
+}
+
+func (i *idempotentResponseWriter) Header() http.Header {
+	return i.writer.Header()
+}
+
+func (i *idempotentResponseWriter) WriteHeader(code int) {
+	if i.code == 0 {
+		i.code = code
+		i.header = make(http.Header)
+
+		for name, values := range i.writer.Header() {
+			if !reflect.DeepEqual(i.before[name], values) {
+				i.header[name] = values
+			}
+		}
+	}
+
+	i.writer.WriteHeader(code)
+}
+
+func (i *idempotentResponseWriter) Write(p []byte) (int, error) {
+	if i.code == 0 {
+		i.WriteHeader(http.StatusOK)
+	}
+
+	i.body.Write(p)
+
+	return i.writer.Write(p)
+}
+
+// Flush sends any buffered data to the API caller, should the underlying
+// http.ResponseWriter support it
+func (i *idempotentResponseWriter) Flush() {
+	if flusher, OK := i.writer.(http.Flusher); OK {
+		if i.code == 0 {
+			i.WriteHeader(http.StatusOK)
+		}
+
+		flusher.Flush()
+	}
+}
+
+// Hijack allows the handler to take over the connection, should the underlying
+// http.ResponseWriter support it, in which case the response is not stored
+func (i *idempotentResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, OK := i.writer.(http.Hijacker)
+	if !OK {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		i.hijacked = true
+	}
+
+	return conn, rw, err
+}
+
+// Unwrap allows an http.ResponseController to reach the underlying http.ResponseWriter
+func (i *idempotentResponseWriter) Unwrap() http.ResponseWriter {
+	return i.writer
+}
+
+// StatusCode returns the status code that was written, HTTP-200 should nothing have been
+func (i *idempotentResponseWriter) StatusCode() int {
+	if i.code == 0 {
+		return http.StatusOK
+	}
+
+	return i.code
+}
+
+func (i *idempotentResponseWriter) response() idempotency.Response {
+	return idempotency.Response{
+		Status: i.StatusCode(),
+		Header: i.header,
+		Body:   i.body.Bytes(),
+	}
+}
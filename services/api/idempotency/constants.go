@@ -0,0 +1,31 @@
+package idempotency
+
+import "time"
+
+// nolint: unused
+const (
+	DefaultTTL           = 24 * time.Hour
+	DefaultLockTTL       = time.Minute
+	DefaultPollInterval  = 50 * time.Millisecond
+	DefaultSweepInterval = time.Minute
+	DefaultStoreTimeout  = 5 * time.Second
+	DefaultMaxBodySize   = 1 << 20
+
+	DefaultTable = "idempotency_keys"
+
+	MaxKeyLength = 255
+)
+
+// nolint: unused
+const (
+	TTLEnvKey          = "IDEMPOTENCY_TTL"           // i.e. 24h
+	LockTTLEnvKey      = "IDEMPOTENCY_LOCK_TTL"      // i.e. 1m
+	WaitEnvKey         = "IDEMPOTENCY_WAIT"          // i.e. 5s, concurrent duplicates are refused if not set
+	StoreTimeoutEnvKey = "IDEMPOTENCY_STORE_TIMEOUT" // i.e. 5s
+)
+
+// nolint: unused
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderReplayed       = "Idempotent-Replayed"
+)
@@ -0,0 +1,59 @@
+package idempotency
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// NewGuardFromEnv will instantiate and return a Guard using the TTL, lock TTL, wait and
+// store timeout named within the environment, each of which is optional
+func NewGuardFromEnv(env utils.Environ, options ...Option) (*Guard, error) {
+	newopt := []Option{}
+
+	for key, option := range map[string]func(time.Duration) Option{
+		TTLEnvKey:          WithTTL,
+		LockTTLEnvKey:      WithLockTTL,
+		WaitEnvKey:         WithWait,
+		StoreTimeoutEnvKey: WithStoreTimeout,
+	} {
+		value, OK := env.Get(key)
+		if !OK || value == "" {
+			continue
+		}
+
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return nil, errs.Errorf(errs.ErrTypeConfiguration, "invalid %s '%s'", key, value)
+		}
+
+		newopt = append(newopt, option(duration))
+	}
+
+	return NewGuard(append(newopt, options...)...), nil
+}
+
+// NewGuard will instantiate and return a Guard that holds the keys of POST and PATCH
+// requests in memory, for DefaultTTL, unless configured otherwise
+func NewGuard(options ...Option) *Guard {
+	guard := Guard{
+		store:   NewMemoryStore(),
+		scope:   BySubject,
+		ttl:     DefaultTTL,
+		lockTTL: DefaultLockTTL,
+		timeout: DefaultStoreTimeout,
+		now:     time.Now,
+
+		maxBodySize: DefaultMaxBodySize,
+	}
+
+	WithMethods(http.MethodPost, http.MethodPatch)(&guard)
+
+	for _, option := range options {
+		option(&guard)
+	}
+
+	return &guard
+}
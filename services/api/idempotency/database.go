@@ -0,0 +1,119 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/db"
+
+	stderr "errors"
+)
+
+var _ Store = new(DatabaseStore)
+
+// DatabaseStore is a Store that holds the keys within a table of the database provided by
+// the db.Adapter (in a manner supported by both CockroachDB and SQLite)... expired keys are
+// discarded as they are reused, so a periodic 'DELETE ... WHERE expires_at < now' may be
+// worthwhile for a busy service
+type DatabaseStore struct {
+	adapter db.Adapter
+	table   string
+}
+
+// row is a key as held within the table, the response being JSON encoded
+type row struct {
+	Fingerprint string
+	Response    *string
+	ExpiresAt   int64
+}
+
+// NewDatabaseStore will instantiate and return a DatabaseStore, creating its table (named
+// DefaultTable unless another is given) should it not exist
+func NewDatabaseStore(ctx context.Context, adapter db.Adapter, table ...string) (*DatabaseStore, error) {
+	store := DatabaseStore{adapter: adapter, table: DefaultTable}
+	if len(table) > 0 && table[0] != "" {
+		store.table = table[0]
+	}
+
+	err := adapter.Conn(ctx).Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (id VARCHAR(64) PRIMARY KEY, fingerprint VARCHAR(64) NOT NULL, response TEXT, expires_at BIGINT NOT NULL)",
+		store.table)).Error
+	if err != nil {
+		return nil, errs.Wrap(err, errs.ErrTypeDatabase, "unable to create idempotency key table")
+	}
+
+	return &store, nil
+}
+
+func (d *DatabaseStore) Reserve(ctx context.Context, key string, record Record, now time.Time) (*Record, error) {
+	conn := d.adapter.Conn(ctx)
+
+	// a key that has expired is no longer held...
+	err := conn.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ? AND expires_at <= ?", d.table), key, now.UnixNano()).Error
+	if err != nil {
+		return nil, errs.Wrap(err, errs.ErrTypeDatabase, "unable to reserve idempotency key")
+	}
+
+	for {
+		inserted := conn.Exec(fmt.Sprintf("INSERT INTO %s (id, fingerprint, expires_at) VALUES (?, ?, ?) ON CONFLICT (id) DO NOTHING", d.table),
+			key, record.Fingerprint, record.Expires.UnixNano())
+		if inserted.Error != nil {
+			return nil, errs.Wrap(inserted.Error, errs.ErrTypeDatabase, "unable to reserve idempotency key")
+		}
+
+		if inserted.RowsAffected > 0 {
+			return nil, nil
+		}
+
+		var held row
+
+		err = conn.Table(d.table).Select("fingerprint", "response", "expires_at").Where("id = ?", key).Take(&held).Error
+		if stderr.Is(err, gorm.ErrRecordNotFound) {
+			// the key was released since we attempted to reserve it...
+			continue
+		}
+		if err != nil {
+			return nil, errs.Wrap(err, errs.ErrTypeDatabase, "unable to read idempotency key")
+		}
+
+		existing := Record{Fingerprint: held.Fingerprint, Expires: time.Unix(0, held.ExpiresAt)}
+
+		if held.Response != nil {
+			existing.Response = new(Response)
+			if err = json.Unmarshal([]byte(*held.Response), existing.Response); err != nil {
+				return nil, errs.Wrap(err, errs.ErrTypeUnmarshal, "unable to read idempotency key")
+			}
+		}
+
+		return &existing, nil
+	}
+}
+
+func (d *DatabaseStore) Complete(ctx context.Context, key string, response Response, expires time.Time) error {
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return errs.WithType(err, errs.ErrTypeMarshal)
+	}
+
+	err = d.adapter.Conn(ctx).Exec(fmt.Sprintf("UPDATE %s SET response = ?, expires_at = ? WHERE id = ?", d.table),
+		string(encoded), expires.UnixNano(), key).Error
+	if err != nil {
+		return errs.Wrap(err, errs.ErrTypeDatabase, "unable to store idempotent response")
+	}
+
+	return nil
+}
+
+func (d *DatabaseStore) Release(ctx context.Context, key string) error {
+	err := d.adapter.Conn(ctx).Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", d.table), key).Error
+	if err != nil {
+		return errs.Wrap(err, errs.ErrTypeDatabase, "unable to release idempotency key")
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package idempotency_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/api/idempotency"
+	"github.com/djmarrerajr/common-lib/services/db"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+type testAdapter struct {
+	conn *gorm.DB
+}
+
+func (t *testAdapter) Start(context.Context, *errgroup.Group) error { return nil }
+func (t *testAdapter) Stop() error                                  { return nil }
+func (t *testAdapter) Conn(ctx context.Context) *gorm.DB            { return t.conn.WithContext(ctx) }
+
+func (t *testAdapter) RunInTx(ctx context.Context, fn db.TxFunc) error {
+	return db.RunInTx(ctx, t.conn, db.RetryPolicy{}, fn)
+}
+
+type IdempotencyTestSuite struct {
+	suite.Suite
+
+	now time.Time
+}
+
+func (i *IdempotencyTestSuite) SetupTest() {
+	i.now = time.Unix(1700000000, 0)
+}
+
+func (i *IdempotencyTestSuite) TestNewGuardFromEnv() {
+	_, err := idempotency.NewGuardFromEnv(utils.NewEnviron(map[string]string{idempotency.TTLEnvKey: "a day"}))
+	i.Equal(errs.ErrTypeConfiguration, errs.GetType(err))
+
+	guard, err := idempotency.NewGuardFromEnv(utils.NewEnviron(map[string]string{idempotency.TTLEnvKey: "1h"}), i.clock())
+	i.Require().NoError(err)
+
+	key, _, err := guard.Reserve(i.request("key-1", "body"))
+	i.Require().NoError(err)
+	i.Require().NoError(guard.Complete(context.Background(), key, idempotency.Response{Status: http.StatusCreated}))
+
+	i.now = i.now.Add(59 * time.Minute)
+
+	_, replay, _ := guard.Reserve(i.request("key-1", "body"))
+	i.NotNil(replay, "the response is stored for the TTL")
+
+	i.now = i.now.Add(time.Minute)
+
+	_, replay, _ = guard.Reserve(i.request("key-1", "body"))
+	i.Nil(replay, "the response has expired")
+}
+
+func (i *IdempotencyTestSuite) TestGuard_Applies() {
+	guard := idempotency.NewGuard()
+
+	i.True(guard.Applies(i.request("key-1", "")))
+	i.False(guard.Applies(i.request("", "")), "the request has no key")
+	i.False(guard.Applies(httptest.NewRequest(http.MethodGet, "/pay", nil)))
+
+	guard = idempotency.NewGuard(idempotency.WithMethods(http.MethodPut))
+	i.False(guard.Applies(i.request("key-1", "")))
+}
+
+func (i *IdempotencyTestSuite) TestGuard_KeysAreScoped() {
+	guard := idempotency.NewGuard(i.clock())
+
+	alice := i.request("key-1", "body")
+	alice = alice.WithContext(utils.AddFieldToContext(alice.Context(), shared.SubjectContextKey, "alice"))
+
+	key, _, err := guard.Reserve(alice)
+	i.Require().NoError(err)
+	i.Require().NoError(guard.Complete(context.Background(), key, idempotency.Response{Status: http.StatusCreated}))
+
+	_, replay, err := guard.Reserve(i.request("key-1", "body"))
+	i.Require().NoError(err)
+	i.Nil(replay, "another client's key is not shared")
+
+	_, _, err = guard.Reserve(i.request("key-1", "body"))
+	i.ErrorIs(err, idempotency.ErrInProgress, "the unauthenticated scope holds the key")
+}
+
+func (i *IdempotencyTestSuite) TestGuard_LockExpires() {
+	guard := idempotency.NewGuard(idempotency.WithLockTTL(time.Minute), i.clock())
+
+	_, _, err := guard.Reserve(i.request("key-1", "body"))
+	i.Require().NoError(err)
+
+	_, _, err = guard.Reserve(i.request("key-1", "body"))
+	i.ErrorIs(err, idempotency.ErrInProgress)
+
+	i.now = i.now.Add(time.Minute)
+
+	_, _, err = guard.Reserve(i.request("key-1", "body"))
+	i.NoError(err, "the abandoned key may be reserved again")
+}
+
+func (i *IdempotencyTestSuite) TestGuard_WaitsForResponse() {
+	guard := idempotency.NewGuard(idempotency.WithWait(time.Second))
+
+	key, _, err := guard.Reserve(i.request("key-1", "body"))
+	i.Require().NoError(err)
+
+	go func() {
+		time.Sleep(2 * idempotency.DefaultPollInterval)
+		// nolint: errcheck
+		guard.Complete(context.Background(), key, idempotency.Response{Status: http.StatusAccepted, Body: []byte("done")})
+	}()
+
+	_, replay, err := guard.Reserve(i.request("key-1", "body"))
+	i.Require().NoError(err)
+	i.Require().NotNil(replay)
+	i.Equal(http.StatusAccepted, replay.Status)
+	i.Equal("done", string(replay.Body))
+}
+
+func (i *IdempotencyTestSuite) TestGuard_BodyIsPreserved() {
+	guard := idempotency.NewGuard()
+	req := i.request("key-1", "body")
+
+	_, _, err := guard.Reserve(req)
+	i.Require().NoError(err)
+
+	body, err := io.ReadAll(req.Body)
+	i.Require().NoError(err)
+	i.Equal("body", string(body))
+}
+
+func (i *IdempotencyTestSuite) TestGuard_LargeBodyIsRefused() {
+	guard := idempotency.NewGuard(idempotency.WithMaxBodySize(8))
+
+	_, _, err := guard.Reserve(i.request("key-1", "a body that is too large"))
+	i.Equal(errs.ErrTypeRequestTooLarge, errs.GetType(err))
+
+	_, _, err = guard.Reserve(i.request("key-1", "body"))
+	i.NoError(err, "the refused request did not reserve the key")
+}
+
+func (i *IdempotencyTestSuite) TestDatabaseStore() {
+	conn, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	i.Require().NoError(err)
+
+	ctx := context.Background()
+
+	store, err := idempotency.NewDatabaseStore(ctx, &testAdapter{conn})
+	i.Require().NoError(err)
+
+	guard := idempotency.NewGuard(idempotency.WithStore(store), idempotency.WithLockTTL(time.Minute), i.clock())
+
+	key, replay, err := guard.Reserve(i.request("key-1", "body"))
+	i.Require().NoError(err)
+	i.Nil(replay)
+
+	_, _, err = guard.Reserve(i.request("key-1", "body"))
+	i.ErrorIs(err, idempotency.ErrInProgress)
+
+	_, _, err = guard.Reserve(i.request("key-1", "another body"))
+	i.ErrorIs(err, idempotency.ErrKeyReused)
+
+	response := idempotency.Response{Status: http.StatusCreated, Header: http.Header{"Location": {"/pay/1"}}, Body: []byte(`{"id":1}`)}
+	i.Require().NoError(guard.Complete(ctx, key, response))
+
+	_, replay, err = guard.Reserve(i.request("key-1", "body"))
+	i.Require().NoError(err)
+	i.Equal(&response, replay)
+
+	i.now = i.now.Add(idempotency.DefaultTTL)
+
+	_, replay, err = guard.Reserve(i.request("key-1", "body"))
+	i.Require().NoError(err)
+	i.Nil(replay, "the expired key has been reserved again")
+
+	i.Require().NoError(guard.Release(ctx, key))
+
+	_, replay, err = guard.Reserve(i.request("key-1", "another body"))
+	i.Require().NoError(err)
+	i.Nil(replay, "the released key may be reused")
+}
+
+func (i *IdempotencyTestSuite) TestGuard_StoreOutlivesRequestWithinTimeout() {
+	store := &contextStore{Store: idempotency.NewMemoryStore()}
+	guard := idempotency.NewGuard(idempotency.WithStore(store), idempotency.WithStoreTimeout(time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	i.Require().NoError(guard.Complete(ctx, "key-1", idempotency.Response{Status: http.StatusCreated}))
+	i.Require().NoError(guard.Release(ctx, "key-2"))
+
+	i.Len(store.seen, 2)
+	for _, err := range store.seen {
+		i.NoError(err, "the request's cancellation should not reach the Store")
+	}
+
+	for _, deadline := range store.deadlines {
+		i.WithinDuration(time.Now().Add(time.Second), deadline, time.Second, "the Store should be bounded by the timeout")
+	}
+}
+
+func (i *IdempotencyTestSuite) request(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/pay", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(idempotency.HeaderIdempotencyKey, key)
+	}
+
+	return req
+}
+
+func (i *IdempotencyTestSuite) clock() idempotency.Option {
+	return idempotency.WithClock(func() time.Time { return i.now })
+}
+
+// contextStore records the state of the context with which it is completed or released
+type contextStore struct {
+	idempotency.Store
+
+	seen      []error
+	deadlines []time.Time
+}
+
+func (c *contextStore) Complete(ctx context.Context, key string, response idempotency.Response, expires time.Time) error {
+	c.record(ctx)
+	return c.Store.Complete(ctx, key, response, expires)
+}
+
+func (c *contextStore) Release(ctx context.Context, key string) error {
+	c.record(ctx)
+	return c.Store.Release(ctx, key)
+}
+
+func (c *contextStore) record(ctx context.Context) {
+	deadline, _ := ctx.Deadline()
+
+	c.seen = append(c.seen, ctx.Err())
+	c.deadlines = append(c.deadlines, deadline)
+}
+
+func TestIdempotency(t *testing.T) {
+	suite.Run(t, new(IdempotencyTestSuite))
+}
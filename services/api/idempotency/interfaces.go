@@ -0,0 +1,20 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Store holds the Record of each idempotency key... the MemoryStore is local to the process
+// whereas the DatabaseStore allows duplicates to be detected across every instance of a service
+type Store interface {
+	// Reserve records the key as in progress unless it is already held, and has not expired,
+	// in which case the Record already held is returned (otherwise nil)
+	Reserve(ctx context.Context, key string, record Record, now time.Time) (*Record, error)
+
+	// Complete stores the response to the request for which the key was reserved
+	Complete(ctx context.Context, key string, response Response, expires time.Time) error
+
+	// Release discards the key so that the request may be made again
+	Release(ctx context.Context, key string) error
+}
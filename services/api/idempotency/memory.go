@@ -0,0 +1,74 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Store = new(MemoryStore)
+
+// MemoryStore is a Store that holds the keys in memory... expired keys are periodically
+// discarded so that the memory used is bounded by the keys used within the TTL
+type MemoryStore struct {
+	mu sync.Mutex
+
+	records map[string]Record
+	swept   time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]Record),
+	}
+}
+
+func (m *MemoryStore) Reserve(_ context.Context, key string, record Record, now time.Time) (*Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.swept) >= DefaultSweepInterval {
+		m.sweep(now)
+	}
+
+	if held, exists := m.records[key]; exists && now.Before(held.Expires) {
+		return &held, nil
+	}
+
+	m.records[key] = record
+
+	return nil, nil
+}
+
+func (m *MemoryStore) Complete(_ context.Context, key string, response Response, expires time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record := m.records[key]
+	record.Response = &response
+	record.Expires = expires
+
+	m.records[key] = record
+
+	return nil
+}
+
+func (m *MemoryStore) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+
+	return nil
+}
+
+// sweep discards those keys that have expired
+func (m *MemoryStore) sweep(now time.Time) {
+	for key, record := range m.records {
+		if !now.Before(record.Expires) {
+			delete(m.records, key)
+		}
+	}
+
+	m.swept = now
+}
@@ -0,0 +1,76 @@
+package idempotency
+
+import "time"
+
+type Option func(*Guard)
+
+// WithStore will replace the (in-memory) Store in which the keys are held
+func WithStore(store Store) Option {
+	return func(g *Guard) {
+		g.store = store
+	}
+}
+
+// WithScope will replace the function used to scope the keys to a client (BySubject by default)
+func WithScope(fn ScopeFunc) Option {
+	return func(g *Guard) {
+		g.scope = fn
+	}
+}
+
+// WithMethods will replace the methods of the requests that are guarded (POST and PATCH by default)
+func WithMethods(methods ...string) Option {
+	return func(g *Guard) {
+		g.methods = make(map[string]struct{}, len(methods))
+		for _, method := range methods {
+			g.methods[method] = struct{}{}
+		}
+	}
+}
+
+// WithTTL will replace the time for which a response is stored (DefaultTTL by default)
+func WithTTL(ttl time.Duration) Option {
+	return func(g *Guard) {
+		g.ttl = ttl
+	}
+}
+
+// WithLockTTL will replace the time for which a key is held by a request in progress, after
+// which it is considered abandoned (DefaultLockTTL by default)
+func WithLockTTL(ttl time.Duration) Option {
+	return func(g *Guard) {
+		g.lockTTL = ttl
+	}
+}
+
+// WithWait will cause a request whose key is held by a request in progress to wait, for
+// up to the timeout, for its response rather than being refused with ErrInProgress
+func WithWait(timeout time.Duration) Option {
+	return func(g *Guard) {
+		g.wait = timeout
+	}
+}
+
+// WithStoreTimeout will replace the time permitted to store, or release, a key once the
+// request has been handled (DefaultStoreTimeout by default)
+func WithStoreTimeout(timeout time.Duration) Option {
+	return func(g *Guard) {
+		g.timeout = timeout
+	}
+}
+
+// WithMaxBodySize will replace the size of the largest request body that will be read in
+// order to tell requests apart (DefaultMaxBodySize by default), a larger request being
+// refused with an errs.ErrTypeRequestTooLarge error
+func WithMaxBodySize(size int64) Option {
+	return func(g *Guard) {
+		g.maxBodySize = size
+	}
+}
+
+// WithClock will replace the function used to obtain the current time
+func WithClock(now func() time.Time) Option {
+	return func(g *Guard) {
+		g.now = now
+	}
+}
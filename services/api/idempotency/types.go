@@ -0,0 +1,198 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+
+	stderr "errors"
+)
+
+var (
+	// ErrInvalidKey is returned for a request whose key is longer than MaxKeyLength
+	ErrInvalidKey = errs.Sentinel(errs.ErrTypeValidation, "idempotency key is too long")
+
+	// ErrKeyReused is returned for a request whose key was used by a request that differed from it
+	ErrKeyReused = errs.Sentinel(errs.ErrTypeValidation, "idempotency key has been used by a different request")
+
+	// ErrInProgress is returned for a request whose key is held by a request still in progress
+	ErrInProgress = errs.Sentinel(errs.ErrTypeConflict, "a request with the same idempotency key is in progress")
+)
+
+// Response is the stored response to a request, replayed in place of repeating the request
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Record is held for each idempotency key, the Response being nil while the request that
+// reserved the key is in progress
+type Record struct {
+	Fingerprint string
+	Response    *Response
+	Expires     time.Time
+}
+
+// ScopeFunc identifies the client that made the request, only requests having the same
+// scope (and method and path) sharing their idempotency keys
+type ScopeFunc func(*http.Request) string
+
+// BySubject scopes the keys by the subject of the client's token (see api.WithAuthentication)
+// so that one client's keys can never be used by another... unauthenticated requests share
+// a single scope
+func BySubject(r *http.Request) string {
+	subject, _ := utils.GetFieldValueFromContext[string](r.Context(), shared.SubjectContextKey)
+
+	return subject
+}
+
+// Guard gives requests that carry an Idempotency-Key header 'at most once' semantics by
+// storing the response to the first request with a key, to be replayed to any later
+// request with the same key
+type Guard struct {
+	store   Store
+	scope   ScopeFunc
+	methods map[string]struct{}
+	ttl     time.Duration
+	lockTTL time.Duration
+	wait    time.Duration
+	timeout time.Duration
+	now     func() time.Time
+
+	maxBodySize int64
+}
+
+// Applies reports whether the request should be guarded, i.e. it is a POST (or PATCH)
+// request that carries an Idempotency-Key header
+func (g *Guard) Applies(r *http.Request) bool {
+	_, guarded := g.methods[r.Method]
+
+	return guarded && r.Header.Get(HeaderIdempotencyKey) != ""
+}
+
+// Reserve will reserve the request's key, returning the key by which its response should
+// later be stored or, should the key have been used before, the Response to be replayed
+//
+// The request body is read, and replaced, so that the request may be told apart from any
+// other that used its key.  Should a request with the same key be in progress the Guard
+// waits (if configured to) for its Response, otherwise ErrInProgress is returned.
+func (g *Guard) Reserve(r *http.Request) (string, *Response, error) {
+	value := r.Header.Get(HeaderIdempotencyKey)
+	if len(value) > MaxKeyLength {
+		return "", nil, ErrInvalidKey.WithStack()
+	}
+
+	fingerprint, err := fingerprintOf(r, g.maxBodySize)
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := hashOf(g.scope(r), r.Method, r.URL.Path, value)
+
+	var deadline time.Time
+	if g.wait > 0 {
+		deadline = g.now().Add(g.wait)
+	}
+
+	for {
+		now := g.now()
+
+		record, err := g.store.Reserve(r.Context(), key, Record{Fingerprint: fingerprint, Expires: now.Add(g.lockTTL)}, now)
+		if err != nil {
+			return "", nil, err
+		}
+
+		switch {
+		case record == nil:
+			return key, nil, nil
+		case record.Fingerprint != fingerprint:
+			return "", nil, ErrKeyReused.WithStack()
+		case record.Response != nil:
+			return key, record.Response, nil
+		case !now.Before(deadline):
+			return "", nil, ErrInProgress.WithStack()
+		}
+
+		// the request in progress will either complete, or release the key, in time...
+		select {
+		case <-r.Context().Done():
+			return "", nil, errs.WithType(r.Context().Err(), errs.ErrTypeTimeout)
+		case <-time.After(DefaultPollInterval):
+		}
+	}
+}
+
+// Complete will store the response to the request for which the key was reserved... the
+// context's cancellation is ignored, so that a response written to a client that has since
+// gone away is still stored, but the Store is given no longer than the store timeout
+func (g *Guard) Complete(ctx context.Context, key string, response Response) error {
+	ctx, cancel := g.detach(ctx)
+	defer cancel()
+
+	return g.store.Complete(ctx, key, response, g.now().Add(g.ttl))
+}
+
+// Release will discard the key, i.e. should the request have failed, so that the request
+// may be made again... as with Complete, the context's cancellation is ignored
+func (g *Guard) Release(ctx context.Context, key string) error {
+	ctx, cancel := g.detach(ctx)
+	defer cancel()
+
+	return g.store.Release(ctx, key)
+}
+
+// detach returns a copy of the context that is not cancelled along with it, but that is
+// bounded by the store timeout (should there be one)
+func (g *Guard) detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = context.WithoutCancel(ctx)
+
+	if g.timeout > 0 {
+		return context.WithTimeout(ctx, g.timeout)
+	}
+
+	return ctx, func() {}
+}
+
+// fingerprintOf returns the hash of the request body, which is replaced so that it may
+// still be read by the request handler... a body larger than the limit is refused
+func fingerprintOf(r *http.Request, limit int64) (string, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return hashOf(), nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if stderr.As(err, &tooLarge) {
+			return "", errs.Errorf(errs.ErrTypeRequestTooLarge, "request body exceeds %d bytes", tooLarge.Limit)
+		}
+
+		return "", errs.WithType(err, errs.ErrTypeUnmarshal)
+	}
+
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return hashOf(string(body)), nil
+}
+
+// hashOf returns the (hex encoded) SHA-256 hash of the values
+func hashOf(values ...string) string {
+	hash := sha256.New()
+
+	for _, value := range values {
+		// nolint: errcheck
+		hash.Write(append([]byte(value), 0))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
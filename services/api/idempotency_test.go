@@ -0,0 +1,146 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/services/api/idempotency"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+type payment struct {
+	Name  string `json:"name"`
+	Calls int    `json:"calls"`
+}
+
+func (h *HandlerTestSuite) TestIdempotency_DuplicatesAreReplayed() {
+	collector, err := metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "test")
+	h.Require().NoError(err)
+
+	h.appctx.Collector = collector
+
+	calls := 0
+	server := h.newServer(func(_ context.Context, _ *shared.ApplicationContext, req any) (any, int) {
+		calls++
+		return payment{req.(*testRequest).Name, calls}, http.StatusCreated
+	}, api.WithIdempotency(idempotency.NewGuard()))
+
+	first := h.pay(server, "key-1", `{"name":"alice"}`)
+	h.Equal(http.StatusCreated, first.Code)
+	h.Empty(first.Header().Get(idempotency.HeaderReplayed))
+
+	replayed := h.pay(server, "key-1", `{"name":"alice"}`)
+	h.Equal(http.StatusCreated, replayed.Code)
+	h.Equal("true", replayed.Header().Get(idempotency.HeaderReplayed))
+	h.Equal(first.Header().Get(api.HeaderContentType), replayed.Header().Get(api.HeaderContentType))
+	h.JSONEq(first.Body.String(), replayed.Body.String())
+	h.Equal(1, calls, "the handler is not invoked for a duplicate")
+
+	other := h.pay(server, "key-2", `{"name":"alice"}`)
+	h.Equal(http.StatusCreated, other.Code)
+	h.Equal(2, calls)
+
+	unkeyed := h.serve(server, `{"name":"alice"}`)
+	h.Equal(http.StatusCreated, unkeyed.Code)
+	h.Equal(3, calls, "requests without a key are not guarded")
+
+	scrape := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, api.MetricsPath, nil))
+
+	h.Contains(scrape.Body.String(), `test_idempotent_replays_total{appName="",appVersion="",environ="",hostname="",path="test"} 1`)
+}
+
+func (h *HandlerTestSuite) TestIdempotency_RefusedRequests() {
+	var server *api.Server
+
+	server = h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		// a duplicate made while the first is in progress...
+		rec := h.pay(server, "key-1", `{"name":"alice"}`)
+
+		h.Equal(http.StatusConflict, rec.Code)
+		h.Equal(errs.ErrTypeConflict, h.decodeError(rec).Type)
+
+		return payment{Name: "alice"}, http.StatusCreated
+	}, api.WithIdempotency(idempotency.NewGuard()))
+
+	h.Equal(http.StatusCreated, h.pay(server, "key-1", `{"name":"alice"}`).Code)
+
+	reused := h.pay(server, "key-1", `{"name":"bob"}`)
+	h.Equal(http.StatusUnprocessableEntity, reused.Code)
+	h.Equal(errs.ErrTypeValidation, h.decodeError(reused).Type)
+
+	invalid := h.pay(server, strings.Repeat("k", idempotency.MaxKeyLength+1), `{"name":"alice"}`)
+	h.Equal(http.StatusUnprocessableEntity, invalid.Code)
+}
+
+func (h *HandlerTestSuite) TestIdempotency_FailuresAreNotStored() {
+	calls := 0
+	server := h.newServer(func(_ context.Context, _ *shared.ApplicationContext, req any) (any, int) {
+		calls++
+		if calls == 1 {
+			return errs.New(errs.ErrTypeUnknown, "boom"), 0
+		}
+		return payment{req.(*testRequest).Name, calls}, http.StatusCreated
+	}, api.WithIdempotency(idempotency.NewGuard()))
+
+	h.Equal(http.StatusInternalServerError, h.pay(server, "key-1", `{"name":"alice"}`).Code)
+
+	retried := h.pay(server, "key-1", `{"name":"alice"}`)
+	h.Equal(http.StatusCreated, retried.Code)
+	h.Empty(retried.Header().Get(idempotency.HeaderReplayed))
+
+	var resp payment
+	h.Require().NoError(json.Unmarshal(retried.Body.Bytes(), &resp))
+	h.Equal(payment{"alice", 2}, resp)
+}
+
+func (h *HandlerTestSuite) TestIdempotency_SkippedRoutes() {
+	calls := 0
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		calls++
+		return nil, http.StatusNoContent
+	}, api.WithIdempotency(idempotency.NewGuard(), "/test"))
+
+	for i := 0; i < 2; i++ {
+		h.Equal(http.StatusNoContent, h.pay(server, "key-1", `{"name":"alice"}`).Code)
+	}
+
+	h.Equal(2, calls)
+}
+
+func (h *HandlerTestSuite) TestIdempotency_RouteBodyLimitIsApplied() {
+	called := false
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) { return nil, 0 },
+		api.WithIdempotency(idempotency.NewGuard()))
+
+	server.DefineRequestHandlerWithOptions("/test", func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		called = true
+		return nil, http.StatusNoContent
+	}, testRequest{}, api.RouteMethods(http.MethodPost), api.RouteMaxBodySize(16))
+
+	rec := h.pay(server, "key-1", `{"name":"a name that is far too long"}`)
+
+	h.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+	h.Equal(errs.ErrTypeRequestTooLarge, h.decodeError(rec).Type)
+	h.False(called)
+
+	h.Equal(http.StatusNoContent, h.pay(server, "key-1", `{"name":"alice"}`).Code, "the refused request did not reserve the key")
+}
+
+func (h *HandlerTestSuite) pay(server *api.Server, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+	req.Header.Set(api.HeaderContentType, api.ValueApplicationJson)
+	req.Header.Set(idempotency.HeaderIdempotencyKey, key)
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	return rec
+}
@@ -1,3 +1,22 @@
 package api
 
+import (
+	"io"
+	"net/http"
+)
+
 type Option func(*Server)
+
+// Codec is responsible for the decoding of request bodies, and the encoding of responses,
+// of a single media type (see CodecRegistry)
+type Codec interface {
+	MediaType() string
+	Decode(*http.Request, any) error
+	Encode(io.Writer, any) error
+}
+
+// Compressor encodes response bodies using a content-coding, i.e. gzip
+type Compressor interface {
+	Encoding() string
+	NewWriter(io.Writer) (io.WriteCloser, error)
+}
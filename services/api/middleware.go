@@ -2,12 +2,15 @@ package api
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
 	"github.com/djmarrerajr/common-lib/shared"
 	"github.com/djmarrerajr/common-lib/utils"
 )
@@ -15,41 +18,146 @@ import (
 // MetricsMiddleware will integrate with the metrics collector service to create
 // and increment a standard set of obversability metrics for each, registered,
 // api endpoint
+//
+// If the application has no metrics collector the middleware does nothing
 func MetricsMiddleware(appCtx shared.ApplicationContext) mux.MiddlewareFunc {
 	collector := appCtx.Collector
+	if collector == nil {
+		return func(h http.Handler) http.Handler { return h }
+	}
 
-	filterLabels := []string{shared.EnvironContextKey, shared.HostnameContextKey, shared.AppNameContextKey, shared.AppVersionContextKey}
+	filterLabels := shared.MetricFilterLabels()
 
 	// define out standard set of api metrics...
 	requestByPath := collector.NewDimensionedCounter("requests_total", append(filterLabels, "path")...)
 	responseStatusByPath := collector.NewDimensionedCounter("response_status", append(filterLabels, "path", "statusCode")...)
 	responseErrorsByPath := collector.NewDimensionedCounter("response_errors", append(filterLabels, "path", "errorType")...)
-	responseTimeByPath := collector.NewDimensionedGauge("response_time", append(filterLabels, "path")...)
+	responseTimeByPath := collector.NewDimensionedHistogram("response_time_seconds", DefaultLatencyBuckets, append(filterLabels, "path")...)
+	requestBytesByPath := collector.NewDimensionedCounter("request_bytes_total", append(filterLabels, "path")...)
+	responseBytesByPath := collector.NewDimensionedCounter("response_bytes_total", append(filterLabels, "path")...)
+	requestTimeoutsByPath := collector.NewDimensionedCounter("request_timeouts_total", append(filterLabels, "path")...)
 
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			path := strings.ReplaceAll(r.URL.Path[1:], "/", "_")
+			path := metricPath(r)
 
 			mw := &metricsResponseWriter{writer: w}
 			st := time.Now()
 
+			body := &countingBody{ReadCloser: r.Body}
+			if r.Body != nil {
+				r.Body = body
+			}
+
 			h.ServeHTTP(mw, r)
 
 			// grab values for our standard metric labels...
-			envn, _ := utils.GetFieldValueFromContext[string](appCtx.RootCtx, shared.EnvironContextKey)
-			host, _ := utils.GetFieldValueFromContext[string](appCtx.RootCtx, shared.HostnameContextKey)
-			appl, _ := utils.GetFieldValueFromContext[string](appCtx.RootCtx, shared.AppNameContextKey)
-			vrsn, _ := utils.GetFieldValueFromContext[string](appCtx.RootCtx, shared.AppVersionContextKey)
-
-			filterValues := []string{envn, host, appl, vrsn}
+			filterValues := shared.MetricFilterValues(appCtx.RootCtx)
 
 			// now increment our standard metrics...
 			requestByPath.WithLabelValues(append(filterValues, path)...).Inc()
-			responseStatusByPath.WithLabelValues(append(filterValues, path, fmt.Sprint(mw.code))...).Inc()
-			responseTimeByPath.WithLabelValues(append(filterValues, path)...).Set(float64(time.Since(st).Milliseconds()))
+			responseStatusByPath.WithLabelValues(append(filterValues, path, fmt.Sprint(mw.StatusCode()))...).Inc()
+			responseTimeByPath.WithLabelValues(append(filterValues, path)...).Observe(time.Since(st).Seconds())
+			requestBytesByPath.WithLabelValues(append(filterValues, path)...).Add(float64(body.read))
+			responseBytesByPath.WithLabelValues(append(filterValues, path)...).Add(float64(mw.written))
 			if mw.errorType != "" {
 				responseErrorsByPath.WithLabelValues(append(filterValues, path, string(mw.errorType))...).Inc()
 			}
+			if mw.errorType == errs.ErrTypeTimeout {
+				requestTimeoutsByPath.WithLabelValues(append(filterValues, path)...).Inc()
+			}
 		})
 	}
 }
+
+// newDimensionedCounter returns the named counter, having our standard metric labels as
+// well as those provided, or nil should the application have no Collector
+func newDimensionedCounter(appCtx shared.ApplicationContext, name string, labels ...string) *metrics.DimensionedCounter {
+	if appCtx.Collector == nil {
+		return nil
+	}
+
+	counter := appCtx.Collector.NewDimensionedCounter(name, append(shared.MetricFilterLabels(), labels...)...)
+
+	return &counter
+}
+
+// countingBody counts the bytes read from the request body, as they were sent by the API caller
+type countingBody struct {
+	io.ReadCloser
+	read int
+}
+
+func (c *countingBody) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.read += n
+
+	return n, err
+}
+
+// returnMiddlewareError will return a standardized error to the API caller on behalf of a
+// middleware function, the response being rendered just as a request handler's would be
+// using the Server's configuration (error statuses, codecs and problem details)
+func returnMiddlewareError(s *Server, appCtx *shared.ApplicationContext, w http.ResponseWriter, r *http.Request, err error, status int) {
+	reqCtx := utils.AddMapToContext(r.Context(), utils.GetFieldMapFromContext(appCtx.RootCtx))
+
+	codec, OK := s.Codecs.Negotiate(r.Header.Get(HeaderAccept), ValueApplicationJson)
+	if !OK {
+		codec = defaultCodec
+	}
+
+	handler := ContextualHandler{
+		ApplicationContext: appCtx,
+		ErrorStatuses:      s.ErrorStatuses,
+		Codecs:             s.Codecs,
+		problems:           s.problems,
+	}
+
+	handler.returnErrorResponse(w, r, reqCtx, codec, err, status)
+}
+
+// metricPath returns the value of the 'path' label for the request, i.e. /api/orders = api_orders
+func metricPath(r *http.Request) string {
+	return strings.ReplaceAll(strings.TrimPrefix(r.URL.Path, "/"), "/", "_")
+}
+
+// newSkippedRoutes returns the set of routes to be skipped by a middleware function, which
+// always includes the health and metrics routes
+func newSkippedRoutes(routesToSkip ...string) map[string]struct{} {
+	routes := map[string]struct{}{
+		HealthPath:    {},
+		LivenessPath:  {},
+		ReadinessPath: {},
+		MetricsPath:   {},
+	}
+
+	for _, r := range routesToSkip {
+		routes[r] = struct{}{}
+	}
+
+	return routes
+}
+
+// isSkipped determines whether or not the request's path, or the template of the
+// route that it matched, is one of the routes
+func isSkipped(routes map[string]struct{}, r *http.Request) bool {
+	if _, exists := routes[r.URL.Path]; exists {
+		return true
+	}
+
+	_, exists := routes[routeTemplate(r)]
+
+	return exists
+}
+
+// routeTemplate returns the template of the route that the request matched (i.e. /orders/{id})
+// or, should it not have matched a route, its path
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+
+	return r.URL.Path
+}
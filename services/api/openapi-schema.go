@@ -0,0 +1,332 @@
+package api
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaRegistry reflects Schemas from Go types, each named struct becoming a component
+// that is then referenced (which also allows for recursive types)
+type schemaRegistry struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// schemaFor returns the Schema of the value's type
+func (r *schemaRegistry) schemaFor(value any) *Schema {
+	return r.schemaOf(reflect.TypeOf(value))
+}
+
+// request will split the request struct in to its parameters (those fields tagged with
+// 'path', 'query' or 'header') and the Schema of its body, if it has one
+func (r *schemaRegistry) request(reqStruct any) ([]Parameter, *Schema) {
+	typ := reflect.TypeOf(reqStruct)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil, r.schemaOf(typ)
+	}
+
+	var params []Parameter
+
+	body := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	r.collectRequest(typ, &params, body)
+
+	if len(body.Properties) == 0 {
+		return params, nil
+	}
+
+	return params, body
+}
+
+// collectRequest populates the parameters and body from the fields of the struct,
+// descending in to any embedded (anonymous) structs
+func (r *schemaRegistry) collectRequest(typ reflect.Type, params *[]Parameter, body *Schema) {
+	for idx := 0; idx < typ.NumField(); idx++ {
+		field := typ.Field(idx)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			r.collectRequest(field.Type, params, body)
+			continue
+		}
+
+		isParam := false
+
+		for _, tag := range []string{TagPath, TagQuery, TagHeader} {
+			name, OK := field.Tag.Lookup(tag)
+			if !OK || name == "" || name == "-" {
+				continue
+			}
+
+			schema := r.parameterSchema(field.Type)
+			required := applyValidation(schema, field)
+
+			*params = append(*params, Parameter{Name: name, In: tag, Required: required, Schema: schema})
+			isParam = true
+		}
+
+		if !isParam {
+			r.addProperty(body, field)
+		}
+	}
+}
+
+// parameterSchema returns the Schema of a parameter, which unlike a body field is always
+// provided as a string and so a time.Duration is described as such
+func (r *schemaRegistry) parameterSchema(typ reflect.Type) *Schema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == durationType:
+		return &Schema{Type: "string", Format: "duration"}
+	case typ.Kind() == reflect.Slice && typ.Elem() == durationType:
+		return &Schema{Type: "array", Items: &Schema{Type: "string", Format: "duration"}}
+	default:
+		return r.schemaOf(typ)
+	}
+}
+
+// addProperty adds the field to the object Schema using the name given by its json tag
+func (r *schemaRegistry) addProperty(object *Schema, field reflect.StructField) {
+	name := field.Name
+
+	if tag, OK := field.Tag.Lookup("json"); OK {
+		parts := strings.Split(tag, ",")
+		if parts[0] == "-" {
+			return
+		}
+		if parts[0] != "" {
+			name = parts[0]
+		}
+	}
+
+	schema := r.schemaOf(field.Type)
+
+	// constraints cannot be added alongside a reference...
+	if schema.Ref != "" {
+		if isRequired(field) {
+			object.Required = append(object.Required, name)
+		}
+	} else if applyValidation(schema, field) {
+		object.Required = append(object.Required, name)
+	}
+
+	object.Properties[name] = schema
+}
+
+// schemaOf returns the Schema of the type
+func (r *schemaRegistry) schemaOf(typ reflect.Type) *Schema {
+	if typ == nil {
+		return &Schema{}
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType):
+		return &Schema{Type: "string", Format: formatOf(typ)}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaOf(typ.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaOf(typ.Elem())}
+	case reflect.Struct:
+		return r.structSchema(typ)
+	default:
+		return &Schema{}
+	}
+}
+
+// structSchema returns a reference to the component describing a named struct, adding
+// the component if necessary... anonymous structs are described inline
+func (r *schemaRegistry) structSchema(typ reflect.Type) *Schema {
+	if typ.Name() == "" {
+		return r.objectSchema(typ)
+	}
+
+	name, exists := r.names[typ]
+	if !exists {
+		name = typ.Name()
+
+		// types from different packages may share a name...
+		for suffix := 2; r.components[name] != nil; suffix++ {
+			name = typ.Name() + strconv.Itoa(suffix)
+		}
+
+		r.names[typ] = name
+		r.components[name] = &Schema{} // reserved before descending in case the type is recursive
+		*r.components[name] = *r.objectSchema(typ)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// objectSchema describes each of the struct's fields
+func (r *schemaRegistry) objectSchema(typ reflect.Type) *Schema {
+	object := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for idx := 0; idx < typ.NumField(); idx++ {
+		field := typ.Field(idx)
+
+		switch {
+		case !field.IsExported():
+			continue
+		case field.Anonymous && field.Type.Kind() == reflect.Struct:
+			embedded := r.objectSchema(field.Type)
+			for name, schema := range embedded.Properties {
+				object.Properties[name] = schema
+			}
+			object.Required = append(object.Required, embedded.Required...)
+		default:
+			r.addProperty(object, field)
+		}
+	}
+
+	return object
+}
+
+// formatOf returns the format of types that are encoded as text, where it is known
+func formatOf(typ reflect.Type) string {
+	if typ.Name() == "UUID" {
+		return "uuid"
+	}
+
+	return ""
+}
+
+// isRequired determines whether or not the field's validate tag includes 'required'
+func isRequired(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyValidation will translate the field's validate tag (see go-playground/validator) in
+// to the equivalent Schema constraints, returning whether or not the field is required
+func applyValidation(schema *Schema, field reflect.StructField) bool {
+	required := false
+
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "dive":
+			// the remaining rules apply to the elements of a collection...
+			return required
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, value)
+			}
+		case "len":
+			applyBound(schema, "min", param)
+			applyBound(schema, "max", param)
+		case "min", "max", "gte", "lte", "gt", "lt":
+			applyBound(schema, name, param)
+		}
+	}
+
+	return required
+}
+
+// applyBound will apply the bound to the length, size or value of the schema as appropriate
+func applyBound(schema *Schema, rule, param string) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	lower := rule == "min" || rule == "gte" || rule == "gt"
+	size := int(value)
+
+	switch schema.Type {
+	case "string":
+		if rule == "gt" {
+			size++
+		} else if rule == "lt" {
+			size--
+		}
+		if lower {
+			schema.MinLength = &size
+		} else {
+			schema.MaxLength = &size
+		}
+	case "array":
+		if rule == "gt" {
+			size++
+		} else if rule == "lt" {
+			size--
+		}
+		if lower {
+			schema.MinItems = &size
+		} else {
+			schema.MaxItems = &size
+		}
+	case "integer", "number":
+		switch rule {
+		case "gt":
+			schema.ExclusiveMinimum = &value
+		case "lt":
+			schema.ExclusiveMaximum = &value
+		case "min", "gte":
+			schema.Minimum = &value
+		default:
+			schema.Maximum = &value
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package api
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pathVariablePattern matches the variables of a gorilla path template, i.e. {id:[0-9]+}
+var pathVariablePattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// OpenAPIDocument is an OpenAPI 3.1 description of the Server's request handlers
+type OpenAPIDocument struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       OpenAPIInfo         `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components,omitempty"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the Operation of each HTTP method (in lowercase) supported by a path
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Schema is the subset of JSON Schema (2020-12) that can be derived from Go types
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+}
+
+// OpenAPI will generate the OpenAPI document describing each of the request handlers that
+// have been defined on the Server
+//
+// Request schemas are reflected from the request struct (and its validator tags) while
+// response schemas are taken from those declared using RouteResponse
+func (s Server) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	doc := OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+	}
+
+	if s.routes == nil {
+		return &doc
+	}
+
+	schemas := newSchemaRegistry()
+
+	for _, rt := range s.routes.list() {
+		path := pathVariablePattern.ReplaceAllString(rt.path, "{$1}")
+
+		methods := rt.config.Methods
+		if len(methods) == 0 {
+			methods = []string{http.MethodGet}
+		}
+
+		item, exists := doc.Paths[path]
+		if !exists {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+
+		for _, method := range methods {
+			item[strings.ToLower(method)] = newOperation(schemas, rt, method)
+		}
+	}
+
+	doc.Components.Schemas = schemas.components
+
+	return &doc
+}
+
+// newOperation will describe the route for the specified method
+func newOperation(schemas *schemaRegistry, rt route, method string) *Operation {
+	op := Operation{
+		OperationID: rt.config.OperationID,
+		Summary:     rt.config.Summary,
+		Description: rt.config.Description,
+		Tags:        rt.config.Tags,
+		Deprecated:  rt.config.Deprecated,
+		Responses:   make(map[string]Response),
+	}
+
+	if op.OperationID != "" && len(rt.config.Methods) > 1 {
+		op.OperationID += method[:1] + strings.ToLower(method[1:])
+	}
+
+	if rt.reqStruct != nil {
+		var body *Schema
+
+		op.Parameters, body = schemas.request(rt.reqStruct)
+
+		// parameters in the path are always required...
+		for idx := range op.Parameters {
+			if op.Parameters[idx].In == TagPath {
+				op.Parameters[idx].Required = true
+			}
+		}
+
+		if body != nil && method != http.MethodGet && method != http.MethodDelete && method != http.MethodHead {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					ValueApplicationJson: {body},
+					ValueApplicationXml:  {body},
+				},
+			}
+		}
+	}
+
+	// every variable of the path template is a required parameter, whether or not the
+	// request struct binds it...
+	op.Parameters = append(op.Parameters, unboundPathParameters(rt.path, op.Parameters)...)
+
+	statuses := make([]int, 0, len(rt.config.Responses))
+	for status := range rt.config.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	for _, status := range statuses {
+		resp := Response{Description: http.StatusText(status)}
+
+		if body := rt.config.Responses[status]; body != nil {
+			resp.Content = map[string]MediaType{ValueApplicationJson: {schemas.schemaFor(body)}}
+		}
+
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+
+	if len(op.Responses) == 0 {
+		op.Responses[strconv.Itoa(http.StatusOK)] = Response{Description: http.StatusText(http.StatusOK)}
+	}
+
+	op.Responses["default"] = Response{
+		Description: "Error",
+		Content: map[string]MediaType{
+			ValueApplicationJson: {schemas.schemaFor(ErrorResponse{})},
+			ValueProblemJson:     {schemas.schemaFor(ProblemDetails{})},
+		},
+	}
+
+	return &op
+}
+
+// openAPIHandler returns an http.HandlerFunc that serves the Server's OpenAPI document
+func (s Server) openAPIHandler(info OpenAPIInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buff, err := json.Marshal(s.OpenAPI(info))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ValueApplicationJson)
+		_, _ = w.Write(buff)
+	}
+}
+
+// unboundPathParameters returns a (string) parameter for each variable of the path template
+// that is not amongst the parameters already bound
+func unboundPathParameters(path string, bound []Parameter) []Parameter {
+	names := make(map[string]bool, len(bound))
+	for _, param := range bound {
+		if param.In == TagPath {
+			names[param.Name] = true
+		}
+	}
+
+	var params []Parameter
+	for _, match := range pathVariablePattern.FindAllStringSubmatch(path, -1) {
+		if !names[match[1]] {
+			params = append(params, Parameter{Name: match[1], In: TagPath, Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+
+	return params
+}
+
+// swaggerUIAssets are the Swagger UI assets (see swagger-ui/NOTICE) which are embedded so that
+// the page neither depends upon, nor is served whatever a third party's CDN happens to hold
+//
+//go:embed swagger-ui/swagger-ui.css swagger-ui/swagger-ui-bundle.js
+var swaggerUIAssets embed.FS
+
+// swaggerUIAssetHandler returns an http.HandlerFunc that serves the named embedded Swagger UI asset
+func swaggerUIAssetHandler(name string) http.HandlerFunc {
+	asset, err := swaggerUIAssets.ReadFile(path.Join("swagger-ui", name))
+	if err != nil {
+		panic(err) // the asset is embedded so this cannot happen...
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(asset))
+	}
+}
+
+// swaggerUIHandler returns an http.HandlerFunc that serves a Swagger UI page for the document
+// at specPath... the Swagger UI assets themselves are served beneath assetsPath
+func swaggerUIHandler(title, assetsPath, specPath string) http.HandlerFunc {
+	page := fmt.Sprintf(swaggerUIPage, html.EscapeString(title), assetsPath, assetsPath, specPath)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(page))
+	}
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8"/>
+  <title>%s</title>
+  <link rel="stylesheet" href="%s/swagger-ui.css"/>
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="%s/swagger-ui-bundle.js"></script>
+  <script>window.onload = () => { window.ui = SwaggerUIBundle({url: "%s", dom_id: "#swagger-ui"}); };</script>
+</body>
+</html>
+`
@@ -0,0 +1,121 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/google/uuid"
+
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+)
+
+type account struct {
+	ID      uuid.UUID `json:"id"`
+	Owner   string    `json:"owner" validate:"required,min=3,max=64"`
+	Balance float64   `json:"balance" validate:"gte=0"`
+	Parent  *account  `json:"parent,omitempty"`
+}
+
+type updateAccountRequest struct {
+	ID     uuid.UUID `path:"id"`
+	DryRun bool      `query:"dryRun"`
+	Tenant string    `header:"X-Tenant" validate:"required"`
+	Owner  string    `json:"owner" validate:"required,oneof=alice bob"`
+	Tags   []string  `json:"tags" validate:"max=5"`
+	Secret string    `json:"-"`
+}
+
+func (h *HandlerTestSuite) TestOpenAPI_DescribesRequestHandlers() {
+	server := h.newServer(noop)
+
+	server.DefineRequestHandlerWithOptions("/accounts/{id:[0-9a-f-]+}", noop, updateAccountRequest{},
+		api.RouteMethods(http.MethodPut),
+		api.RouteOperationID("updateAccount"),
+		api.RouteSummary("Update an account"),
+		api.RouteTags("accounts"),
+		api.RouteResponse(http.StatusOK, account{}),
+		api.RouteResponse(http.StatusNoContent, nil),
+	)
+
+	doc := server.OpenAPI(api.OpenAPIInfo{Title: "accounts", Version: "1.0.0"})
+
+	h.Equal(api.OpenAPIVersion, doc.OpenAPI)
+	h.Contains(doc.Paths, "/test")
+
+	op := doc.Paths["/accounts/{id}"]["put"]
+	h.Require().NotNil(op)
+	h.Equal("updateAccount", op.OperationID)
+	h.Equal([]string{"accounts"}, op.Tags)
+
+	h.Equal([]api.Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &api.Schema{Type: "string", Format: "uuid"}},
+		{Name: "dryRun", In: "query", Schema: &api.Schema{Type: "boolean"}},
+		{Name: "X-Tenant", In: "header", Required: true, Schema: &api.Schema{Type: "string"}},
+	}, op.Parameters)
+
+	body := op.RequestBody.Content[api.ValueApplicationJson].Schema
+	h.Equal([]string{"owner"}, body.Required)
+	h.Equal([]any{"alice", "bob"}, body.Properties["owner"].Enum)
+	h.Equal(5, *body.Properties["tags"].MaxItems)
+	h.NotContains(body.Properties, "Secret")
+
+	h.Equal("#/components/schemas/account", op.Responses["200"].Content[api.ValueApplicationJson].Schema.Ref)
+	h.Nil(op.Responses["204"].Content)
+	h.Contains(op.Responses, "default")
+
+	component := doc.Components.Schemas["account"]
+	h.Equal(3, *component.Properties["owner"].MinLength)
+	h.Equal(0.0, *component.Properties["balance"].Minimum)
+	h.Equal("#/components/schemas/account", component.Properties["parent"].Ref)
+}
+
+func (h *HandlerTestSuite) TestOpenAPI_PathVariablesAreAlwaysParameters() {
+	server := h.newServer(noop)
+
+	server.DefineRequestHandler("/items/{id}/parts/{part:[0-9]+}", noop, nil, http.MethodGet)
+
+	doc := server.OpenAPI(api.OpenAPIInfo{Title: "items", Version: "1.0.0"})
+
+	op := doc.Paths["/items/{id}/parts/{part}"]["get"]
+	h.Require().NotNil(op)
+	h.Equal([]api.Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &api.Schema{Type: "string"}},
+		{Name: "part", In: "path", Required: true, Schema: &api.Schema{Type: "string"}},
+	}, op.Parameters)
+}
+
+func (h *HandlerTestSuite) TestOpenAPI_ServedAsJson() {
+	server := h.newServer(noop, api.WithOpenAPI(api.OpenAPIInfo{Title: "test", Version: "1"}), api.WithSwaggerUI("test", ""))
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, api.OpenAPIPath, nil))
+
+	var doc api.OpenAPIDocument
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.NoError(json.Unmarshal(rec.Body.Bytes(), &doc))
+	h.Contains(doc.Paths, "/test", "handlers defined after the option are included")
+
+	rec = httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, api.SwaggerUIPath, nil))
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.Contains(rec.Body.String(), api.OpenAPIPath)
+	h.NotContains(rec.Body.String(), "://", "the assets are not loaded from elsewhere")
+
+	for asset, ctype := range map[string]string{"swagger-ui.css": "text/css", "swagger-ui-bundle.js": "javascript"} {
+		rec = httptest.NewRecorder()
+		server.Api.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, api.SwaggerUIPath+"/"+asset, nil))
+
+		h.Equal(http.StatusOK, rec.Code, asset)
+		h.Contains(rec.Header().Get(api.HeaderContentType), ctype, asset)
+		h.Contains(rec.Body.String(), "swagger-ui", asset)
+	}
+}
+
+func noop(context.Context, *shared.ApplicationContext, any) (any, int) {
+	return nil, 0
+}
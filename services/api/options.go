@@ -11,6 +11,8 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/shared"
 	"github.com/djmarrerajr/common-lib/utils"
 )
 
@@ -29,6 +31,15 @@ func WithLogger(logger utils.Logger) Option {
 	}
 }
 
+// WithApplicationContext will assign the provided application context to the API... it
+// should precede any option whose middleware records metrics (i.e. WithRateLimit) so that
+// the application's Collector is known to the middleware as it is created
+func WithApplicationContext(appCtx shared.ApplicationContext) Option {
+	return func(s *Server) {
+		s.AppCtx = appCtx
+	}
+}
+
 // WithTimeoutDurationSecs will update the appropriate Timeout duration where
 // a positive integer value is provided
 func WithTimeoutDurationSecs(read, readHeader, write, idle int) Option {
@@ -127,6 +138,72 @@ func WithRequestMiddleware(fn mux.MiddlewareFunc) Option {
 	}
 }
 
+// WithErrorStatus will map the specified ErrorType to the HTTP status code that
+// should be returned whenever a request handler encounters an error of that type
+func WithErrorStatus(errType errs.ErrorType, status int) Option {
+	return func(s *Server) {
+		s.ErrorStatuses[errType] = status
+	}
+}
+
+// WithErrorStatusMap will merge the provided ErrorType->status mappings in to
+// those already known to the Server, replacing any that already exist
+func WithErrorStatusMap(statuses ErrorStatusMap) Option {
+	return func(s *Server) {
+		for errType, status := range statuses {
+			s.ErrorStatuses[errType] = status
+		}
+	}
+}
+
+// WithProblemDetails will cause all error responses to be returned as RFC 7807
+// problem details (application/problem+json or application/problem+xml) rather
+// than only doing so when the API caller explicitly asks for them
+//
+// If a typeBaseURI is provided it will be used to construct the problem 'type'
+// i.e. https://errors.example.com + Validation = https://errors.example.com/validation
+func WithProblemDetails(typeBaseURI string) Option {
+	return func(s *Server) {
+		s.problems = problemConfig{
+			enabled:     true,
+			typeBaseURI: typeBaseURI,
+		}
+	}
+}
+
+// WithCodec will register the Codec with the Server, replacing any existing Codec for
+// the same media type, so that it can be used to decode requests and encode responses
+func WithCodec(codec Codec) Option {
+	return func(s *Server) {
+		s.Codecs.Register(codec)
+	}
+}
+
+// WithOpenAPI will serve the OpenAPI document, generated from the Server's request handlers,
+// at /openapi.json... handlers defined after the Server is created are included
+func WithOpenAPI(info OpenAPIInfo) Option {
+	return func(s *Server) {
+		defineOrReplaceRoute(s, OpenAPIPath, s.openAPIHandler(info), http.MethodGet)
+	}
+}
+
+// WithSwaggerUI will serve a Swagger UI page, for the document served by WithOpenAPI, at the
+// specified path (or /docs if no path is provided) along with its assets, which are embedded
+// in the library, beneath it
+func WithSwaggerUI(title, path string) Option {
+	return func(s *Server) {
+		if path == "" {
+			path = SwaggerUIPath
+		}
+
+		for _, asset := range []string{"swagger-ui.css", "swagger-ui-bundle.js"} {
+			defineOrReplaceRoute(s, path+"/"+asset, swaggerUIAssetHandler(asset), http.MethodGet)
+		}
+
+		defineOrReplaceRoute(s, path, swaggerUIHandler(title, path, OpenAPIPath), http.MethodGet)
+	}
+}
+
 // WithPostShutdownCallback registers a function that will be invoked *after* the
 // Server shutdown has been completed
 func WithPostShutdownCallback(fn func()) Option {
@@ -138,6 +215,10 @@ func WithPostShutdownCallback(fn func()) Option {
 func defineOrReplaceRoute(s *Server, path string, handler http.HandlerFunc, methods ...string) {
 	var currRoute *mux.Route
 
+	if s.Api.Handler == nil {
+		s.Api.Handler = mux.NewRouter()
+	}
+
 	// because it is possible to override a default route handler we need to check if it exists and
 	// replace the handler because gorilla does not handle multiple route definitions well so we are
 	// updating any route definitions that match our path so that they have the same handler...
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator"
+
+	"github.com/djmarrerajr/common-lib/errs"
+
+	stderr "errors"
+)
+
+// ProblemDetails is an RFC 7807 representation of an error that can be returned
+// to the API caller as either application/problem+json or application/problem+xml
+type ProblemDetails struct {
+	XMLName xml.Name `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+
+	Type     string `json:"type"               xml:"type"`
+	Title    string `json:"title"              xml:"title"`
+	Status   int    `json:"status"             xml:"status"`
+	Detail   string `json:"detail,omitempty"   xml:"detail,omitempty"`
+	Instance string `json:"instance,omitempty" xml:"instance,omitempty"`
+
+	// extension members...
+	RequestId     string         `json:"requestId,omitempty"      xml:"requestId,omitempty"`
+	ErrorType     errs.ErrorType `json:"errorType,omitempty"      xml:"errorType,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty" xml:"invalid-params>i,omitempty"`
+}
+
+// InvalidParam describes a single request parameter that failed validation
+type InvalidParam struct {
+	Name   string `json:"name"   xml:"name"`
+	Reason string `json:"reason" xml:"reason"`
+}
+
+// problemConfig controls if, and how, RFC 7807 error responses are generated
+type problemConfig struct {
+	enabled     bool   // always respond with problem details (not just when asked)
+	typeBaseURI string // base URI used to construct the problem 'type'
+}
+
+// NewProblemDetails will construct the RFC 7807 representation of the provided error
+//
+// When no typeBaseURI is provided the problem type will be 'about:blank' (as defined
+// by the RFC) otherwise the type will be a URI derived from the errors ErrorType
+func NewProblemDetails(err error, status int, instance, reqID, typeBaseURI string) ProblemDetails {
+	errType := errs.GetType(err)
+
+	problem := ProblemDetails{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    err.Error(),
+		Instance:  instance,
+		RequestId: reqID,
+		ErrorType: errType,
+	}
+
+	if typeBaseURI != "" && errType != "" {
+		problem.Type = fmt.Sprintf("%s/%s", strings.TrimSuffix(typeBaseURI, "/"), kebabCase(string(errType)))
+		problem.Title = titleCase(string(errType))
+	}
+
+	var validationErrs validator.ValidationErrors
+	if stderr.As(err, &validationErrs) {
+		problem.Detail = "one or more request parameters failed validation"
+
+		for _, fieldErr := range validationErrs {
+			problem.InvalidParams = append(problem.InvalidParams, InvalidParam{
+				Name:   fieldName(fieldErr),
+				Reason: fieldReason(fieldErr),
+			})
+		}
+	}
+
+	return problem
+}
+
+// wantsProblemDetails determines whether or not the API caller should receive an
+// RFC 7807 response and, if so, the media type it should be encoded as... a caller that
+// asks for both receives the one it prefers, while one that excludes them (q=0) receives
+// neither even if they are enabled
+func (p problemConfig) wantsProblemDetails(r *http.Request, ctype string) (string, bool) {
+	ranges := parseAccept(r.Header.Get(HeaderAccept))
+
+	isXml := ctype == ValueApplicationXml || ctype == ValueTextXml
+	asksJson := requestedQuality(ranges, ValueProblemJson)
+	asksXml := requestedQuality(ranges, ValueProblemXml)
+
+	switch {
+	case asksXml > asksJson:
+		return ValueProblemXml, true
+	case asksJson > 0:
+		return ValueProblemJson, true
+	case p.enabled && isXml && !isExcluded(ranges, ValueProblemXml):
+		return ValueProblemXml, true
+	case p.enabled && !isExcluded(ranges, ValueProblemJson):
+		return ValueProblemJson, true
+	default:
+		return "", false
+	}
+}
+
+// requestedQuality returns the quality of the media range naming the media type, zero
+// should the caller not have asked for it by name
+func requestedQuality(ranges []mediaRange, mediaType string) float64 {
+	for _, rng := range ranges {
+		if rng.mediaType == mediaType {
+			return rng.quality
+		}
+	}
+
+	return 0
+}
+
+// marshalProblem will encode the problem details in the requested format
+func marshalProblem(mediaType string, problem ProblemDetails) ([]byte, error) {
+	if mediaType == ValueProblemXml {
+		return xml.Marshal(problem)
+	}
+
+	return json.Marshal(problem)
+}
+
+// fieldName returns the namespaced name of the offending field, minus the name of
+// the top-level struct, i.e. 'Account.Owner.Name' becomes 'Owner.Name'
+func fieldName(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+
+	if idx := strings.Index(namespace, "."); idx >= 0 {
+		return namespace[idx+1:]
+	}
+
+	return namespace
+}
+
+// fieldReason returns a human readable description of the failed validation
+func fieldReason(fieldErr validator.FieldError) string {
+	if fieldErr.Param() != "" {
+		return fmt.Sprintf("failed on the '%s=%s' validation", fieldErr.Tag(), fieldErr.Param())
+	}
+
+	return fmt.Sprintf("failed on the '%s' validation", fieldErr.Tag())
+}
+
+// kebabCase converts 'InvalidNumber' to 'invalid-number'
+func kebabCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "-"))
+}
+
+// titleCase converts 'InvalidNumber' to 'Invalid Number'
+func titleCase(s string) string {
+	return strings.Join(splitWords(s), " ")
+}
+
+// splitWords will split a CamelCased string in to its component words
+func splitWords(s string) []string {
+	var words []string
+	var curr []rune
+
+	for i, r := range s {
+		if i > 0 && unicode.IsUpper(r) && len(curr) > 0 {
+			words = append(words, string(curr))
+			curr = nil
+		}
+
+		curr = append(curr, r)
+	}
+
+	if len(curr) > 0 {
+		words = append(words, string(curr))
+	}
+
+	return words
+}
@@ -0,0 +1,31 @@
+package ratelimit
+
+import "time"
+
+// nolint: unused
+const (
+	DefaultSweepInterval = time.Minute
+)
+
+// nolint: unused
+const (
+	KeyByIP      = "ip"
+	KeyBySubject = "subject"
+)
+
+// nolint: unused
+const (
+	LimitEnvKey  = "RATE_LIMIT"        // i.e. 100/1m
+	BurstEnvKey  = "RATE_LIMIT_BURST"  // defaults to the number of requests
+	KeyEnvKey    = "RATE_LIMIT_KEY"    // ip (default) or subject
+	RoutesEnvKey = "RATE_LIMIT_ROUTES" // i.e. /login=5/1m,/orders/{id}=10/1s
+)
+
+// nolint: unused
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderPolicy     = "RateLimit-Policy"
+	HeaderRetryAfter = "Retry-After"
+)
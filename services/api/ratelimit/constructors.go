@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"strings"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// NewLimiterFromEnv will instantiate and return a Limiter using the default limit, burst,
+// client key and route limits named within the environment... only the limit is required
+func NewLimiterFromEnv(env utils.Environ, options ...Option) (*Limiter, error) {
+	value, err := env.GetRequired(LimitEnvKey)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := ParseLimit(value)
+	if err != nil {
+		return nil, err
+	}
+
+	burst, _, err := env.GetInt(BurstEnvKey)
+	if err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
+	}
+	limit.Burst = burst
+
+	newopt := []Option{}
+
+	key, _ := env.Get(KeyEnvKey)
+	switch key {
+	case "", KeyByIP:
+	case KeyBySubject:
+		newopt = append(newopt, WithKeyFunc(BySubject))
+	default:
+		return nil, errs.Errorf(errs.ErrTypeConfiguration, "invalid rate limit key '%s'", key)
+	}
+
+	if routes, OK := env.Get(RoutesEnvKey); OK && routes != "" {
+		for _, entry := range strings.Split(routes, ",") {
+			route, value, _ := strings.Cut(entry, "=")
+
+			routeLimit, err := ParseLimit(value)
+			if err != nil {
+				return nil, err
+			}
+
+			newopt = append(newopt, WithRouteLimit(strings.TrimSpace(route), routeLimit))
+		}
+	}
+
+	return NewLimiter(limit, append(newopt, options...)...), nil
+}
+
+// NewLimiter will instantiate and return a Limiter that applies the limit to each client,
+// identified by IP address, unless configured otherwise
+func NewLimiter(limit Limit, options ...Option) *Limiter {
+	limiter := Limiter{
+		store:  NewMemoryStore(),
+		key:    ByIP,
+		limit:  limit,
+		routes: make(map[string]Limit),
+		now:    time.Now,
+	}
+
+	for _, option := range options {
+		option(&limiter)
+	}
+
+	return &limiter
+}
@@ -0,0 +1,15 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Store holds the token bucket of each key... the MemoryStore is local to the process
+// whereas an implementation backed by a shared store (i.e. Redis) would allow the limits
+// to be enforced across every instance of a service
+type Store interface {
+	// Take removes a token from the key's bucket, if one is available, returning the
+	// state of the bucket afterwards
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// ByIP identifies the client by the IP address from which the request was received...
+// behind a proxy a KeyFunc using the (trusted) forwarding header should be used instead
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// BySubject identifies the client by the subject of its token (see api.WithAuthentication)
+// falling back to its IP address for requests that have not been authenticated
+func BySubject(r *http.Request) string {
+	if subject, _ := utils.GetFieldValueFromContext[string](r.Context(), shared.SubjectContextKey); subject != "" {
+		return "sub:" + subject
+	}
+
+	return ByIP(r)
+}
+
+// ByHeader identifies the client by the value of the header (i.e. an API key) falling
+// back to its IP address for requests without the header
+func ByHeader(name string) KeyFunc {
+	return func(r *http.Request) string {
+		if value := r.Header.Get(name); value != "" {
+			return name + ":" + value
+		}
+
+		return ByIP(r)
+	}
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Store = new(MemoryStore)
+
+// MemoryStore is a Store that holds the buckets in memory... buckets that have refilled
+// are periodically discarded so that the memory used is bounded by the number of active
+// clients rather than every client ever seen
+type MemoryStore struct {
+	mu sync.Mutex
+
+	buckets map[string]*bucket
+	limits  map[string]Limit
+	swept   time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		limits:  make(map[string]Limit),
+	}
+}
+
+func (m *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.swept) >= DefaultSweepInterval {
+		m.sweep(now)
+	}
+
+	b, exists := m.buckets[key]
+	if !exists {
+		b = &bucket{tokens: limit.capacity(), updated: now}
+		m.buckets[key] = b
+	}
+
+	m.limits[key] = limit
+
+	return b.take(limit, now), nil
+}
+
+// sweep discards those buckets that have refilled, as they are indistinguishable from new ones
+func (m *MemoryStore) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if b.full(m.limits[key], now) {
+			delete(m.buckets, key)
+			delete(m.limits, key)
+		}
+	}
+
+	m.swept = now
+}
@@ -0,0 +1,34 @@
+package ratelimit
+
+import "time"
+
+type Option func(*Limiter)
+
+// WithStore will replace the (in-memory) Store in which the buckets are held
+func WithStore(store Store) Option {
+	return func(l *Limiter) {
+		l.store = store
+	}
+}
+
+// WithKeyFunc will replace the function used to identify the client (ByIP by default)
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(l *Limiter) {
+		l.key = fn
+	}
+}
+
+// WithRouteLimit will apply the limit, rather than the default, to the route which is given
+// as either a path or a route template (i.e. /orders/{id})
+func WithRouteLimit(route string, limit Limit) Option {
+	return func(l *Limiter) {
+		l.routes[route] = limit
+	}
+}
+
+// WithClock will replace the function used to obtain the current time
+func WithClock(now func() time.Time) Option {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
@@ -0,0 +1,151 @@
+package ratelimit_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/api/ratelimit"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+type RateLimitTestSuite struct {
+	suite.Suite
+
+	now time.Time
+}
+
+func (l *RateLimitTestSuite) SetupTest() {
+	l.now = time.Unix(1700000000, 0)
+}
+
+func (l *RateLimitTestSuite) TestParseLimit() {
+	tests := []struct {
+		value string
+		limit ratelimit.Limit
+		valid bool
+	}{
+		{value: "100/1m", limit: ratelimit.Limit{Requests: 100, Period: time.Minute}, valid: true},
+		{value: "10/s", limit: ratelimit.Limit{Requests: 10, Period: time.Second}, valid: true},
+		{value: " 5/30s ", limit: ratelimit.Limit{Requests: 5, Period: 30 * time.Second}, valid: true},
+		{value: "100"},
+		{value: "0/1m"},
+		{value: "ten/1m"},
+		{value: "10/fortnight"},
+	}
+
+	for _, test := range tests {
+		l.Run(test.value, func() {
+			limit, err := ratelimit.ParseLimit(test.value)
+			if !test.valid {
+				l.Equal(errs.ErrTypeConfiguration, errs.GetType(err))
+				return
+			}
+
+			l.Require().NoError(err)
+			l.Equal(test.limit, limit)
+		})
+	}
+}
+
+func (l *RateLimitTestSuite) TestLimiter_TokenBucket() {
+	limiter := ratelimit.NewLimiter(ratelimit.Limit{Requests: 2, Period: time.Second, Burst: 3}, l.clock())
+	req := httptest.NewRequest("GET", "/", nil)
+
+	for i := 2; i >= 0; i-- {
+		result, err := limiter.Take(req, "/")
+		l.Require().NoError(err)
+		l.True(result.Allowed)
+		l.Equal(i, result.Remaining)
+	}
+
+	result, _ := limiter.Take(req, "/")
+	l.False(result.Allowed, "the burst has been exhausted")
+	l.Equal(500*time.Millisecond, result.RetryAfter)
+	l.Equal(1500*time.Millisecond, result.Reset)
+
+	l.now = l.now.Add(500 * time.Millisecond)
+
+	result, _ = limiter.Take(req, "/")
+	l.True(result.Allowed, "a token has been added")
+}
+
+func (l *RateLimitTestSuite) TestLimiter_KeysAndRoutesAreIndependent() {
+	limiter := ratelimit.NewLimiter(ratelimit.Limit{Requests: 1, Period: time.Minute},
+		ratelimit.WithRouteLimit("/login", ratelimit.Limit{Requests: 1, Period: time.Hour}),
+		l.clock(),
+	)
+
+	alice := httptest.NewRequest("GET", "/", nil)
+	alice.RemoteAddr = "10.0.0.1:1234"
+
+	bob := httptest.NewRequest("GET", "/", nil)
+	bob.RemoteAddr = "10.0.0.2:1234"
+
+	for _, route := range []string{"/", "/login"} {
+		result, _ := limiter.Take(alice, route)
+		l.True(result.Allowed)
+
+		result, _ = limiter.Take(bob, route)
+		l.True(result.Allowed)
+
+		result, _ = limiter.Take(alice, route)
+		l.False(result.Allowed)
+	}
+
+	result, _ := limiter.Take(alice, "/login")
+	l.Equal(time.Hour, result.Limit.Period)
+}
+
+func (l *RateLimitTestSuite) TestKeyFuncs() {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Api-Key", "abc")
+
+	l.Equal("10.0.0.1", ratelimit.ByIP(req))
+	l.Equal("10.0.0.1", ratelimit.BySubject(req))
+	l.Equal("X-Api-Key:abc", ratelimit.ByHeader("X-Api-Key")(req))
+
+	req = req.WithContext(utils.AddFieldToContext(req.Context(), shared.SubjectContextKey, "bruno"))
+	l.Equal("sub:bruno", ratelimit.BySubject(req))
+}
+
+func (l *RateLimitTestSuite) TestNewLimiterFromEnv() {
+	_, err := ratelimit.NewLimiterFromEnv(utils.NewEnviron(map[string]string{}))
+	l.Error(err, "a limit is required")
+
+	_, err = ratelimit.NewLimiterFromEnv(utils.NewEnviron(map[string]string{
+		ratelimit.LimitEnvKey: "10/s",
+		ratelimit.KeyEnvKey:   "cookie",
+	}))
+	l.Equal(errs.ErrTypeConfiguration, errs.GetType(err))
+
+	limiter, err := ratelimit.NewLimiterFromEnv(utils.NewEnviron(map[string]string{
+		ratelimit.LimitEnvKey:  "10/s",
+		ratelimit.BurstEnvKey:  "20",
+		ratelimit.KeyEnvKey:    ratelimit.KeyBySubject,
+		ratelimit.RoutesEnvKey: "/login=5/1m, /orders/{id}=1/s",
+	}), l.clock())
+	l.Require().NoError(err)
+
+	req := httptest.NewRequest("GET", "/", nil)
+
+	result, _ := limiter.Take(req, "/")
+	l.Equal(ratelimit.Limit{Requests: 10, Period: time.Second, Burst: 20}, result.Limit)
+	l.Equal(19, result.Remaining)
+
+	result, _ = limiter.Take(req, "/orders/{id}")
+	l.Equal(ratelimit.Limit{Requests: 1, Period: time.Second}, result.Limit)
+}
+
+func (l *RateLimitTestSuite) clock() ratelimit.Option {
+	return ratelimit.WithClock(func() time.Time { return l.now })
+}
+
+func TestRateLimit(t *testing.T) {
+	suite.Run(t, new(RateLimitTestSuite))
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+)
+
+// Limit allows a number of requests per period, any unused allowance accumulating up to
+// the burst (which, if not provided, is the number of requests)
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// ParseLimit parses a limit of the form <requests>/<period>, i.e. 100/1m or 10/s
+func ParseLimit(value string) (Limit, error) {
+	requests, period, OK := strings.Cut(strings.TrimSpace(value), "/")
+	if !OK {
+		return Limit{}, errs.Errorf(errs.ErrTypeConfiguration, "invalid rate limit '%s'", value)
+	}
+
+	count, err := strconv.Atoi(requests)
+	if err != nil || count <= 0 {
+		return Limit{}, errs.Errorf(errs.ErrTypeConfiguration, "invalid rate limit '%s'", value)
+	}
+
+	// allow the unit alone, i.e. 10/s rather than 10/1s
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return Limit{}, errs.Errorf(errs.ErrTypeConfiguration, "invalid rate limit '%s'", value)
+	}
+
+	return Limit{Requests: count, Period: duration}, nil
+}
+
+// capacity is the maximum number of tokens the bucket may hold
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+
+	return float64(l.Requests)
+}
+
+// rate is the number of tokens added to the bucket each second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Policy describes the limit as a RateLimit-Policy header value, i.e. 100;w=60
+func (l Limit) Policy() string {
+	return fmt.Sprintf("%d;w=%d", l.Requests, int(math.Ceil(l.Period.Seconds())))
+}
+
+// Result is the state of a key's bucket after a token has been taken from it
+type Result struct {
+	Allowed    bool
+	Limit      Limit
+	Remaining  int
+	Reset      time.Duration // until the bucket is once again full
+	RetryAfter time.Duration // until a token is available, if one was not
+}
+
+// KeyFunc identifies the client that made the request, requests having the same
+// key sharing the same limit
+type KeyFunc func(*http.Request) string
+
+// Limiter applies a default Limit to each client, or a specific Limit to those routes
+// that have one, using a token bucket held by its Store
+type Limiter struct {
+	store  Store
+	key    KeyFunc
+	limit  Limit
+	routes map[string]Limit
+	now    func() time.Time
+}
+
+// Take removes a token from the client's bucket for the route (a path or route template)
+func (l *Limiter) Take(r *http.Request, route string) (Result, error) {
+	limit, OK := l.routes[route]
+	if !OK {
+		limit = l.limit
+	}
+
+	// each route having its own limit also has its own buckets...
+	key := l.key(r)
+	if OK {
+		key = route + "|" + key
+	}
+
+	return l.store.Take(r.Context(), key, limit, l.now())
+}
+
+// bucket is a single token bucket
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills the bucket for the time elapsed since it was last updated and then removes
+// a token from it, if one is available
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	capacity, rate := limit.capacity(), limit.rate()
+
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+		b.updated = now
+	}
+
+	result := Result{Limit: limit}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+
+	return result
+}
+
+// full determines whether or not the bucket would have refilled by now
+func (b *bucket) full(limit Limit, now time.Time) bool {
+	return b.tokens+now.Sub(b.updated).Seconds()*limit.rate() >= limit.capacity()
+}
+
+func seconds(secs float64) time.Duration {
+	return time.Duration(secs * float64(time.Second))
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/api/ratelimit"
+	"github.com/djmarrerajr/common-lib/shared"
+)
+
+// WithRateLimit will add a middleware function that limits the rate at which each client
+// may make requests, i.e.
+//
+//	limiter, _ := ratelimit.NewLimiterFromEnv(env)
+//	api.WithRateLimit(limiter)
+//
+// Every response carries the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Policy headers while requests in excess of the limit are refused, along with a
+// Retry-After header, with an errs.ErrTypeRateLimited error and counted by the
+// 'rate_limited_total' metric.  The health and metrics routes are never limited.
+//
+// When authenticated clients are to be limited by subject (see ratelimit.BySubject) this
+// should be added after WithAuthentication.
+func WithRateLimit(limiter *ratelimit.Limiter, routesToSkip ...string) Option {
+	return func(s *Server) {
+		WithRequestMiddleware(rateLimitMiddleware(s, limiter, routesToSkip...))(s)
+	}
+}
+
+func rateLimitMiddleware(s *Server, limiter *ratelimit.Limiter, routesToSkip ...string) mux.MiddlewareFunc {
+	routes := newSkippedRoutes(routesToSkip...)
+	rejected := newDimensionedCounter(s.AppCtx, "rate_limited_total", "path")
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isSkipped(routes, r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := limiter.Take(r, routeTemplate(r))
+			if err != nil {
+				// an unavailable store should not take the service down with it...
+				s.Logger.WithCtx(r.Context()).Error("unable to apply rate limit", err)
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set(ratelimit.HeaderLimit, strconv.Itoa(result.Limit.Requests))
+			w.Header().Set(ratelimit.HeaderRemaining, strconv.Itoa(result.Remaining))
+			w.Header().Set(ratelimit.HeaderReset, ceilSeconds(result.Reset))
+			w.Header().Set(ratelimit.HeaderPolicy, result.Limit.Policy())
+
+			if result.Allowed {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			if rejected != nil {
+				rejected.WithLabelValues(append(shared.MetricFilterValues(s.AppCtx.RootCtx), metricPath(r))...).Inc()
+			}
+
+			w.Header().Set(ratelimit.HeaderRetryAfter, ceilSeconds(result.RetryAfter))
+
+			err = errs.Errorf(errs.ErrTypeRateLimited, "rate limit of %d requests per %s exceeded", result.Limit.Requests, result.Limit.Period)
+			returnMiddlewareError(s, &s.AppCtx, w, r, err, 0)
+		})
+	}
+}
+
+// ceilSeconds returns the duration as a whole number of seconds, rounded up
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
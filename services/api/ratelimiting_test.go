@@ -0,0 +1,56 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/services/api/ratelimit"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+func (h *HandlerTestSuite) TestRateLimit_ExcessRequestsAreRefused() {
+	collector, err := metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "test")
+	h.Require().NoError(err)
+
+	h.appctx.Collector = collector
+
+	limiter := ratelimit.NewLimiter(ratelimit.Limit{Requests: 2, Period: time.Minute})
+	server := h.newGreetingServer(api.WithRateLimit(limiter))
+
+	for i := 1; i >= 0; i-- {
+		rec := h.get(server, "")
+
+		h.Equal(http.StatusOK, rec.Code)
+		h.Equal("2", rec.Header().Get(ratelimit.HeaderLimit))
+		h.Equal(strconv.Itoa(i), rec.Header().Get(ratelimit.HeaderRemaining))
+		h.Equal("2;w=60", rec.Header().Get(ratelimit.HeaderPolicy))
+	}
+
+	rec := h.get(server, "")
+
+	h.Equal(http.StatusTooManyRequests, rec.Code)
+	h.Equal("30", rec.Header().Get(ratelimit.HeaderRetryAfter))
+	h.Equal(errs.ErrTypeRateLimited, h.decodeError(rec).Type)
+
+	scrape := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, api.MetricsPath, nil))
+
+	h.Contains(scrape.Body.String(), `test_rate_limited_total{appName="",appVersion="",environ="",hostname="",path="greeting"} 1`)
+}
+
+func (h *HandlerTestSuite) TestRateLimit_SkippedRoutes() {
+	limiter := ratelimit.NewLimiter(ratelimit.Limit{Requests: 1, Period: time.Minute})
+	server := h.newGreetingServer(api.WithRateLimit(limiter, "/greeting"))
+
+	for i := 0; i < 3; i++ {
+		rec := h.get(server, "")
+
+		h.Equal(http.StatusOK, rec.Code)
+		h.Empty(rec.Header().Get(ratelimit.HeaderLimit))
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gorilla/mux"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// WithPanicRecovery will add a middleware function that recovers from any panic raised
+// while handling a request and, rather than dropping the connection, returns a standardized
+// HTTP-500 error response to the API caller
+//
+// The panic (and its stack trace) is captured as an errs.ErrTypeInternal error which is
+// logged with the request's context, flagged on the request's span and counted by the
+// 'panics_total' metric (if the application has a metrics collector).  It should be added
+// after any tracing or metrics middleware so that they too observe the failed request.
+func WithPanicRecovery(appCtx shared.ApplicationContext) Option {
+	return func(s *Server) {
+		WithRequestMiddleware(recoveryMiddleware(s, appCtx))(s)
+	}
+}
+
+// recoveryMiddleware returns the middleware function added by WithPanicRecovery... the error
+// response is rendered using the Server's configuration as it is at the time of the request
+func recoveryMiddleware(s *Server, appCtx shared.ApplicationContext) mux.MiddlewareFunc {
+	var panicsByPath *metrics.DimensionedCounter
+	if appCtx.Collector != nil {
+		counter := appCtx.Collector.NewDimensionedCounter("panics_total", append(shared.MetricFilterLabels(), "path")...)
+		panicsByPath = &counter
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+
+				// the server itself relies upon this panic to abort a response...
+				if recErr, OK := rec.(error); OK && errors.Is(recErr, http.ErrAbortHandler) {
+					panic(rec)
+				}
+
+				err := errs.Errorf(errs.ErrTypeInternal, "panic: %v", rec)
+
+				appCtx.Logger.WithCtx(utils.AddMapToContext(r.Context(), utils.GetFieldMapFromContext(appCtx.RootCtx))).Errorw("recovered from panic", "panic", fmt.Sprint(rec), "stack", string(debug.Stack()))
+
+				if span := opentracing.SpanFromContext(r.Context()); span != nil {
+					ext.Error.Set(span, true)
+					span.LogKV("event", "panic", "message", fmt.Sprint(rec))
+				}
+
+				if panicsByPath != nil {
+					panicsByPath.WithLabelValues(append(shared.MetricFilterValues(appCtx.RootCtx), metricPath(r))...).Inc()
+				}
+
+				// nothing more can be done if the handler had already started its response...
+				if mw, OK := findMetricsWriter(w); OK && mw.code != 0 {
+					mw.errorType = errs.ErrTypeInternal
+					return
+				}
+
+				returnMiddlewareError(s, &appCtx, w, r, err, http.StatusInternalServerError)
+			}()
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+func (h *HandlerTestSuite) TestRecovery_PanicReturnsInternalError() {
+	collector, err := metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "test")
+	h.Require().NoError(err)
+
+	h.appctx.Collector = collector
+
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		panic("something went horribly wrong")
+	}, api.WithRequestMiddleware(api.MetricsMiddleware(h.appctx)), api.WithPanicRecovery(h.appctx))
+
+	rec := h.serve(server, `{"name":"bruno"}`)
+
+	h.Equal(http.StatusInternalServerError, rec.Code)
+
+	resp := h.decodeError(rec)
+	h.Equal(errs.ErrTypeInternal, resp.Type)
+	h.Contains(resp.Description, "something went horribly wrong")
+
+	scrape := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, api.MetricsPath, nil))
+
+	h.Contains(scrape.Body.String(), `test_panics_total{`)
+	h.Contains(scrape.Body.String(), `test_response_errors{appName="",appVersion="",environ="",errorType="Internal",hostname="",path="test"} 1`)
+}
+
+func (h *HandlerTestSuite) TestRecovery_ProblemDetailsWhenRequested() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		panic("boom")
+	}, api.WithPanicRecovery(h.appctx))
+
+	rec := h.serve(server, `{"name":"bruno"}`, api.ValueProblemJson)
+
+	h.Equal(http.StatusInternalServerError, rec.Code)
+	h.Equal(api.ValueProblemJson, rec.Header().Get(api.HeaderContentType))
+	h.Equal(http.StatusInternalServerError, h.decodeProblem(rec).Status)
+}
+
+func (h *HandlerTestSuite) TestRecovery_AbortHandlerIsNotRecovered() {
+	server := h.newServer(func(context.Context, *shared.ApplicationContext, any) (any, int) {
+		panic(http.ErrAbortHandler)
+	}, api.WithPanicRecovery(h.appctx))
+
+	h.PanicsWithValue(http.ErrAbortHandler, func() {
+		h.serve(server, `{"name":"bruno"}`)
+	})
+}
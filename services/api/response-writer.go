@@ -1,15 +1,20 @@
 package api
 
 import (
+	"bufio"
+	"net"
 	"net/http"
 
 	"github.com/djmarrerajr/common-lib/errs"
 )
 
+// metricsResponseWriter wraps the underlying http.ResponseWriter so that the
+// status code, error type and size of each response can be captured for metrics
 type metricsResponseWriter struct {
 	writer    http.ResponseWriter
 	code      int
 	errorType errs.ErrorType
+	written   int
 }
 
 func (m *metricsResponseWriter) Header() http.Header {
@@ -17,10 +22,83 @@ func (m *metricsResponseWriter) Header() http.Header {
 }
 
 func (m *metricsResponseWriter) WriteHeader(statusCode int) {
-	m.code = statusCode
+	if m.code == 0 {
+		m.code = statusCode
+	}
+
 	m.writer.WriteHeader(statusCode)
 }
 
 func (m *metricsResponseWriter) Write(data []byte) (int, error) {
-	return m.writer.Write(data)
+	// an implicit WriteHeader(http.StatusOK) occurs when writing without one
+	if m.code == 0 {
+		m.code = http.StatusOK
+	}
+
+	n, err := m.writer.Write(data)
+	m.written += n
+
+	return n, err
+}
+
+// Flush sends any buffered data to the API caller, should the underlying
+// http.ResponseWriter support it
+func (m *metricsResponseWriter) Flush() {
+	if flusher, OK := m.writer.(http.Flusher); OK {
+		if m.code == 0 {
+			m.code = http.StatusOK
+		}
+
+		flusher.Flush()
+	}
+}
+
+// Hijack allows the handler to take over the connection, should the underlying
+// http.ResponseWriter support it (i.e. to upgrade it to a WebSocket)
+func (m *metricsResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, OK := m.writer.(http.Hijacker)
+	if !OK {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err == nil && m.code == 0 {
+		m.code = http.StatusSwitchingProtocols
+	}
+
+	return conn, rw, err
+}
+
+// Unwrap allows an http.ResponseController to reach the underlying http.ResponseWriter
+func (m *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return m.writer
+}
+
+// StatusCode returns the status code that was written to the wire, if nothing
+// has been written yet the implicit status of HTTP-200 is returned
+func (m *metricsResponseWriter) StatusCode() int {
+	if m.code == 0 {
+		return http.StatusOK
+	}
+
+	return m.code
+}
+
+// findMetricsWriter returns the metricsResponseWriter wrapped by the http.ResponseWriter,
+// should there be one, looking beneath any other writers that wrap it (i.e. compression)
+func findMetricsWriter(w http.ResponseWriter) (*metricsResponseWriter, bool) {
+	for w != nil {
+		if mw, OK := w.(*metricsResponseWriter); OK {
+			return mw, true
+		}
+
+		unwrapper, OK := w.(interface{ Unwrap() http.ResponseWriter })
+		if !OK {
+			break
+		}
+
+		w = unwrapper.Unwrap()
+	}
+
+	return nil, false
 }
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/services/api/auth"
+)
+
+// RouteOption is used to configure an individual route (see RouteMethods et al.)
+type RouteOption func(*RouteConfig)
+
+// RouteConfig describes an individual route, beyond its path and handler, and is
+// populated by the RouteOption(s) provided when the route is defined
+type RouteConfig struct {
+	Methods []string
+
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+	Responses   map[int]any // the type of the body returned with each status, nil if none
+
+	Scopes   []string                          // every one of which the caller must have been granted
+	Roles    []string                          // at least one of which the caller must hold
+	Policies []func(ctx context.Context) error // each of which must permit the request
+
+	Timeout      time.Duration // the deadline within which the handler must respond, if not zero
+	MaxBodySize  int64         // the size of the largest request body accepted, if not zero
+	StrictJson   bool          // whether a JSON request body may contain unknown fields
+	WriteTimeout time.Duration // overrides the Server's write timeout, if not zero
+	Streams      []string      // the media types a stream handler may produce
+	Heartbeat    time.Duration // the interval between a stream's heartbeats, or a WebSocket's pings
+
+	MaxMessageSize int64 // the largest message a WebSocket handler will accept
+}
+
+// RouteMethods restricts the route to the specified HTTP methods
+func RouteMethods(methods ...string) RouteOption {
+	return func(c *RouteConfig) {
+		c.Methods = append(c.Methods, methods...)
+	}
+}
+
+// RouteOperationID sets the (unique) identifier of the route within the OpenAPI document
+func RouteOperationID(id string) RouteOption {
+	return func(c *RouteConfig) {
+		c.OperationID = id
+	}
+}
+
+// RouteSummary sets the summary and, optionally, a longer description of the route
+func RouteSummary(summary string, description ...string) RouteOption {
+	return func(c *RouteConfig) {
+		c.Summary = summary
+		if len(description) > 0 {
+			c.Description = description[0]
+		}
+	}
+}
+
+// RouteTags groups the route, within the OpenAPI document, with others having the same tag
+func RouteTags(tags ...string) RouteOption {
+	return func(c *RouteConfig) {
+		c.Tags = append(c.Tags, tags...)
+	}
+}
+
+// RouteDeprecated marks the route as deprecated
+func RouteDeprecated() RouteOption {
+	return func(c *RouteConfig) {
+		c.Deprecated = true
+	}
+}
+
+// RouteResponse declares the type of the body returned with the status, i.e.
+//
+//	api.RouteResponse(http.StatusOK, Account{})
+//	api.RouteResponse(http.StatusNoContent, nil)
+func RouteResponse(status int, body any) RouteOption {
+	return func(c *RouteConfig) {
+		if c.Responses == nil {
+			c.Responses = make(map[int]any)
+		}
+		c.Responses[status] = body
+	}
+}
+
+// RouteScopes requires that the caller's token has been granted every one of the scopes
+func RouteScopes(scopes ...string) RouteOption {
+	return func(c *RouteConfig) {
+		c.Scopes = append(c.Scopes, scopes...)
+	}
+}
+
+// RouteRoles requires that the caller's token holds at least one of the roles
+func RouteRoles(roles ...string) RouteOption {
+	return func(c *RouteConfig) {
+		c.Roles = append(c.Roles, roles...)
+	}
+}
+
+// RoutePolicy requires that the policy permits the request, an untyped error being treated
+// as an errs.ErrTypeAuthorization error, i.e.
+//
+//	api.RoutePolicy(func(ctx context.Context, claims auth.Claims) error {
+//		if claims.String("tenant") != "acme" {
+//			return errors.New("only acme may place orders")
+//		}
+//		return nil
+//	})
+func RoutePolicy(policy auth.Policy) RouteOption {
+	return func(c *RouteConfig) {
+		c.Policies = append(c.Policies, func(ctx context.Context) error {
+			claims, _ := auth.ClaimsFromContext(ctx)
+			return policy(ctx, claims)
+		})
+	}
+}
+
+// RouteTimeout sets the deadline within which the handler must respond, which is applied to
+// the context given to the handler... a handler that returns the context's error once the
+// deadline has passed has it reported as an errs.ErrTypeTimeout error (HTTP-408), while one
+// that completes regardless has its response sent
+func RouteTimeout(timeout time.Duration) RouteOption {
+	return func(c *RouteConfig) {
+		c.Timeout = timeout
+	}
+}
+
+// RouteMaxBodySize sets the size (in bytes) of the largest request body the route will accept,
+// a larger body being refused with an errs.ErrTypeRequestTooLarge error (HTTP-413)
+func RouteMaxBodySize(size int64) RouteOption {
+	return func(c *RouteConfig) {
+		c.MaxBodySize = size
+	}
+}
+
+// RouteStrictJson causes a JSON request body that contains any field unknown to the request
+// struct to be refused with an errs.ErrTypeUnmarshal error (HTTP-400)
+func RouteStrictJson() RouteOption {
+	return func(c *RouteConfig) {
+		c.StrictJson = true
+	}
+}
+
+// RouteWriteTimeout overrides the Server's write timeout for the route, i.e. to permit a
+// lengthy download... a stream handler has no write timeout unless one is given, while a
+// WebSocket handler applies it to the sending of each message
+func RouteWriteTimeout(timeout time.Duration) RouteOption {
+	return func(c *RouteConfig) {
+		c.WriteTimeout = timeout
+	}
+}
+
+// RouteStreams sets the media types that a stream handler may produce, in order of preference,
+// which defaults to text/event-stream and application/x-ndjson... any other media type (i.e.
+// text/csv) is streamed as-is
+func RouteStreams(mediaTypes ...string) RouteOption {
+	return func(c *RouteConfig) {
+		c.Streams = append(c.Streams, mediaTypes...)
+	}
+}
+
+// RouteHeartbeat sets the interval at which a stream handler's idle stream is sent a heartbeat,
+// which defaults to DefaultStreamHeartbeat, or a WebSocket is pinged, which defaults to
+// DefaultWebSocketPingInterval... a negative interval disables heartbeats
+func RouteHeartbeat(interval time.Duration) RouteOption {
+	return func(c *RouteConfig) {
+		c.Heartbeat = interval
+	}
+}
+
+// RouteMaxMessageSize sets the size (in bytes) of the largest message a WebSocket handler will
+// accept, which defaults to DefaultWebSocketMaxMessageSize... the WebSocket of an API caller
+// that sends a larger message is closed
+func RouteMaxMessageSize(size int64) RouteOption {
+	return func(c *RouteConfig) {
+		c.MaxMessageSize = size
+	}
+}
+
+// newRouteConfig applies each of the options to an empty RouteConfig
+func newRouteConfig(options ...RouteOption) RouteConfig {
+	config := RouteConfig{}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return config
+}
+
+// route is a request handler known to the Server, retained so that the OpenAPI document
+// can be generated from it
+type route struct {
+	path      string
+	reqStruct any
+	config    RouteConfig
+}
+
+// routeRegistry holds the request handlers, in the order they were defined
+type routeRegistry struct {
+	mu     sync.RWMutex
+	routes []route
+}
+
+// add will record the route, replacing any previously defined for the same path
+func (r *routeRegistry) add(rt route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for idx := range r.routes {
+		if r.routes[idx].path == rt.path {
+			r.routes[idx] = rt
+			return
+		}
+	}
+
+	r.routes = append(r.routes, rt)
+}
+
+// lookup returns the route defined for the path (template)
+func (r *routeRegistry) lookup(path string) (route, bool) {
+	if r == nil {
+		return route{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rt := range r.routes {
+		if rt.path == path {
+			return rt, true
+		}
+	}
+
+	return route{}, false
+}
+
+// list returns a copy of the routes
+func (r *routeRegistry) list() []route {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]route(nil), r.routes...)
+}
@@ -0,0 +1,435 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// StreamHandlerFunc is a request handler that sends its response incrementally, using the
+// Stream, rather than returning it... it should return once the response is complete, or
+// the API caller has disconnected (at which point the context is done)
+type StreamHandlerFunc func(context.Context, *shared.ApplicationContext, any, *Stream) error
+
+// Event is a single Server-Sent Event
+type Event struct {
+	ID    string        // becomes the Last-Event-ID of a reconnecting API caller
+	Event string        // the type of the event, 'message' if omitted
+	Data  any           // sent as-is if a string or []byte, otherwise encoded as JSON
+	Retry time.Duration // how long the API caller should wait before reconnecting
+}
+
+// DefineStreamHandler operates like DefineRequestHandlerWithOptions except that the handler
+// sends its response using a Stream, i.e.
+//
+//	server.DefineStreamHandler("/prices", func(ctx context.Context, appCtx *shared.ApplicationContext, req any, stream *api.Stream) error {
+//		for price := range prices(ctx, stream.LastEventID()) {
+//			if err := stream.SendEvent(api.Event{ID: price.ID, Data: price}); err != nil {
+//				return err
+//			}
+//		}
+//		return nil
+//	}, nil)
+//
+// The media type of the stream is negotiated from the Accept header and the RouteStreams, and
+// the route is restricted to GET requests unless other RouteMethods are given.
+func (s Server) DefineStreamHandler(path string, handler StreamHandlerFunc, reqStruct any, options ...RouteOption) {
+	config := newRouteConfig(options...)
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodGet}
+	}
+
+	if s.routes != nil {
+		s.routes.add(route{path, reqStruct, config})
+	}
+
+	ctxHandler := ContextualHandler{
+		ApplicationContext: &s.AppCtx,
+		ErrorStatuses:      s.ErrorStatuses,
+		Codecs:             s.Codecs,
+		problems:           s.problems,
+		config:             config,
+		denials:            newDenialCounter(s.AppCtx, config),
+		streamFunc:         handler,
+		any:                reqStruct,
+	}
+
+	defineOrReplaceRoute(&s, path, ctxHandler.ServeHTTP, config.Methods...)
+}
+
+// serveStream will negotiate the media type of the stream and, once the request has been
+// authorized, unmarshalled and validated, invoke the stream handler
+//
+// Any error returned by the handler before it has sent anything is returned to the API caller
+// as it would be by any other handler... thereafter the response has begun and the error
+// can only be logged (and, for Server-Sent Events, sent as an 'error' event).
+func (h ContextualHandler) serveStream(w http.ResponseWriter, r *http.Request, reqCtx, spanCtx context.Context, ctype string) {
+	w.Header().Add(HeaderVary, HeaderAccept)
+
+	// errors are encoded using the Codecs as a stream's media type will have none...
+	codec, OK := h.codecs().Negotiate(r.Header.Get(HeaderAccept), ValueApplicationJson)
+	if !OK {
+		codec = defaultCodec
+	}
+
+	mediaType, OK := negotiateStream(r.Header.Get(HeaderAccept), h.config.Streams)
+	if !OK {
+		err := errs.Errorf(errs.ErrTypeNotAcceptable, "unable to produce any of: %s", r.Header.Get(HeaderAccept))
+		h.returnErrorResponse(w, r, reqCtx, codec, err, 0)
+		return
+	}
+
+	data, err := h.requestData(reqCtx, ctype, r)
+	if err != nil {
+		h.returnErrorResponse(w, r, reqCtx, codec, err, 0)
+		return
+	}
+
+	stream := newStream(r.Context(), w, mediaType, r.Header.Get(HeaderLastEventID))
+
+	heartbeat := h.config.Heartbeat
+	if heartbeat == 0 {
+		heartbeat = DefaultStreamHeartbeat
+	}
+
+	stop := stream.heartbeat(heartbeat)
+	err = h.streamFunc(spanCtx, h.ApplicationContext, data, stream)
+	stop()
+
+	switch {
+	case err == nil:
+		// a stream to which nothing was sent is still a (successful) response...
+		if err = stream.begin(); err != nil && r.Context().Err() == nil {
+			h.Logger.WithCtx(reqCtx).Error("error writing response", err)
+		}
+	case !stream.began():
+		h.returnErrorResponse(w, r, reqCtx, codec, errs.WithTypeFallback(err, errs.ErrTypeUnknown), 0)
+	case r.Context().Err() != nil:
+		// the API caller has disconnected, which is how most streams end...
+	default:
+		h.streamError(w, reqCtx, stream, errs.WithTypeFallback(err, errs.ErrTypeUnknown))
+	}
+}
+
+// streamError will log an error returned by a stream handler once its response had begun
+// and, if the stream is of Server-Sent Events, send it as an 'error' event
+func (h ContextualHandler) streamError(w http.ResponseWriter, reqCtx context.Context, stream *Stream, err error) {
+	h.Logger.WithCtx(reqCtx).Error("error streaming response", err)
+
+	errType := errs.GetType(err)
+	if mw, OK := findMetricsWriter(w); OK {
+		mw.errorType = errType
+	}
+
+	if stream.MediaType() != ValueEventStream {
+		return
+	}
+
+	reqID, _ := utils.GetFieldValueFromContext[string](reqCtx, shared.RequestIdContextKey)
+
+	// nolint: errcheck
+	stream.SendEvent(Event{Event: "error", Data: ErrorResponse{
+		RequestId:   reqID,
+		Type:        errType,
+		Code:        h.ErrorStatuses.StatusFor(err),
+		Description: err.Error(),
+	}})
+}
+
+// Stream is used by a StreamHandlerFunc to send its response, as Server-Sent Events,
+// newline-delimited JSON or (for any other media type) as-is, each part of the response
+// being flushed to the API caller as soon as it is sent
+//
+// A Stream is safe for concurrent use, and refuses anything sent once the API caller has
+// disconnected by returning the error of the request's context.
+type Stream struct {
+	ctx         context.Context
+	writer      http.ResponseWriter
+	controller  *http.ResponseController
+	mediaType   string
+	lastEventID string
+
+	mu      sync.Mutex
+	started bool
+}
+
+func newStream(ctx context.Context, w http.ResponseWriter, mediaType, lastEventID string) *Stream {
+	return &Stream{
+		ctx:         ctx,
+		writer:      w,
+		controller:  http.NewResponseController(w),
+		mediaType:   mediaType,
+		lastEventID: lastEventID,
+	}
+}
+
+// MediaType returns the negotiated media type of the stream, i.e. text/event-stream
+func (s *Stream) MediaType() string {
+	return s.mediaType
+}
+
+// LastEventID returns the ID of the last event received by an API caller that is
+// reconnecting, so that it may be sent any events it has missed
+func (s *Stream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Header returns the response headers which may be changed, i.e. to set the
+// Content-Disposition of a download, until the first part of the response is sent
+func (s *Stream) Header() http.Header {
+	return s.writer.Header()
+}
+
+// Done returns a channel that is closed once the API caller has disconnected
+func (s *Stream) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Send will send the value as a Server-Sent Event's data, as a line of JSON or, for any
+// other media type, as-is (in which case it must be a string, []byte or io.Reader)
+func (s *Stream) Send(v any) error {
+	switch s.mediaType {
+	case ValueEventStream:
+		return s.SendEvent(Event{Data: v})
+	case ValueNDJson:
+		var buff bytes.Buffer
+
+		if err := json.NewEncoder(&buff).Encode(v); err != nil {
+			return errs.WithType(err, errs.ErrTypeMarshal)
+		}
+
+		return s.send(buff.Bytes())
+	}
+
+	switch v := v.(type) {
+	case string:
+		return s.send([]byte(v))
+	case []byte:
+		return s.send(v)
+	case io.Reader:
+		_, err := io.Copy(s, v)
+		return err
+	default:
+		return errs.Errorf(errs.ErrTypeMarshal, "unable to stream %T as %s", v, s.mediaType)
+	}
+}
+
+// SendEvent will send the Server-Sent Event, which is only possible should the stream
+// be of text/event-stream
+func (s *Stream) SendEvent(event Event) error {
+	if s.mediaType != ValueEventStream {
+		return errs.Errorf(errs.ErrTypeInternal, "unable to send an event on a stream of %s", s.mediaType)
+	}
+
+	var data string
+
+	switch v := event.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return errs.WithType(err, errs.ErrTypeMarshal)
+		}
+		data = string(encoded)
+	}
+
+	var buff bytes.Buffer
+
+	if event.ID != "" {
+		fmt.Fprintf(&buff, "id: %s\n", singleLine(event.ID))
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&buff, "event: %s\n", singleLine(event.Event))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&buff, "retry: %d\n", event.Retry.Milliseconds())
+	}
+
+	// each line of the data is sent as a field of its own...
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		fmt.Fprintf(&buff, "data: %s\n", line)
+	}
+
+	buff.WriteString("\n")
+
+	return s.send(buff.Bytes())
+}
+
+// Write will send the bytes as-is, allowing the Stream to be used as an io.Writer
+func (s *Stream) Write(p []byte) (int, error) {
+	if err := s.send(p); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// send will write, and flush, the bytes having first begun the response if necessary
+func (s *Stream) send(p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	s.start()
+
+	if _, err := s.writer.Write(p); err != nil {
+		return err
+	}
+
+	return s.flush()
+}
+
+// begin will begin the response, should nothing yet have been sent
+func (s *Stream) begin() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return nil
+	}
+
+	s.start()
+
+	return s.flush()
+}
+
+func (s *Stream) began() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.started
+}
+
+// start will write the response headers, the Stream's lock being held
+func (s *Stream) start() {
+	if s.started {
+		return
+	}
+
+	s.started = true
+
+	header := s.writer.Header()
+	if header.Get(HeaderContentType) == "" {
+		header.Set(HeaderContentType, s.mediaType)
+	}
+
+	header.Set(HeaderCacheControl, "no-cache")
+	header.Del(HeaderContentLength)
+
+	if s.mediaType == ValueEventStream {
+		// prevent proxies (i.e. nginx) from buffering the events...
+		header.Set("X-Accel-Buffering", "no")
+	}
+
+	s.writer.WriteHeader(http.StatusOK)
+}
+
+func (s *Stream) flush() error {
+	if err := s.controller.Flush(); err != nil && err != http.ErrNotSupported {
+		return err
+	}
+
+	return nil
+}
+
+// heartbeat will, until stopped, periodically send a comment (or a blank line of JSON) on
+// an idle stream so that it is not closed by any proxy between us and the API caller
+func (s *Stream) heartbeat(interval time.Duration) (stop func()) {
+	var beat []byte
+
+	switch s.mediaType {
+	case ValueEventStream:
+		beat = []byte(":\n\n")
+	case ValueNDJson:
+		beat = []byte("\n")
+	}
+
+	if interval <= 0 || beat == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.send(beat); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// the heartbeat must have ceased before the handler returns, at which point the
+	// response may no longer be written...
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// negotiateStream returns the media type, of those the route may produce, that best
+// matches the Accept header
+func negotiateStream(accept string, mediaTypes []string) (string, bool) {
+	if len(mediaTypes) == 0 {
+		mediaTypes = DefaultStreamTypes
+	}
+
+	ranges := parseAccept(accept)
+
+	for _, rng := range ranges {
+		if rng.quality == 0 {
+			continue
+		}
+
+		for _, mediaType := range mediaTypes {
+			mediaType = strings.ToLower(mediaType)
+			if isExcluded(ranges, mediaType) {
+				continue
+			}
+
+			switch {
+			case rng.mediaType == "*/*", rng.mediaType == mediaType:
+				return mediaType, true
+			case strings.HasSuffix(rng.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(rng.mediaType, "*")):
+				return mediaType, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// singleLine removes any line breaks, which would otherwise corrupt an event's fields
+func singleLine(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,170 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+func (h *HandlerTestSuite) TestStream_ServerSentEvents() {
+	h.appctx.Collector, _ = metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "test")
+
+	server := h.newGreetingServer(api.WithRequestMiddleware(api.MetricsMiddleware(h.appctx)))
+
+	server.DefineStreamHandler("/events", func(_ context.Context, _ *shared.ApplicationContext, _ any, stream *api.Stream) error {
+		_ = stream.SendEvent(api.Event{ID: "2", Event: "greeting", Data: greeting{"hello"}})
+		_ = stream.SendEvent(api.Event{ID: "3", Data: "multi\nline", Retry: time.Second})
+
+		return stream.Send("resumed after " + stream.LastEventID())
+	}, nil, api.RouteMethods(http.MethodGet))
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set(api.HeaderAccept, api.ValueEventStream)
+	req.Header.Set(api.HeaderLastEventID, "1")
+
+	rec := httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.Equal(api.ValueEventStream, rec.Header().Get(api.HeaderContentType))
+	h.Equal("no-cache", rec.Header().Get(api.HeaderCacheControl))
+	h.True(rec.Flushed)
+	h.Equal("id: 2\nevent: greeting\ndata: {\"message\":\"hello\"}\n\n"+
+		"id: 3\nretry: 1000\ndata: multi\ndata: line\n\n"+
+		"data: resumed after 1\n\n", rec.Body.String())
+}
+
+func (h *HandlerTestSuite) TestStream_NegotiatedMediaType() {
+	server := h.newGreetingServer()
+
+	server.DefineStreamHandler("/records", func(_ context.Context, _ *shared.ApplicationContext, _ any, stream *api.Stream) error {
+		if stream.MediaType() == "text/csv" {
+			stream.Header().Set("Content-Disposition", `attachment; filename="greetings.csv"`)
+			return stream.Send(strings.NewReader("message\nhello\nbonjour\n"))
+		}
+
+		for _, message := range []string{"hello", "bonjour"} {
+			if err := stream.Send(greeting{message}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, nil, api.RouteStreams(api.ValueNDJson, "text/csv"))
+
+	tests := []struct {
+		accept    string
+		status    int
+		mediaType string
+		body      string
+	}{
+		{accept: "", status: http.StatusOK, mediaType: api.ValueNDJson, body: "{\"message\":\"hello\"}\n{\"message\":\"bonjour\"}\n"},
+		{accept: "text/*", status: http.StatusOK, mediaType: "text/csv", body: "message\nhello\nbonjour\n"},
+		{accept: api.ValueEventStream, status: http.StatusNotAcceptable},
+	}
+
+	for _, test := range tests {
+		h.Run(test.accept, func() {
+			rec := h.get(server, test.accept, "/records")
+
+			h.Equal(test.status, rec.Code)
+			if test.status == http.StatusOK {
+				h.Equal(test.mediaType, rec.Header().Get(api.HeaderContentType))
+				h.Equal(test.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func (h *HandlerTestSuite) TestStream_Errors() {
+	server := h.newGreetingServer()
+
+	server.DefineStreamHandler("/events", func(_ context.Context, _ *shared.ApplicationContext, _ any, stream *api.Stream) error {
+		if stream.LastEventID() == "" {
+			return errs.New(errs.ErrTypeNotFound, "no such stream")
+		}
+
+		_ = stream.Send("first")
+
+		return errors.New("upstream went away")
+	}, nil)
+
+	// an error returned before anything is sent is returned as usual...
+	rec := h.get(server, api.ValueEventStream, "/events")
+
+	h.Equal(http.StatusNotFound, rec.Code)
+	h.Equal(errs.ErrTypeNotFound, h.decodeError(rec).Type)
+
+	// whereas one returned afterwards becomes an event
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set(api.HeaderAccept, api.ValueEventStream)
+	req.Header.Set(api.HeaderLastEventID, "1")
+
+	rec = httptest.NewRecorder()
+	server.Api.Handler.ServeHTTP(rec, req)
+
+	h.Equal(http.StatusOK, rec.Code)
+	h.Contains(rec.Body.String(), "data: first\n\nevent: error\ndata: {")
+	h.Contains(rec.Body.String(), `"error":"upstream went away"`)
+}
+
+func (h *HandlerTestSuite) TestStream_HeartbeatsAndDisconnects() {
+	disconnected := make(chan struct{})
+
+	server := h.newGreetingServer()
+	server.DefineStreamHandler("/events", func(ctx context.Context, _ *shared.ApplicationContext, _ any, stream *api.Stream) error {
+		// outlive the server's write timeout before sending anything...
+		time.Sleep(100 * time.Millisecond)
+		_ = stream.Send("late")
+
+		<-ctx.Done()
+		close(disconnected)
+
+		return ctx.Err()
+	}, nil, api.RouteHeartbeat(10*time.Millisecond))
+
+	live := httptest.NewUnstartedServer(server.Api.Handler)
+	live.Config.WriteTimeout = 50 * time.Millisecond
+	live.Start()
+	defer live.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, live.URL+"/events", nil)
+	req.Header.Set(api.HeaderAccept, api.ValueEventStream)
+
+	resp, err := http.DefaultClient.Do(req)
+	h.Require().NoError(err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+
+	line, err := reader.ReadString('\n')
+	h.Require().NoError(err)
+	h.Equal(":\n", line)
+
+	for !strings.HasPrefix(line, "data:") {
+		line, err = reader.ReadString('\n')
+		h.Require().NoError(err)
+	}
+	h.Equal("data: late\n", line)
+
+	cancel()
+
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		h.Fail("the handler was not told of the disconnect")
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"sync/atomic"
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/djmarrerajr/common-lib/errs"
 	"github.com/djmarrerajr/common-lib/shared"
 	"github.com/djmarrerajr/common-lib/utils"
 )
@@ -20,6 +22,14 @@ type Server struct {
 	Api    *http.Server
 	Logger utils.Logger
 
+	ErrorStatuses ErrorStatusMap // maps an error's type to the HTTP status returned
+	Codecs        *CodecRegistry // decodes requests and encodes responses by media type
+
+	problems problemConfig   // controls the generation of RFC 7807 responses
+	routes   *routeRegistry  // the request handlers from which the OpenAPI document is generated
+	draining *atomic.Bool    // set once the Server has been asked to drain
+	sockets  *socketRegistry // the open WebSockets, which are closed when the Server is stopped
+
 	serverCert string
 	serverKey  string
 }
@@ -55,10 +65,16 @@ func (s Server) Start(ctx context.Context, grp *errgroup.Group) error {
 }
 
 func (s Server) Stop() error {
+	s.draining.Store(true)
+
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
 	defer cancel()
 
 	s.Logger.Infof("waiting %s for existing connections to terminate", DefaultShutdownTimeout)
+
+	// a WebSocket's connection has been hijacked and so is unknown to the http.Server...
+	s.sockets.shutdown(ctx)
+
 	if err := s.Api.Shutdown(ctx); err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			s.Logger.Infof("shutdown timeout exceeded - remaining connections terminated")
@@ -72,12 +88,72 @@ func (s Server) Stop() error {
 	return nil
 }
 
+// Drain will cause the Server to report itself as not ready, so that no new traffic is
+// routed to it, while continuing to serve requests until it is stopped
+func (s Server) Drain() {
+	s.Logger.Infof("draining, readiness checks will now fail")
+	s.draining.Store(true)
+}
+
+// readinessCheck is registered as a health check and will fail once the Server
+// has been asked to drain so that no new traffic is routed to it
+func (s Server) readinessCheck(context.Context) error {
+	if s.draining.Load() {
+		return errs.New(errs.ErrTypeUnknown, "server is draining")
+	}
+
+	return nil
+}
+
 func (s Server) DefineRoute(path string, handler http.HandlerFunc, methods ...string) {
 	defineOrReplaceRoute(&s, path, handler, methods...)
 }
 
+// DefineRouteWithOptions operates like DefineRoute except that the route is configured using
+// RouteOption(s)... of which only RouteMethods, RouteTimeout and RouteMaxBodySize apply, the
+// handler being responsible for responding once the deadline of the request's context passes
+func (s Server) DefineRouteWithOptions(path string, handler http.HandlerFunc, options ...RouteOption) {
+	config := newRouteConfig(options...)
+
+	defineOrReplaceRoute(&s, path, func(w http.ResponseWriter, r *http.Request) {
+		if config.Timeout > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), config.Timeout)
+			defer cancel()
+
+			r = r.WithContext(ctx)
+		}
+
+		if config.MaxBodySize > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, config.MaxBodySize)
+		}
+
+		handler(w, r)
+	}, config.Methods...)
+}
+
 func (s Server) DefineRequestHandler(path string, handler shared.RequestHandlerFunc, reqStruct any, methods ...string) {
-	ctxHandler := ContextualHandler{&s.AppCtx, handler, reqStruct}
+	s.DefineRequestHandlerWithOptions(path, handler, reqStruct, RouteMethods(methods...))
+}
+
+// DefineRequestHandlerWithOptions operates like DefineRequestHandler except that the route is
+// configured using RouteOption(s), i.e. api.RouteMethods, api.RouteSummary, api.RouteResponse
+func (s Server) DefineRequestHandlerWithOptions(path string, handler shared.RequestHandlerFunc, reqStruct any, options ...RouteOption) {
+	config := newRouteConfig(options...)
+
+	if s.routes != nil {
+		s.routes.add(route{path, reqStruct, config})
+	}
+
+	ctxHandler := ContextualHandler{
+		ApplicationContext: &s.AppCtx,
+		CustomHandlerFunc:  handler,
+		ErrorStatuses:      s.ErrorStatuses,
+		Codecs:             s.Codecs,
+		problems:           s.problems,
+		config:             config,
+		denials:            newDenialCounter(s.AppCtx, config),
+		any:                reqStruct,
+	}
 
-	defineOrReplaceRoute(&s, path, ctxHandler.ServeHTTP, methods...)
+	defineOrReplaceRoute(&s, path, ctxHandler.ServeHTTP, config.Methods...)
 }
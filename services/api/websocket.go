@@ -0,0 +1,600 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// WebSocketHandlerFunc is a request handler that exchanges messages with the API caller over
+// a WebSocket... it should return once it is done with the WebSocket, or the WebSocket has
+// been closed by the API caller or the Server (at which point the context is done)
+type WebSocketHandlerFunc func(context.Context, *shared.ApplicationContext, *WebSocket) error
+
+// DefineWebSocketHandler will upgrade each GET request for the path to a WebSocket, which is
+// then handed to the handler, i.e.
+//
+//	server.DefineWebSocketHandler("/chat", func(ctx context.Context, appCtx *shared.ApplicationContext, ws *api.WebSocket) error {
+//		for msg := range ws.Messages() {
+//			if err := ws.Send(reply(msg.(*ChatMessage))); err != nil {
+//				return err
+//			}
+//		}
+//		return nil
+//	}, ChatMessage{})
+//
+// Messages are encoded, and decoded, using the Codec negotiated from the Accept header of the
+// upgrade request (JSON by default), each message received being decoded in to a new instance
+// of msgStruct (or delivered as-is, as a []byte, should msgStruct be nil).  A message that
+// cannot be decoded, or fails validation, is answered with an ErrorResponse in place of being
+// delivered.
+//
+// The WebSocket is pinged every DefaultWebSocketPingInterval (see RouteHeartbeat) and closed
+// should the API caller fail to respond, send a message larger than the RouteMaxMessageSize,
+// or the Server be stopped.  As with any browser-facing WebSocket, upgrade requests from an
+// origin other than the Server's own are refused.
+func (s Server) DefineWebSocketHandler(path string, handler WebSocketHandlerFunc, msgStruct any, options ...RouteOption) {
+	config := newRouteConfig(options...)
+	config.Methods = []string{http.MethodGet}
+
+	if s.routes != nil {
+		s.routes.add(route{path, nil, config})
+	}
+
+	ctxHandler := ContextualHandler{
+		ApplicationContext: &s.AppCtx,
+		ErrorStatuses:      s.ErrorStatuses,
+		Codecs:             s.Codecs,
+		problems:           s.problems,
+		config:             config,
+		denials:            newDenialCounter(s.AppCtx, config),
+		socketFunc:         handler,
+		sockets:            s.sockets,
+		socketCollectors:   newSocketCollectors(s.AppCtx),
+		any:                msgStruct,
+	}
+
+	defineOrReplaceRoute(&s, path, ctxHandler.ServeHTTP, config.Methods...)
+}
+
+// serveWebSocket will, once the API caller has been authorized, upgrade the connection to a
+// WebSocket and invoke the WebSocket handler, closing the WebSocket once it has returned
+//
+// An error returned by the handler is logged and sent to the API caller as the reason the
+// WebSocket was closed, there being no other way to return it.
+func (h ContextualHandler) serveWebSocket(w http.ResponseWriter, r *http.Request, reqCtx, spanCtx context.Context) {
+	w.Header().Add(HeaderVary, HeaderAccept)
+
+	codec, OK := h.codecs().Negotiate(r.Header.Get(HeaderAccept), ValueApplicationJson)
+	if !OK {
+		err := errs.Errorf(errs.ErrTypeNotAcceptable, "unable to produce any of: %s", r.Header.Get(HeaderAccept))
+		h.returnErrorResponse(w, r, reqCtx, defaultCodec, err, 0)
+		return
+	}
+
+	if err := h.authorize(reqCtx, r); err != nil {
+		h.returnErrorResponse(w, r, reqCtx, codec, err, 0)
+		return
+	}
+
+	if h.sockets.isStopping() {
+		h.returnErrorResponse(w, r, reqCtx, codec, errs.New(errs.ErrTypeUnavailable, closeReasonStopping), 0)
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			h.returnErrorResponse(w, r, reqCtx, codec, errs.WithTypeFallback(reason, errs.ErrTypeUnknown), status)
+		},
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already responded...
+		return
+	}
+
+	ws := newWebSocket(spanCtx, conn, codec, h.config, h.socketMetrics(r))
+	go h.receive(reqCtx, ws)
+
+	// the Server may have begun stopping while the connection was being upgraded...
+	if !h.sockets.track(ws) {
+		ws.closeWith(websocket.CloseGoingAway, closeReasonStopping)
+		ws.finish()
+		return
+	}
+	defer h.sockets.untrack(ws)
+
+	stop := ws.keepalive()
+	err = h.socketFunc(ws.ctx, h.ApplicationContext, ws)
+	stop()
+
+	switch {
+	case err == nil, ws.ctx.Err() != nil:
+		// the WebSocket has been closed, which is how most WebSockets end...
+		ws.closeWith(websocket.CloseNormalClosure, "")
+	default:
+		err = errs.WithTypeFallback(err, errs.ErrTypeUnknown)
+		h.Logger.WithCtx(reqCtx).Error("error handling websocket", err)
+
+		if mw, OK := findMetricsWriter(w); OK {
+			mw.errorType = errs.GetType(err)
+		}
+
+		ws.closeWith(websocket.CloseInternalServerErr, err.Error())
+	}
+
+	ws.finish()
+
+	if span := opentracing.SpanFromContext(spanCtx); span != nil {
+		span.SetTag("websocket.messages_in", ws.in.Load())
+		span.SetTag("websocket.messages_out", ws.out.Load())
+	}
+}
+
+// receive will, until the WebSocket is closed, read each message sent by the API caller and
+// deliver it to the handler... a message that cannot be decoded, or is invalid, is answered
+// with an ErrorResponse
+func (h ContextualHandler) receive(reqCtx context.Context, ws *WebSocket) {
+	defer close(ws.received)
+	defer close(ws.messages)
+	defer ws.cancel()
+
+	for {
+		ws.extendReadDeadline()
+
+		_, p, err := ws.conn.ReadMessage()
+		if err != nil {
+			if ws.ctx.Err() == nil && !isExpectedClose(err) {
+				h.Logger.WithCtx(reqCtx).Error("error receiving message", err)
+			}
+			return
+		}
+
+		ws.in.Add(1)
+		ws.metrics.received()
+
+		msg, err := h.decodeMessage(ws.codec, p)
+		if err != nil {
+			h.rejectMessage(reqCtx, ws, err)
+			continue
+		}
+
+		select {
+		case ws.messages <- msg:
+		case <-ws.ctx.Done():
+			return
+		}
+	}
+}
+
+// decodeMessage will, using the WebSocket's Codec, transform the message in to a pointer to
+// a new instance of the message struct which, if we *have* a validator, is then validated
+func (h ContextualHandler) decodeMessage(codec Codec, p []byte) (any, error) {
+	if h.any == nil {
+		return p, nil
+	}
+
+	data := reflect.New(reflect.TypeOf(h.any)).Interface()
+
+	// the Codecs decode requests, so the message is presented as one...
+	req := &http.Request{
+		Header:        http.Header{HeaderContentType: []string{codec.MediaType()}},
+		Body:          io.NopCloser(bytes.NewReader(p)),
+		ContentLength: int64(len(p)),
+	}
+
+	if err := codec.Decode(req, data); err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeUnmarshal)
+	}
+
+	if h.ApplicationContext.Validator != nil {
+		if err := h.ApplicationContext.Validator.Struct(data); err != nil {
+			return nil, errs.WithType(err, errs.ErrTypeValidation)
+		}
+	}
+
+	return data, nil
+}
+
+// rejectMessage will log the error with which a message was rejected and return it to the
+// API caller as an ErrorResponse
+func (h ContextualHandler) rejectMessage(reqCtx context.Context, ws *WebSocket, err error) {
+	h.Logger.WithCtx(reqCtx).Error("error receiving message", err)
+
+	reqID, _ := utils.GetFieldValueFromContext[string](reqCtx, shared.RequestIdContextKey)
+
+	// nolint: errcheck
+	ws.Send(ErrorResponse{
+		RequestId:   reqID,
+		Type:        errs.GetType(err),
+		Code:        h.ErrorStatuses.StatusFor(err),
+		Description: err.Error(),
+	})
+}
+
+// WebSocket is used by a WebSocketHandlerFunc to exchange messages with the API caller
+//
+// A WebSocket is safe for concurrent use, and refuses anything sent once it has been closed
+// by returning the error of its context.
+type WebSocket struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	conn    *websocket.Conn
+	codec   Codec
+	metrics *socketMetrics
+
+	messages chan any      // the decoded messages, closed once the WebSocket is closed
+	received chan struct{} // closed once nothing more will be read from the connection
+
+	interval     time.Duration // between pings, none being sent if not positive
+	writeTimeout time.Duration // the time permitted to send each message
+
+	mu        sync.Mutex // held while a message is sent
+	closeOnce sync.Once
+	in, out   atomic.Int64
+}
+
+func newWebSocket(ctx context.Context, conn *websocket.Conn, codec Codec, config RouteConfig, metrics *socketMetrics) *WebSocket {
+	ws := &WebSocket{
+		conn:         conn,
+		codec:        codec,
+		metrics:      metrics,
+		messages:     make(chan any),
+		received:     make(chan struct{}),
+		interval:     config.Heartbeat,
+		writeTimeout: config.WriteTimeout,
+	}
+
+	ws.ctx, ws.cancel = context.WithCancel(ctx)
+
+	if ws.interval == 0 {
+		ws.interval = DefaultWebSocketPingInterval
+	}
+
+	if ws.writeTimeout <= 0 {
+		ws.writeTimeout = DefaultWriteTimeout
+	}
+
+	readLimit := config.MaxMessageSize
+	if readLimit <= 0 {
+		readLimit = DefaultWebSocketMaxMessageSize
+	}
+
+	conn.SetReadLimit(readLimit)
+	conn.SetPongHandler(func(string) error {
+		ws.extendReadDeadline()
+		return nil
+	})
+
+	metrics.connected()
+
+	return ws
+}
+
+// MediaType returns the media type of the messages, as negotiated from the Accept header
+func (ws *WebSocket) MediaType() string {
+	return ws.codec.MediaType()
+}
+
+// Messages returns the channel on which each message sent by the API caller is delivered,
+// which is closed once the WebSocket is closed
+func (ws *WebSocket) Messages() <-chan any {
+	return ws.messages
+}
+
+// Done returns a channel that is closed once the WebSocket has been closed
+func (ws *WebSocket) Done() <-chan struct{} {
+	return ws.ctx.Done()
+}
+
+// Send will send the value, encoded using the negotiated Codec, as a message... a string or
+// []byte is sent as-is, as a text or binary message respectively
+func (ws *WebSocket) Send(v any) error {
+	if err := ws.ctx.Err(); err != nil {
+		return err
+	}
+
+	msgType := websocket.TextMessage
+
+	var p []byte
+
+	switch v := v.(type) {
+	case string:
+		p = []byte(v)
+	case []byte:
+		msgType, p = websocket.BinaryMessage, v
+	default:
+		var buff bytes.Buffer
+
+		if err := ws.codec.Encode(&buff, v); err != nil {
+			return errs.WithType(err, errs.ErrTypeMarshal)
+		}
+
+		// a binary media type (i.e. application/msgpack) cannot be sent as text...
+		if p = buff.Bytes(); !utf8.Valid(p) {
+			msgType = websocket.BinaryMessage
+		}
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if err := ws.conn.SetWriteDeadline(time.Now().Add(ws.writeTimeout)); err != nil {
+		return err
+	}
+
+	if err := ws.conn.WriteMessage(msgType, p); err != nil {
+		return err
+	}
+
+	ws.out.Add(1)
+	ws.metrics.sent()
+
+	return nil
+}
+
+// extendReadDeadline allows the API caller until the next-but-one ping to respond, or
+// send a message, before the WebSocket is considered dead
+func (ws *WebSocket) extendReadDeadline() {
+	if ws.interval > 0 {
+		// nolint: errcheck
+		ws.conn.SetReadDeadline(time.Now().Add(2 * ws.interval))
+	}
+}
+
+// keepalive will, until stopped, periodically ping the API caller so that an unresponsive
+// API caller is detected, and the connection is not closed by any proxy between us
+func (ws *WebSocket) keepalive() (stop func()) {
+	if ws.interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(ws.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ws.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := ws.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(ws.writeTimeout)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// closeWith will send the API caller a close message, only the first of which is sent, after
+// which nothing more may be sent or delivered
+func (ws *WebSocket) closeWith(code int, reason string) {
+	ws.closeOnce.Do(func() {
+		ws.cancel()
+
+		// the reason must fit within a control message...
+		if len(reason) > maxCloseReason {
+			reason = reason[:maxCloseReason]
+		}
+
+		// nolint: errcheck
+		ws.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(ws.writeTimeout))
+	})
+}
+
+// finish will give the API caller a moment to acknowledge the close message before the
+// connection is closed
+func (ws *WebSocket) finish() {
+	timer := time.NewTimer(DefaultWebSocketCloseTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-ws.received:
+	case <-timer.C:
+	}
+
+	// nolint: errcheck
+	ws.conn.Close()
+	<-ws.received
+
+	ws.metrics.disconnected()
+}
+
+// maxCloseReason is the length of the longest reason a close message may carry
+const maxCloseReason = 123
+
+const closeReasonStopping = "server is stopping"
+
+// isExpectedClose reports whether the error is that of an API caller which has closed, or
+// simply dropped, the connection
+func isExpectedClose(err error) bool {
+	return websocket.IsCloseError(err,
+		websocket.CloseNormalClosure,
+		websocket.CloseGoingAway,
+		websocket.CloseNoStatusReceived,
+		websocket.CloseAbnormalClosure,
+	) || errors.Is(err, net.ErrClosed)
+}
+
+// socketRegistry holds the open WebSockets so that they may be closed when the Server is
+// stopped, their connections being unknown to the http.Server once hijacked
+type socketRegistry struct {
+	mu       sync.Mutex
+	sockets  map[*WebSocket]struct{}
+	stopping bool
+	wg       sync.WaitGroup
+}
+
+func (r *socketRegistry) isStopping() bool {
+	if r == nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stopping
+}
+
+// track will record the WebSocket, which is refused should the Server be stopping
+func (r *socketRegistry) track(ws *WebSocket) bool {
+	if r == nil {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopping {
+		return false
+	}
+
+	if r.sockets == nil {
+		r.sockets = make(map[*WebSocket]struct{})
+	}
+
+	r.sockets[ws] = struct{}{}
+	r.wg.Add(1)
+
+	return true
+}
+
+func (r *socketRegistry) untrack(ws *WebSocket) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sockets, ws)
+	r.wg.Done()
+}
+
+// shutdown will ask the API caller of each open WebSocket to go away, and wait until their
+// handlers have returned or the context is done (at which point the connections are dropped)
+func (r *socketRegistry) shutdown(ctx context.Context) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.stopping = true
+
+	sockets := make([]*WebSocket, 0, len(r.sockets))
+	for ws := range r.sockets {
+		sockets = append(sockets, ws)
+	}
+	r.mu.Unlock()
+
+	for _, ws := range sockets {
+		ws.closeWith(websocket.CloseGoingAway, closeReasonStopping)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		for _, ws := range sockets {
+			// nolint: errcheck
+			ws.conn.Close()
+		}
+	}
+}
+
+// socketCollectors are the standard set of metrics recorded for the WebSockets of a route,
+// nil should the application have no metrics collector
+type socketCollectors struct {
+	open metrics.DimensionedGauge
+	in   metrics.DimensionedCounter
+	out  metrics.DimensionedCounter
+}
+
+func newSocketCollectors(appCtx shared.ApplicationContext) *socketCollectors {
+	collector := appCtx.Collector
+	if collector == nil {
+		return nil
+	}
+
+	labels := append(shared.MetricFilterLabels(), "path")
+
+	return &socketCollectors{
+		open: collector.NewDimensionedGauge("websocket_connections_open", labels...),
+		in:   collector.NewDimensionedCounter("websocket_messages_in_total", labels...),
+		out:  collector.NewDimensionedCounter("websocket_messages_out_total", labels...),
+	}
+}
+
+// socketMetrics are the metrics recorded for a WebSocket, nil should the application have
+// no metrics collector
+type socketMetrics struct {
+	*socketCollectors
+	values []string
+}
+
+func (h ContextualHandler) socketMetrics(r *http.Request) *socketMetrics {
+	if h.socketCollectors == nil {
+		return nil
+	}
+
+	return &socketMetrics{h.socketCollectors, append(shared.MetricFilterValues(h.RootCtx), metricPath(r))}
+}
+
+func (m *socketMetrics) connected() {
+	if m != nil {
+		m.open.WithLabelValues(m.values...).Inc()
+	}
+}
+
+func (m *socketMetrics) disconnected() {
+	if m != nil {
+		m.open.WithLabelValues(m.values...).Dec()
+	}
+}
+
+func (m *socketMetrics) received() {
+	if m != nil {
+		m.in.WithLabelValues(m.values...).Inc()
+	}
+}
+
+func (m *socketMetrics) sent() {
+	if m != nil {
+		m.out.WithLabelValues(m.values...).Inc()
+	}
+}
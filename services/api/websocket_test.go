@@ -0,0 +1,195 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+func (h *HandlerTestSuite) TestWebSocket_ExchangesTypedMessages() {
+	h.appctx.Collector, _ = metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "test")
+
+	server := h.newGreetingServer(api.WithRequestMiddleware(api.MetricsMiddleware(h.appctx)))
+	server.DefineWebSocketHandler("/chat", func(_ context.Context, _ *shared.ApplicationContext, ws *api.WebSocket) error {
+		for msg := range ws.Messages() {
+			if err := ws.Send(greeting{"hello " + msg.(*testRequest).Name}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, testRequest{})
+
+	live := httptest.NewServer(server.Api.Handler)
+	defer live.Close()
+
+	conn := h.dial(live, "/chat", http.Header{api.HeaderAccept: {api.ValueApplicationXml}})
+
+	h.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(`<testRequest><Name>bruno</Name></testRequest>`)))
+
+	msgType, p, err := conn.ReadMessage()
+	h.Require().NoError(err)
+	h.Equal(websocket.TextMessage, msgType)
+	h.Equal(`<greeting><message>hello bruno</message></greeting>`, string(p))
+
+	// a message that fails validation is answered with an error, rather than delivered...
+	h.Require().NoError(conn.WriteMessage(websocket.TextMessage, []byte(`<testRequest></testRequest>`)))
+
+	_, p, err = conn.ReadMessage()
+	h.Require().NoError(err)
+	h.Contains(string(p), `<type>Validation</type><code>422</code>`)
+
+	h.Require().NoError(conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+
+	_, _, err = conn.ReadMessage()
+	h.True(websocket.IsCloseError(err, websocket.CloseNormalClosure))
+
+	scrape := h.scrapeEventually(`test_websocket_connections_open{appName="",appVersion="",environ="",hostname="",path="chat"} 0`)
+	h.Contains(scrape, `test_websocket_messages_in_total{appName="",appVersion="",environ="",hostname="",path="chat"} 2`)
+	h.Contains(scrape, `test_websocket_messages_out_total{appName="",appVersion="",environ="",hostname="",path="chat"} 2`)
+	h.Contains(scrape, `test_response_status{appName="",appVersion="",environ="",hostname="",path="chat",statusCode="101"} 1`)
+}
+
+func (h *HandlerTestSuite) TestWebSocket_HandlerErrorsAndOversizedMessagesClose() {
+	server := h.newGreetingServer()
+	server.DefineWebSocketHandler("/fails", func(context.Context, *shared.ApplicationContext, *api.WebSocket) error {
+		return errs.New(errs.ErrTypeNotFound, "no such room")
+	}, nil)
+	server.DefineWebSocketHandler("/echo", func(_ context.Context, _ *shared.ApplicationContext, ws *api.WebSocket) error {
+		for msg := range ws.Messages() {
+			_ = ws.Send(msg)
+		}
+		return nil
+	}, nil, api.RouteMaxMessageSize(8))
+
+	live := httptest.NewServer(server.Api.Handler)
+	defer live.Close()
+
+	_, _, err := h.dial(live, "/fails", nil).ReadMessage()
+	h.True(websocket.IsCloseError(err, websocket.CloseInternalServerErr))
+	h.Contains(err.Error(), "no such room")
+
+	conn := h.dial(live, "/echo", nil)
+
+	h.Require().NoError(conn.WriteMessage(websocket.BinaryMessage, []byte("short")))
+
+	msgType, p, err := conn.ReadMessage()
+	h.Require().NoError(err)
+	h.Equal(websocket.BinaryMessage, msgType)
+	h.Equal("short", string(p))
+
+	h.Require().NoError(conn.WriteMessage(websocket.BinaryMessage, []byte("far too long")))
+
+	_, _, err = conn.ReadMessage()
+	h.True(websocket.IsCloseError(err, websocket.CloseMessageTooBig))
+
+	// while a request that is not an upgrade is refused as usual
+	rec := h.get(server, "", "/echo")
+
+	h.Equal(http.StatusBadRequest, rec.Code)
+	h.Equal(http.StatusBadRequest, h.decodeError(rec).Code)
+}
+
+func (h *HandlerTestSuite) TestWebSocket_ClosedWhenServerStops() {
+	returned := make(chan struct{})
+
+	server := h.newGreetingServer()
+	server.DefineWebSocketHandler("/feed", func(ctx context.Context, _ *shared.ApplicationContext, ws *api.WebSocket) error {
+		defer close(returned)
+
+		_ = ws.Send("subscribed")
+		<-ctx.Done()
+
+		return ctx.Err()
+	}, nil, api.RouteHeartbeat(10*time.Millisecond))
+
+	live := httptest.NewServer(server.Api.Handler)
+	defer live.Close()
+
+	conn := h.dial(live, "/feed", nil)
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+
+	_, p, err := conn.ReadMessage()
+	h.Require().NoError(err)
+	h.Equal("subscribed", string(p))
+
+	// the pings are only handled while reading, which must continue until the close...
+	closed := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-pinged:
+	case <-time.After(5 * time.Second):
+		h.Fail("the websocket was not pinged")
+	}
+
+	h.Require().NoError(server.Stop())
+
+	select {
+	case <-returned:
+	default:
+		h.Fail("the handler had not returned once the server stopped")
+	}
+
+	select {
+	case err = <-closed:
+		h.True(websocket.IsCloseError(err, websocket.CloseGoingAway))
+	case <-time.After(5 * time.Second):
+		h.Fail("the websocket was not closed")
+	}
+
+	// and no new websockets are accepted
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(live.URL, "http")+"/feed", nil)
+	h.Require().Error(err)
+	h.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func (h *HandlerTestSuite) dial(live *httptest.Server, path string, header http.Header) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(live.URL, "http")+path, header)
+	h.Require().NoError(err)
+
+	h.T().Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// scrapeEventually returns the scraped metrics once they contain the expected metric, the
+// metrics of a WebSocket being recorded once the server has finished with it
+func (h *HandlerTestSuite) scrapeEventually(expected string) string {
+	var scrape string
+
+	h.Eventually(func() bool {
+		rec := httptest.NewRecorder()
+		h.appctx.Collector.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		scrape = rec.Body.String()
+
+		return strings.Contains(scrape, expected)
+	}, 5*time.Second, 10*time.Millisecond, expected)
+
+	return scrape
+}
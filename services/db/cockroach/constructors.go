@@ -103,14 +103,30 @@ func NewAdapterFromEnv(env utils.Environ, appCtx shared.ApplicationContext, opti
 }
 
 func newCockroachDB(appCtx shared.ApplicationContext, database string, options ...Option) *CockroachDB {
-	db := CockroachDB{
+	adapter := CockroachDB{
 		AppCtx:   appCtx,
 		database: database,
+		retry:    db.DefaultRetryPolicy(),
 	}
 
 	for _, option := range options {
-		option(&db)
+		option(&adapter)
 	}
 
-	return &db
+	// count each re-attempted transaction, should the application have a collector...
+	if appCtx.Collector != nil {
+		retries := appCtx.Collector.NewCounter(db.TxRetriesMetricName)
+		onRetry := adapter.retry.OnRetry
+
+		adapter.retry.OnRetry = func(err error) {
+			retries.Inc()
+			adapter.logger.Debugf("retrying transaction: %s", err)
+
+			if onRetry != nil {
+				onRetry(err)
+			}
+		}
+	}
+
+	return &adapter
 }
@@ -1,30 +1,13 @@
 package cockroach
 
 import (
+	"github.com/djmarrerajr/common-lib/services/db"
 	"github.com/djmarrerajr/common-lib/utils"
 )
 
-type LogWriter struct {
-	utils.Logger
-}
-
-func (l LogWriter) Printf(msg string, data ...interface{}) {
-	var isError bool
-
-	for _, item := range data {
-		switch item.(type) {
-		case error:
-			isError = true
-		}
-	}
-
-	if isError {
-		l.Errorf(msg, data...)
-	} else {
-		l.Infof(msg, data...)
-	}
-}
+// LogWriter is retained for compatibility, see db.LogWriter
+type LogWriter = db.LogWriter
 
 func NewGormLogger(logger utils.Logger) *LogWriter {
-	return &LogWriter{logger}
+	return db.NewGormLogger(logger)
 }
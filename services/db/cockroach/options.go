@@ -1,8 +1,11 @@
 package cockroach
 
 import (
+	"io/fs"
 	"time"
 
+	"github.com/djmarrerajr/common-lib/services/db"
+	"github.com/djmarrerajr/common-lib/services/db/migrations"
 	"github.com/djmarrerajr/common-lib/utils"
 )
 
@@ -46,3 +49,27 @@ func WithCertificateInfo(ca, cert, key string) Option {
 		cd.key = key
 	}
 }
+
+// WithRetryPolicy replaces the policy used to re-attempt transactions that fail due to contention
+func WithRetryPolicy(policy db.RetryPolicy) Option {
+	return func(cd *CockroachDB) {
+		cd.retry = policy
+	}
+}
+
+// WithSavepointRetries enables the cockroach_restart savepoint protocol so that transactions
+// are re-attempted without needing to begin a new transaction
+func WithSavepointRetries() Option {
+	return func(cd *CockroachDB) {
+		cd.retry.UseSavepoint = true
+	}
+}
+
+// WithMigrations will apply the migrations contained within the source (typically an
+// embed.FS) each time the adapter is started
+func WithMigrations(source fs.FS, options ...migrations.Option) Option {
+	return func(cd *CockroachDB) {
+		cd.migrations = source
+		cd.migrationOpts = options
+	}
+}
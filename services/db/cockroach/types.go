@@ -4,6 +4,7 @@ package cockroach
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -13,7 +14,9 @@ import (
 	"gorm.io/gorm/schema"
 	"gorm.io/plugin/dbresolver"
 
+	"github.com/djmarrerajr/common-lib/observability/health"
 	"github.com/djmarrerajr/common-lib/services/db"
+	"github.com/djmarrerajr/common-lib/services/db/migrations"
 	"github.com/djmarrerajr/common-lib/shared"
 	"github.com/djmarrerajr/common-lib/utils"
 )
@@ -41,12 +44,23 @@ type CockroachDB struct {
 	idleConn int
 	maxTime  time.Duration
 	idleTime time.Duration
+
+	retry db.RetryPolicy
+
+	migrations    fs.FS
+	migrationOpts []migrations.Option
+}
+
+// Conn returns the database connection bound to the provided context
+func (d *CockroachDB) Conn(ctx context.Context) *gorm.DB {
+	return d.conn.WithContext(ctx)
 }
 
-func (d *CockroachDB) CreateAccount(acct *db.Account) error { return d.conn.Create(acct).Error }
-func (d *CockroachDB) GetAccount(acct *db.Account) error    { return d.conn.First(acct).Error }
-func (d *CockroachDB) UpdateAccount(acct *db.Account) error { return d.conn.Save(acct).Error }
-func (d *CockroachDB) DeleteAccount(acct *db.Account) error { return d.conn.Delete(acct).Error }
+// RunInTx will execute the TxFunc within a transaction, re-attempting it according to
+// the configured RetryPolicy should it fail due to contention (SQLSTATE 40001)
+func (d *CockroachDB) RunInTx(ctx context.Context, fn db.TxFunc) error {
+	return db.RunInTx(ctx, d.conn, d.retry, fn)
+}
 
 func (d *CockroachDB) Start(ctx context.Context, grp *errgroup.Group) error {
 	url := fmt.Sprintf(connectionString, d.user, d.host, d.port, d.database, d.cert, d.key, d.ca)
@@ -91,9 +105,50 @@ func (d *CockroachDB) Start(ctx context.Context, grp *errgroup.Group) error {
 	})
 
 	d.conn = conn
+
+	if d.migrations != nil {
+		err = d.migrate(ctx)
+		if err != nil {
+			d.logger.Errorf("unable to migrate database: %s", err)
+			return err
+		}
+	}
+
+	if d.AppCtx.Health != nil {
+		d.AppCtx.Health.Register(health.Check{
+			Name:     db.HealthCheckName,
+			Checker:  d.ping,
+			Timeout:  db.DefaultHealthCheckTimeout,
+			CacheTTL: db.DefaultHealthCheckCacheTTL,
+			Critical: true,
+		})
+	}
+
 	return nil
 }
 
+// migrate will apply any of the configured migrations that are pending
+func (d *CockroachDB) migrate(ctx context.Context) error {
+	options := append([]migrations.Option{migrations.WithLogger(d.logger)}, d.migrationOpts...)
+
+	migrator, err := migrations.New(d.conn, d.migrations, options...)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Up(ctx)
+}
+
+// ping is registered as a health check and will verify the database is reachable
+func (d *CockroachDB) ping(ctx context.Context) error {
+	sqlDB, err := d.conn.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
 func (d *CockroachDB) Stop() error {
 	d.logger.Infof("database connection closed")
 	return nil
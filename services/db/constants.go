@@ -8,6 +8,23 @@ const (
 	DefaultMaxOpenConnections = 10
 	DefaultMaxConnLifeTime    = 10 * time.Second
 	DefaultMaxConnIdleTime    = 5 * time.Second
+
+	DefaultHealthCheckTimeout  = 2 * time.Second
+	DefaultHealthCheckCacheTTL = 5 * time.Second
+
+	DefaultTxMaxRetries     = 5
+	DefaultTxInitialBackoff = 10 * time.Millisecond
+	DefaultTxMaxBackoff     = time.Second
+)
+
+// nolint: unused
+const (
+	HealthCheckName = "database"
+
+	RetryableSQLState = "40001"
+	SavepointName     = "cockroach_restart"
+
+	TxRetriesMetricName = "db_transaction_retries_total"
 )
 
 // nolint: unused
@@ -25,4 +42,12 @@ const (
 	DatabaseMaxOpenConnEnvKey = "DB_MAX_OPEN_CONNECTIONS"
 	DatabaseMaxIdleTimeEnvKey = "DB_MAX_IDLE_TIME_SECS"
 	DatabaseMaxOpenTimeEnvKey = "DB_MAX_OPEN_TIME_SECS"
+
+	SQLitePathEnvKey = "DB_SQLITE_PATH"
+)
+
+// nolint: unused
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 1000
 )
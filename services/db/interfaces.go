@@ -1,12 +1,38 @@
 package db
 
-import "github.com/djmarrerajr/common-lib/services"
+import (
+	"context"
 
+	"gorm.io/gorm"
+
+	"github.com/djmarrerajr/common-lib/services"
+)
+
+// Adapter is implemented by each of the supported databases and provides the
+// connection upon which a Repository operates
 type Adapter interface {
 	services.Serviceable
 
-	CreateAccount(*Account) error
-	GetAccount(*Account) error
-	UpdateAccount(*Account) error
-	DeleteAccount(*Account) error
+	Conn(context.Context) *gorm.DB
+	RunInTx(context.Context, TxFunc) error
+}
+
+// CreateHook may be implemented by a model that needs to act before it is created
+type CreateHook interface {
+	OnCreate(context.Context) error
+}
+
+// UpdateHook may be implemented by a model that needs to act before it is updated
+type UpdateHook interface {
+	OnUpdate(context.Context) error
+}
+
+// DeleteHook may be implemented by a model that needs to act before it is deleted
+type DeleteHook interface {
+	OnDelete(context.Context) error
+}
+
+// LoadHook may be implemented by a model that needs to act after it has been loaded
+type LoadHook interface {
+	OnLoad(context.Context) error
 }
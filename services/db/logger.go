@@ -0,0 +1,32 @@
+package db
+
+import (
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// LogWriter adapts a utils.Logger for use as the writer of a GORM logger
+type LogWriter struct {
+	utils.Logger
+}
+
+func (l LogWriter) Printf(msg string, data ...interface{}) {
+	var isError bool
+
+	for _, item := range data {
+		switch item.(type) {
+		case error:
+			isError = true
+		}
+	}
+
+	if isError {
+		l.Errorf(msg, data...)
+	} else {
+		l.Infof(msg, data...)
+	}
+}
+
+// NewGormLogger will wrap the logger so that it can be provided to GORM
+func NewGormLogger(logger utils.Logger) *LogWriter {
+	return &LogWriter{logger}
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"context"
+	"io/fs"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/djmarrerajr/common-lib/services/db"
+)
+
+// Command is a standalone Runnable (see app.Runnable) that will start the database
+// adapter, migrate the schema and then stop the adapter again
+type Command struct {
+	ctx     context.Context
+	adapter db.Adapter
+	source  fs.FS
+	steps   int
+	down    bool
+	options []Option
+}
+
+// NewUpCommand returns a Command that will apply all of the pending migrations
+func NewUpCommand(ctx context.Context, adapter db.Adapter, source fs.FS, options ...Option) *Command {
+	return &Command{ctx: ctx, adapter: adapter, source: source, options: options}
+}
+
+// NewDownCommand returns a Command that will revert the specified number of migrations
+func NewDownCommand(ctx context.Context, adapter db.Adapter, source fs.FS, steps int, options ...Option) *Command {
+	return &Command{ctx: ctx, adapter: adapter, source: source, steps: steps, down: true, options: options}
+}
+
+func (c *Command) Run() error {
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+
+	grp, gCtx := errgroup.WithContext(ctx)
+
+	err := c.adapter.Start(gCtx, grp)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = c.adapter.Stop() }()
+
+	migrator, err := New(c.adapter.Conn(ctx), c.source, c.options...)
+	if err != nil {
+		return err
+	}
+
+	if c.down {
+		return migrator.Down(ctx, c.steps)
+	}
+
+	return migrator.Up(ctx)
+}
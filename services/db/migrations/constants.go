@@ -0,0 +1,19 @@
+package migrations
+
+import "time"
+
+// nolint: unused
+const (
+	DefaultDirectory    = "."
+	DefaultTable        = "schema_history"
+	DefaultLockTimeout  = time.Minute
+	DefaultLockTTL      = 15 * time.Minute
+	DefaultPollInterval = 500 * time.Millisecond
+)
+
+// nolint: unused
+const (
+	upSuffix   = "up"
+	downSuffix = "down"
+	lockID     = 1
+)
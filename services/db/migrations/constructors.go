@@ -0,0 +1,110 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/djmarrerajr/common-lib/errs"
+)
+
+// fileNamePattern matches migration files such as 0001_create_accounts.up.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// New will instantiate and return a Migrator that applies the migrations contained within
+// the source (typically an embed.FS) to the database
+func New(conn *gorm.DB, source fs.FS, options ...Option) (*Migrator, error) {
+	migrator := Migrator{
+		conn:        conn,
+		dir:         DefaultDirectory,
+		table:       DefaultTable,
+		owner:       uuid.NewString(),
+		lockTimeout: DefaultLockTimeout,
+		lockTTL:     DefaultLockTTL,
+	}
+
+	for _, option := range options {
+		option(&migrator)
+	}
+
+	migrations, err := loadMigrations(source, migrator.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator.migrations = migrations
+
+	return &migrator, nil
+}
+
+// loadMigrations will read and pair each of the up/down migration files within the directory,
+// returning them in version order
+func loadMigrations(source fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, dir)
+	if err != nil {
+		return nil, errs.Wrap(err, errs.ErrTypeConfiguration, "unable to read migrations")
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		parts := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || parts == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, errs.Wrapf(err, errs.ErrTypeConfiguration, "invalid migration version: %s", entry.Name())
+		}
+
+		contents, err := fs.ReadFile(source, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errs.Wrapf(err, errs.ErrTypeConfiguration, "unable to read migration: %s", entry.Name())
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = migration
+		} else if migration.Name != parts[2] {
+			return nil, errs.Errorf(errs.ErrTypeConfiguration, "duplicate migration version: %d", version)
+		}
+
+		if parts[3] == upSuffix {
+			migration.Up = string(contents)
+			migration.Checksum = checksum(contents)
+		} else {
+			migration.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for version, migration := range byVersion {
+		if migration.Checksum == "" {
+			return nil, errs.Errorf(errs.ErrTypeConfiguration, "migration %d has no up file", version)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// checksum returns the hex encoded SHA-256 of the migration's contents
+func checksum(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
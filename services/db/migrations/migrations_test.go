@@ -0,0 +1,142 @@
+package migrations_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/db"
+	"github.com/djmarrerajr/common-lib/services/db/migrations"
+)
+
+type testAdapter struct {
+	conn *gorm.DB
+}
+
+func (t *testAdapter) Start(context.Context, *errgroup.Group) error { return nil }
+func (t *testAdapter) Stop() error                                  { return nil }
+func (t *testAdapter) Conn(ctx context.Context) *gorm.DB            { return t.conn.WithContext(ctx) }
+
+func (t *testAdapter) RunInTx(ctx context.Context, fn db.TxFunc) error {
+	return db.RunInTx(ctx, t.conn, db.DefaultRetryPolicy(), fn)
+}
+
+type MigrationsTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	conn   *gorm.DB
+	source fstest.MapFS
+}
+
+func (m *MigrationsTestSuite) SetupTest() {
+	conn, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	m.Require().NoError(err)
+
+	// every connection to :memory: is a distinct database...
+	sqlDB, _ := conn.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	m.ctx = context.Background()
+	m.conn = conn
+	m.source = fstest.MapFS{
+		"sql/0001_create_widgets.up.sql":   {Data: []byte("CREATE TABLE widgets (id INT PRIMARY KEY);")},
+		"sql/0001_create_widgets.down.sql": {Data: []byte("DROP TABLE widgets;")},
+		"sql/0002_add_name.up.sql":         {Data: []byte("ALTER TABLE widgets ADD COLUMN name TEXT; CREATE INDEX widgets_name ON widgets (name);")},
+		"sql/0002_add_name.down.sql":       {Data: []byte("DROP INDEX widgets_name; ALTER TABLE widgets DROP COLUMN name;")},
+		"sql/README.md":                    {Data: []byte("ignored")},
+	}
+}
+
+func (m *MigrationsTestSuite) TestUp_AppliesPendingMigrationsOnce() {
+	migrator := m.migrator()
+
+	m.NoError(migrator.Up(m.ctx))
+	m.NoError(migrator.Up(m.ctx))
+
+	m.True(m.conn.Migrator().HasColumn("widgets", "name"))
+	m.Equal(int64(2), m.history())
+}
+
+func (m *MigrationsTestSuite) TestDown_RevertsMostRecentMigrations() {
+	migrator := m.migrator()
+	m.Require().NoError(migrator.Up(m.ctx))
+
+	m.NoError(migrator.Down(m.ctx, 1))
+
+	m.True(m.conn.Migrator().HasTable("widgets"))
+	m.False(m.conn.Migrator().HasColumn("widgets", "name"))
+	m.Equal(int64(1), m.history())
+}
+
+func (m *MigrationsTestSuite) TestUp_EditedMigration_ReportsChecksumMismatch() {
+	m.Require().NoError(m.migrator().Up(m.ctx))
+
+	m.source["sql/0001_create_widgets.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE widgets (id BIGINT PRIMARY KEY);")}
+
+	err := m.migrator().Up(m.ctx)
+
+	m.Equal(errs.ErrTypeConfiguration, errs.GetType(err))
+	m.Contains(err.Error(), "checksum mismatch for migration 1")
+}
+
+func (m *MigrationsTestSuite) TestUp_LockHeldElsewhere_TimesOut() {
+	m.Require().NoError(m.migrator().Up(m.ctx))
+	m.Require().NoError(m.conn.Exec("INSERT INTO schema_history_lock VALUES (1, 'other', ?)", time.Now().UnixNano()).Error)
+
+	migrator, err := migrations.New(m.conn, m.source, migrations.WithDirectory("sql"), migrations.WithLockTimeout(time.Millisecond, 0))
+	m.Require().NoError(err)
+
+	m.Equal(errs.ErrTypeTimeout, errs.GetType(migrator.Up(m.ctx)))
+}
+
+func (m *MigrationsTestSuite) TestUp_AbandonedLockIsRemoved() {
+	m.Require().NoError(m.migrator().Up(m.ctx))
+	m.Require().NoError(m.conn.Exec("INSERT INTO schema_history_lock VALUES (1, 'other', 0)").Error)
+
+	m.NoError(m.migrator().Up(m.ctx))
+}
+
+func (m *MigrationsTestSuite) TestNew_MissingUpFile_IsConfigurationError() {
+	delete(m.source, "sql/0002_add_name.up.sql")
+
+	_, err := migrations.New(m.conn, m.source, migrations.WithDirectory("sql"))
+
+	m.Equal(errs.ErrTypeConfiguration, errs.GetType(err))
+}
+
+func (m *MigrationsTestSuite) TestCommand_RunsAgainstAdapter() {
+	adapter := &testAdapter{m.conn}
+
+	m.NoError(migrations.NewUpCommand(m.ctx, adapter, m.source, migrations.WithDirectory("sql")).Run())
+	m.Equal(int64(2), m.history())
+
+	m.NoError(migrations.NewDownCommand(m.ctx, adapter, m.source, 2, migrations.WithDirectory("sql")).Run())
+	m.False(m.conn.Migrator().HasTable("widgets"))
+}
+
+func (m *MigrationsTestSuite) migrator() *migrations.Migrator {
+	migrator, err := migrations.New(m.conn, m.source, migrations.WithDirectory("sql"))
+	m.Require().NoError(err)
+
+	return migrator
+}
+
+func (m *MigrationsTestSuite) history() int64 {
+	var count int64
+	m.Require().NoError(m.conn.Table(migrations.DefaultTable).Count(&count).Error)
+
+	return count
+}
+
+func TestMigrations(t *testing.T) {
+	suite.Run(t, new(MigrationsTestSuite))
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+type Option func(*Migrator)
+
+// WithLogger provides the logger used to report the progress of the Migrator
+func WithLogger(logger utils.Logger) Option {
+	return func(m *Migrator) {
+		m.logger = logger
+	}
+}
+
+// WithDirectory sets the directory, within the source, that contains the migration files
+func WithDirectory(dir string) Option {
+	return func(m *Migrator) {
+		m.dir = dir
+	}
+}
+
+// WithTable sets the name of the schema history table, the lock table is named after it
+func WithTable(table string) Option {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// WithLockTimeout sets how long to wait for another replica to finish migrating and how
+// long a lock may be held before it is considered abandoned
+func WithLockTimeout(timeout, ttl time.Duration) Option {
+	return func(m *Migrator) {
+		if timeout != 0 {
+			m.lockTimeout = timeout
+		}
+		if ttl != 0 {
+			m.lockTTL = ttl
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// Migration is a single versioned change to the schema
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// record is a row within the schema history table
+type record struct {
+	Version  int64
+	Name     string
+	Checksum string
+}
+
+// Migrator applies versioned migrations to a database, recording each within a schema
+// history table
+//
+// A lock table acts as an advisory lock (in a manner supported by both CockroachDB and
+// SQLite) so that when several replicas start together only one of them migrates.
+type Migrator struct {
+	conn       *gorm.DB
+	logger     utils.Logger
+	migrations []Migration
+
+	dir   string
+	table string
+	owner string
+
+	lockTimeout time.Duration
+	lockTTL     time.Duration
+}
+
+// Migrations returns each of the migrations known to the Migrator in version order
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up will apply each of the migrations that have not yet been applied
+//
+// An error of type errs.ErrTypeConfiguration is returned if a migration that has already
+// been applied has since been edited (or removed)
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		known := make(map[int64]Migration, len(m.migrations))
+		for _, migration := range m.migrations {
+			known[migration.Version] = migration
+		}
+
+		for _, rec := range applied {
+			migration, exists := known[rec.Version]
+			if !exists {
+				return errs.Errorf(errs.ErrTypeConfiguration, "applied migration %d (%s) is missing", rec.Version, rec.Name)
+			}
+			if migration.Checksum != rec.Checksum {
+				return errs.Errorf(errs.ErrTypeConfiguration, "checksum mismatch for migration %d (%s)", rec.Version, rec.Name)
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, exists := applied[migration.Version]; exists {
+				continue
+			}
+
+			err = conn.Transaction(func(tx *gorm.DB) error {
+				if err := exec(tx, migration.Up); err != nil {
+					return err
+				}
+
+				return tx.Table(m.table).Create(&record{migration.Version, migration.Name, migration.Checksum}).Error
+			})
+			if err != nil {
+				return errs.Wrapf(err, errs.ErrTypeDatabase, "unable to apply migration %d (%s)", migration.Version, migration.Name)
+			}
+
+			m.infof("applied migration %d (%s)", migration.Version, migration.Name)
+		}
+
+		return nil
+	})
+}
+
+// Down will revert the specified number of the most recently applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		for idx := len(m.migrations) - 1; idx >= 0 && steps > 0; idx-- {
+			migration := m.migrations[idx]
+
+			if _, exists := applied[migration.Version]; !exists {
+				continue
+			}
+
+			if strings.TrimSpace(migration.Down) == "" {
+				return errs.Errorf(errs.ErrTypeConfiguration, "migration %d (%s) cannot be reverted", migration.Version, migration.Name)
+			}
+
+			err = conn.Transaction(func(tx *gorm.DB) error {
+				if err := exec(tx, migration.Down); err != nil {
+					return err
+				}
+
+				return tx.Table(m.table).Where("version = ?", migration.Version).Delete(&record{}).Error
+			})
+			if err != nil {
+				return errs.Wrapf(err, errs.ErrTypeDatabase, "unable to revert migration %d (%s)", migration.Version, migration.Name)
+			}
+
+			m.infof("reverted migration %d (%s)", migration.Version, migration.Name)
+			steps--
+		}
+
+		return nil
+	})
+}
+
+// applied returns the schema history keyed by version
+func (m *Migrator) applied(conn *gorm.DB) (map[int64]record, error) {
+	var records []record
+
+	err := conn.Table(m.table).Select("version", "name", "checksum").Find(&records).Error
+	if err != nil {
+		return nil, errs.Wrap(err, errs.ErrTypeDatabase, "unable to read schema history")
+	}
+
+	applied := make(map[int64]record, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+
+	return applied, nil
+}
+
+// withLock will create the history and lock tables (if necessary) and then execute fn
+// once the lock has been acquired, releasing it afterwards
+func (m *Migrator) withLock(ctx context.Context, fn func(*gorm.DB) error) error {
+	conn := m.conn.WithContext(ctx)
+
+	err := exec(conn, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, checksum VARCHAR(64) NOT NULL)",
+		m.table))
+	if err == nil {
+		err = exec(conn, fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s_lock (id INT PRIMARY KEY, owner VARCHAR(64) NOT NULL, acquired_at BIGINT NOT NULL)",
+			m.table))
+	}
+	if err != nil {
+		return errs.Wrap(err, errs.ErrTypeDatabase, "unable to create schema history")
+	}
+
+	err = m.lock(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	// released without the context so that the lock is not left behind on cancellation...
+	defer m.unlock(m.conn)
+
+	return fn(conn)
+}
+
+// lock will repeatedly attempt to acquire the lock until either it is acquired or the
+// lock timeout expires... a lock held for longer than the lock TTL is considered abandoned
+func (m *Migrator) lock(ctx context.Context, conn *gorm.DB) error {
+	deadline := time.Now().Add(m.lockTimeout)
+	table := m.table + "_lock"
+
+	for {
+		now := time.Now()
+
+		err := conn.Exec(fmt.Sprintf("INSERT INTO %s (id, owner, acquired_at) VALUES (?, ?, ?)", table),
+			lockID, m.owner, now.UnixNano()).Error
+		if err == nil {
+			return nil
+		}
+
+		stale := conn.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ? AND acquired_at < ?", table),
+			lockID, now.Add(-m.lockTTL).UnixNano())
+		if stale.Error == nil && stale.RowsAffected > 0 {
+			m.infof("removed abandoned migration lock")
+			continue
+		}
+
+		if now.After(deadline) {
+			return errs.Wrap(err, errs.ErrTypeTimeout, "unable to acquire migration lock")
+		}
+
+		m.infof("waiting for migration lock")
+
+		select {
+		case <-ctx.Done():
+			return errs.Wrap(ctx.Err(), errs.ErrTypeTimeout, "unable to acquire migration lock")
+		case <-time.After(DefaultPollInterval):
+		}
+	}
+}
+
+// unlock will release the lock, provided it is still held by this Migrator
+func (m *Migrator) unlock(conn *gorm.DB) {
+	err := conn.Exec(fmt.Sprintf("DELETE FROM %s_lock WHERE id = ? AND owner = ?", m.table), lockID, m.owner).Error
+	if err != nil && m.logger != nil {
+		m.logger.Errorf("unable to release migration lock: %s", err)
+	}
+}
+
+// infof will log the message if a logger has been provided
+func (m *Migrator) infof(format string, args ...any) {
+	if m.logger != nil {
+		m.logger.Infof(format, args...)
+	}
+}
+
+// exec will execute the (possibly multi-statement) SQL unless it is empty
+func exec(conn *gorm.DB, sql string) error {
+	if strings.TrimSpace(sql) == "" {
+		return nil
+	}
+
+	return conn.Exec(sql).Error
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"database/sql/driver"
+	"reflect"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/djmarrerajr/common-lib/errs"
+)
+
+// Operator is the comparison applied by a Filter
+type Operator string
+
+// nolint: unused
+const (
+	OpEqual          Operator = "="
+	OpNotEqual       Operator = "<>"
+	OpGreater        Operator = ">"
+	OpGreaterOrEqual Operator = ">="
+	OpLess           Operator = "<"
+	OpLessOrEqual    Operator = "<="
+	OpIn             Operator = "IN"
+	OpLike           Operator = "LIKE"
+	OpIsNull         Operator = "IS NULL"
+	OpIsNotNull      Operator = "IS NOT NULL"
+)
+
+// Filter restricts the entities returned by a Repository, the Field may be either the
+// name of the struct field or the name of the column it maps to
+type Filter struct {
+	Field string
+	Op    Operator
+	Value any
+}
+
+// Sort orders the entities returned by a Repository, the Field may be either the name
+// of the struct field or the name of the column it maps to
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Query describes the entities that should be returned by a Repository's List
+//
+// Results are paginated using an opaque cursor; the NextCursor of one Page is provided
+// as the Cursor of the Query for the next.  The Query must otherwise remain the same.
+type Query struct {
+	Filters []Filter
+	Sort    []Sort
+	Limit   int
+	Cursor  string
+}
+
+// Page is a single page of the entities returned by a Repository's List, NextCursor
+// will be empty once the final page has been returned
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// Where is a convenience function for the construction of a Filter
+func Where(field string, op Operator, value any) Filter {
+	return Filter{field, op, value}
+}
+
+// Eq is a convenience function for the construction of an equality Filter
+func Eq(field string, value any) Filter {
+	return Filter{field, OpEqual, value}
+}
+
+// expression will return the clause.Expression represented by the Filter... the field is
+// resolved against the model's schema so that only known columns can be referenced
+func (f Filter) expression(model *schema.Schema) (clause.Expression, error) {
+	field, err := lookupField(model, f.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+
+	switch f.Op {
+	case OpEqual, "":
+		return clause.Eq{Column: column, Value: f.Value}, nil
+	case OpNotEqual:
+		return clause.Neq{Column: column, Value: f.Value}, nil
+	case OpGreater:
+		return clause.Gt{Column: column, Value: f.Value}, nil
+	case OpGreaterOrEqual:
+		return clause.Gte{Column: column, Value: f.Value}, nil
+	case OpLess:
+		return clause.Lt{Column: column, Value: f.Value}, nil
+	case OpLessOrEqual:
+		return clause.Lte{Column: column, Value: f.Value}, nil
+	case OpIn:
+		values, err := f.values()
+		if err != nil {
+			return nil, err
+		}
+		return clause.IN{Column: column, Values: values}, nil
+	case OpLike:
+		return clause.Like{Column: column, Value: f.Value}, nil
+	case OpIsNull:
+		return clause.Eq{Column: column, Value: nil}, nil
+	case OpIsNotNull:
+		return clause.Neq{Column: column, Value: nil}, nil
+	default:
+		return nil, errs.Errorf(errs.ErrTypeValidation, "unsupported filter operator: %s", f.Op)
+	}
+}
+
+// values will return the Filter's value, which must be a slice or array (i.e. a []string or
+// []uuid.UUID), as the list of values required by the IN operator... a driver.Valuer (i.e.
+// a uuid.UUID) being a single value rather than a list of them
+func (f Filter) values() ([]any, error) {
+	if values, OK := f.Value.([]any); OK {
+		return values, nil
+	}
+
+	value := reflect.ValueOf(f.Value)
+	_, valuer := f.Value.(driver.Valuer)
+
+	if valuer || (value.Kind() != reflect.Slice && value.Kind() != reflect.Array) {
+		return nil, errs.Errorf(errs.ErrTypeValidation, "filter on '%s' requires a slice or array for the IN operator", f.Field)
+	}
+
+	values := make([]any, value.Len())
+	for idx := range values {
+		values[idx] = value.Index(idx).Interface()
+	}
+
+	return values, nil
+}
+
+// lookupField will return the schema field having the specified struct or column name
+func lookupField(model *schema.Schema, name string) (*schema.Field, error) {
+	field := model.LookUpField(name)
+	if field == nil || field.DBName == "" {
+		return nil, errs.Errorf(errs.ErrTypeValidation, "unknown field '%s' for %s", name, model.Name)
+	}
+
+	return field, nil
+}
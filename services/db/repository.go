@@ -0,0 +1,367 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/djmarrerajr/common-lib/errs"
+
+	stderr "errors"
+)
+
+// Repository provides typed access to the entities of any GORM model using the
+// connection provided by the Adapter
+//
+// Models may participate in the Repository's lifecycle by implementing any of the
+// CreateHook, UpdateHook, DeleteHook or LoadHook interfaces
+type Repository[T any] struct {
+	adapter Adapter
+}
+
+// NewRepository will instantiate and return a Repository for the model T
+func NewRepository[T any](adapter Adapter) *Repository[T] {
+	return &Repository[T]{adapter}
+}
+
+// Create will insert the entity
+func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
+	if hook, OK := any(entity).(CreateHook); OK {
+		if err := hook.OnCreate(ctx); err != nil {
+			return err
+		}
+	}
+
+	return wrapError(r.conn(ctx).Create(entity).Error, "unable to create entity")
+}
+
+// Get will return the entity having the specified primary key or an error of
+// type errs.ErrTypeNotFound if no such entity exists
+func (r *Repository[T]) Get(ctx context.Context, id any) (*T, error) {
+	model, err := r.schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if model.PrioritizedPrimaryField == nil {
+		return nil, errs.Errorf(errs.ErrTypeConfiguration, "%s has no primary key", model.Name)
+	}
+
+	column := clause.Column{Table: clause.CurrentTable, Name: model.PrioritizedPrimaryField.DBName}
+
+	entity := new(T)
+
+	err = r.conn(ctx).Where(clause.Eq{Column: column, Value: id}).First(entity).Error
+	if err != nil {
+		return nil, wrapError(err, "unable to get entity")
+	}
+
+	if err = afterLoad(ctx, entity); err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+// Update will save all of the entity's fields
+func (r *Repository[T]) Update(ctx context.Context, entity *T) error {
+	if hook, OK := any(entity).(UpdateHook); OK {
+		if err := hook.OnUpdate(ctx); err != nil {
+			return err
+		}
+	}
+
+	return wrapError(r.conn(ctx).Save(entity).Error, "unable to update entity")
+}
+
+// Delete will remove the entity (or soft-delete it if the model supports it)
+func (r *Repository[T]) Delete(ctx context.Context, entity *T) error {
+	if hook, OK := any(entity).(DeleteHook); OK {
+		if err := hook.OnDelete(ctx); err != nil {
+			return err
+		}
+	}
+
+	return wrapError(r.conn(ctx).Delete(entity).Error, "unable to delete entity")
+}
+
+// Count will return the number of entities that satisfy all of the filters
+func (r *Repository[T]) Count(ctx context.Context, filters ...Filter) (int64, error) {
+	var count int64
+
+	tx, err := r.filtered(ctx, filters)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Count(&count).Error
+	if err != nil {
+		return 0, wrapError(err, "unable to count entities")
+	}
+
+	return count, nil
+}
+
+// Exists will determine whether or not any entity satisfies all of the filters
+func (r *Repository[T]) Exists(ctx context.Context, filters ...Filter) (bool, error) {
+	var found []T
+
+	tx, err := r.filtered(ctx, filters)
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Limit(1).Find(&found).Error
+	if err != nil {
+		return false, wrapError(err, "unable to determine existence")
+	}
+
+	return len(found) > 0, nil
+}
+
+// List will return a single Page of the entities that satisfy the Query, ordered by the
+// requested fields followed by the primary key (to guarantee a stable order)
+func (r *Repository[T]) List(ctx context.Context, query Query) (Page[T], error) {
+	page := Page[T]{}
+
+	model, err := r.schema(ctx)
+	if err != nil {
+		return page, err
+	}
+
+	keys, err := sortKeys(model, query.Sort)
+	if err != nil {
+		return page, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	} else if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	tx, err := r.filtered(ctx, query.Filters)
+	if err != nil {
+		return page, err
+	}
+
+	// resume from where the previous page left off...
+	if query.Cursor != "" {
+		after, err := keysetExpression(keys, query.Cursor)
+		if err != nil {
+			return page, err
+		}
+
+		tx = tx.Where(after)
+	}
+
+	for _, key := range keys {
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: key.field.DBName}, Desc: key.desc})
+	}
+
+	// fetch one more than we need so we know whether or not another page exists...
+	err = tx.Limit(limit + 1).Find(&page.Items).Error
+	if err != nil {
+		return page, wrapError(err, "unable to list entities")
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+
+		page.NextCursor, err = encodeCursor(ctx, keys, &page.Items[limit-1])
+		if err != nil {
+			return page, err
+		}
+	}
+
+	for idx := range page.Items {
+		if err = afterLoad(ctx, &page.Items[idx]); err != nil {
+			return page, err
+		}
+	}
+
+	return page, nil
+}
+
+// conn returns the transaction carried by the context or, if there isn't one, the
+// adapter's connection
+func (r *Repository[T]) conn(ctx context.Context) *gorm.DB {
+	if tx, OK := TxFromContext(ctx); OK {
+		return tx.WithContext(ctx)
+	}
+
+	return r.adapter.Conn(ctx)
+}
+
+// filtered returns a query against the model restricted by each of the filters
+func (r *Repository[T]) filtered(ctx context.Context, filters []Filter) (*gorm.DB, error) {
+	model, err := r.schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.conn(ctx).Model(new(T))
+
+	for _, filter := range filters {
+		expr, err := filter.expression(model)
+		if err != nil {
+			return nil, err
+		}
+
+		tx = tx.Where(expr)
+	}
+
+	return tx, nil
+}
+
+// schema returns the parsed GORM schema of the model
+func (r *Repository[T]) schema(ctx context.Context) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: r.conn(ctx)}
+
+	err := stmt.Parse(new(T))
+	if err != nil {
+		return nil, errs.Wrap(err, errs.ErrTypeConfiguration, "unable to parse model")
+	}
+
+	return stmt.Schema, nil
+}
+
+// sortKey is a resolved Sort
+type sortKey struct {
+	field *schema.Field
+	desc  bool
+}
+
+// sortKeys will resolve the requested sort fields, adding the primary key(s) as the final
+// tie-breaker so that the ordering (and therefore the cursor) is always deterministic
+func sortKeys(model *schema.Schema, sorts []Sort) ([]sortKey, error) {
+	keys := make([]sortKey, 0, len(sorts)+len(model.PrimaryFields))
+	seen := make(map[string]bool)
+
+	for _, sort := range sorts {
+		field, err := lookupField(model, sort.Field)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, sortKey{field, sort.Desc})
+		seen[field.DBName] = true
+	}
+
+	for _, field := range model.PrimaryFields {
+		if !seen[field.DBName] {
+			keys = append(keys, sortKey{field: field})
+		}
+	}
+
+	return keys, nil
+}
+
+// keysetExpression will construct the expression that selects those rows that come after
+// the cursor, i.e. for keys (a, b): a > ? OR (a = ? AND b > ?)
+func keysetExpression(keys []sortKey, cursor string) (clause.Expression, error) {
+	values, err := decodeCursor(keys, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var alternatives []clause.Expression
+
+	for idx, key := range keys {
+		var conditions []clause.Expression
+
+		for prev := 0; prev < idx; prev++ {
+			conditions = append(conditions, clause.Eq{Column: column(keys[prev]), Value: values[prev]})
+		}
+
+		if key.desc {
+			conditions = append(conditions, clause.Lt{Column: column(key), Value: values[idx]})
+		} else {
+			conditions = append(conditions, clause.Gt{Column: column(key), Value: values[idx]})
+		}
+
+		alternatives = append(alternatives, clause.And(conditions...))
+	}
+
+	return clause.Or(alternatives...), nil
+}
+
+// encodeCursor will capture the sort key values of the entity as an opaque cursor
+func encodeCursor(ctx context.Context, keys []sortKey, entity any) (string, error) {
+	values := make([]any, len(keys))
+
+	for idx, key := range keys {
+		values[idx], _ = key.field.ValueOf(ctx, reflect.ValueOf(entity).Elem())
+	}
+
+	buff, err := json.Marshal(values)
+	if err != nil {
+		return "", errs.Wrap(err, errs.ErrTypeMarshal, "unable to encode cursor")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buff), nil
+}
+
+// decodeCursor will return the sort key values captured within the cursor, each having
+// been decoded in to the type of its respective field
+func decodeCursor(keys []sortKey, cursor string) ([]any, error) {
+	buff, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errs.Wrap(err, errs.ErrTypeValidation, "invalid cursor")
+	}
+
+	var raw []json.RawMessage
+
+	err = json.Unmarshal(buff, &raw)
+	if err != nil || len(raw) != len(keys) {
+		return nil, errs.New(errs.ErrTypeValidation, "invalid cursor")
+	}
+
+	values := make([]any, len(keys))
+
+	for idx, key := range keys {
+		value := reflect.New(key.field.FieldType)
+
+		err = json.Unmarshal(raw[idx], value.Interface())
+		if err != nil {
+			return nil, errs.Wrap(err, errs.ErrTypeValidation, "invalid cursor")
+		}
+
+		values[idx] = value.Elem().Interface()
+	}
+
+	return values, nil
+}
+
+// column returns the column used by the sort key
+func column(key sortKey) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: key.field.DBName}
+}
+
+// afterLoad will invoke the entity's LoadHook, if it has one
+func afterLoad(ctx context.Context, entity any) error {
+	if hook, OK := entity.(LoadHook); OK {
+		return hook.OnLoad(ctx)
+	}
+
+	return nil
+}
+
+// wrapError will associate the appropriate ErrorType with an error returned by GORM
+func wrapError(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	if stderr.Is(err, gorm.ErrRecordNotFound) {
+		return errs.Wrap(err, errs.ErrTypeNotFound, message)
+	}
+
+	return errs.Wrap(err, errs.ErrTypeDatabase, message)
+}
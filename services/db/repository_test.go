@@ -0,0 +1,255 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/db"
+)
+
+type widget struct {
+	ID     int `gorm:"primaryKey"`
+	Name   string
+	Weight int
+	Loaded bool `gorm:"-"`
+}
+
+func (w *widget) OnCreate(context.Context) error {
+	if w.Name == "" {
+		return errs.New(errs.ErrTypeValidation, "name is required")
+	}
+	return nil
+}
+
+func (w *widget) OnLoad(context.Context) error {
+	w.Loaded = true
+	return nil
+}
+
+type testAdapter struct {
+	conn   *gorm.DB
+	policy db.RetryPolicy
+}
+
+func (t *testAdapter) Start(context.Context, *errgroup.Group) error { return nil }
+func (t *testAdapter) Stop() error                                  { return nil }
+func (t *testAdapter) Conn(ctx context.Context) *gorm.DB            { return t.conn.WithContext(ctx) }
+
+func (t *testAdapter) RunInTx(ctx context.Context, fn db.TxFunc) error {
+	return db.RunInTx(ctx, t.conn, t.policy, fn)
+}
+
+type RepositoryTestSuite struct {
+	suite.Suite
+
+	ctx     context.Context
+	adapter *testAdapter
+	repo    *db.Repository[widget]
+}
+
+func (r *RepositoryTestSuite) SetupTest() {
+	conn, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	r.Require().NoError(err)
+	r.Require().NoError(conn.AutoMigrate(&widget{}))
+
+	r.ctx = context.Background()
+	r.adapter = &testAdapter{conn, db.RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}}
+	r.repo = db.NewRepository[widget](r.adapter)
+
+	for i := 1; i <= 5; i++ {
+		r.Require().NoError(r.repo.Create(r.ctx, &widget{Name: fmt.Sprintf("w%d", i), Weight: i % 3}))
+	}
+}
+
+func (r *RepositoryTestSuite) TestCreate_HookErrorPreventsInsert() {
+	err := r.repo.Create(r.ctx, &widget{})
+
+	r.Equal(errs.ErrTypeValidation, errs.GetType(err))
+
+	count, _ := r.repo.Count(r.ctx)
+	r.Equal(int64(5), count)
+}
+
+func (r *RepositoryTestSuite) TestGet_ReturnsEntityAndInvokesLoadHook() {
+	entity, err := r.repo.Get(r.ctx, 2)
+
+	r.NoError(err)
+	r.Equal("w2", entity.Name)
+	r.True(entity.Loaded)
+}
+
+func (r *RepositoryTestSuite) TestGet_Missing_IsNotFound() {
+	_, err := r.repo.Get(r.ctx, 42)
+
+	r.Equal(errs.ErrTypeNotFound, errs.GetType(err))
+}
+
+func (r *RepositoryTestSuite) TestUpdateAndDelete() {
+	entity, _ := r.repo.Get(r.ctx, 1)
+	entity.Name = "renamed"
+
+	r.NoError(r.repo.Update(r.ctx, entity))
+	r.NoError(r.repo.Delete(r.ctx, &widget{ID: 3}))
+
+	exists, err := r.repo.Exists(r.ctx, db.Eq("name", "renamed"))
+	r.NoError(err)
+	r.True(exists)
+
+	exists, _ = r.repo.Exists(r.ctx, db.Eq("ID", 3))
+	r.False(exists)
+}
+
+func (r *RepositoryTestSuite) TestCount_WithFilters() {
+	count, err := r.repo.Count(r.ctx, db.Where("Weight", db.OpGreater, 0), db.Where("name", db.OpNotEqual, "w1"))
+
+	r.NoError(err)
+	r.Equal(int64(3), count)
+}
+
+func (r *RepositoryTestSuite) TestCount_InAcceptsAnySliceOrArray() {
+	tests := []struct {
+		name   string
+		filter db.Filter
+		count  int64
+	}{
+		{name: "[]any", filter: db.Where("name", db.OpIn, []any{"w1", "w3", "w9"}), count: 2},
+		{name: "typed slice", filter: db.Where("name", db.OpIn, []string{"w1", "w3", "w9"}), count: 2},
+		{name: "array", filter: db.Where("weight", db.OpIn, [2]int{0, 2}), count: 3},
+	}
+
+	for _, test := range tests {
+		r.Run(test.name, func() {
+			count, err := r.repo.Count(r.ctx, test.filter)
+
+			r.Require().NoError(err)
+			r.Equal(test.count, count)
+		})
+	}
+
+	_, err := r.repo.Count(r.ctx, db.Where("name", db.OpIn, "w1"))
+	r.Equal(errs.ErrTypeValidation, errs.GetType(err))
+}
+
+func (r *RepositoryTestSuite) TestCount_UnknownField_IsValidationError() {
+	_, err := r.repo.Count(r.ctx, db.Eq("colour", "red"))
+
+	r.Equal(errs.ErrTypeValidation, errs.GetType(err))
+}
+
+func (r *RepositoryTestSuite) TestList_PaginatesWithCursor() {
+	query := db.Query{Sort: []db.Sort{{Field: "weight", Desc: true}}, Limit: 2}
+
+	var names []string
+
+	for pages := 0; pages < 5; pages++ {
+		page, err := r.repo.List(r.ctx, query)
+		r.Require().NoError(err)
+
+		for _, item := range page.Items {
+			r.True(item.Loaded)
+			names = append(names, item.Name)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	r.Equal([]string{"w2", "w5", "w1", "w4", "w3"}, names)
+}
+
+func (r *RepositoryTestSuite) TestList_InvalidCursor_IsValidationError() {
+	_, err := r.repo.List(r.ctx, db.Query{Cursor: "not-a-cursor"})
+
+	r.Equal(errs.ErrTypeValidation, errs.GetType(err))
+}
+
+func (r *RepositoryTestSuite) TestRunInTx_RetriesSerializationFailures() {
+	for _, savepoint := range []bool{false, true} {
+		r.adapter.policy.UseSavepoint = savepoint
+
+		attempts, retries := 0, 0
+		r.adapter.policy.OnRetry = func(error) { retries++ }
+
+		err := r.adapter.RunInTx(r.ctx, func(ctx context.Context, _ *gorm.DB) error {
+			attempts++
+
+			err := r.repo.Create(ctx, &widget{Name: fmt.Sprintf("tx%d", attempts)})
+			if err == nil && attempts%2 == 1 {
+				err = serializationFailure{}
+			}
+
+			return err
+		})
+
+		r.NoError(err)
+		r.Equal(2, attempts)
+		r.Equal(1, retries)
+
+		count, _ := r.repo.Count(r.ctx, db.Where("name", db.OpLike, "tx%"))
+		r.Equal(int64(1), count, "the failed attempt should have been rolled back")
+
+		r.Require().NoError(r.adapter.conn.Where("name LIKE ?", "tx%").Delete(&widget{}).Error)
+	}
+}
+
+func (r *RepositoryTestSuite) TestRunInTx_GivesUpAfterMaxRetries() {
+	attempts := 0
+
+	err := r.adapter.RunInTx(r.ctx, func(context.Context, *gorm.DB) error {
+		attempts++
+		return serializationFailure{}
+	})
+
+	r.True(db.IsRetryable(err))
+	r.Equal(errs.ErrTypeDatabase, errs.GetType(err))
+	r.Equal(3, attempts)
+}
+
+func (r *RepositoryTestSuite) TestRunInTx_BackoffIsScaledByJitter() {
+	jitters := 0
+	r.adapter.policy.InitialBackoff = time.Hour
+	r.adapter.policy.Jitter = func() float64 { jitters++; return 0 }
+
+	attempts := 0
+
+	err := r.adapter.RunInTx(r.ctx, func(context.Context, *gorm.DB) error {
+		attempts++
+		return serializationFailure{}
+	})
+
+	r.True(db.IsRetryable(err))
+	r.Equal(3, attempts)
+	r.Equal(2, jitters, "each retry should have been jittered")
+}
+
+func (r *RepositoryTestSuite) TestRunInTx_HonorsContextCancellation() {
+	ctx, cancel := context.WithCancel(r.ctx)
+	r.adapter.policy.InitialBackoff = time.Minute
+
+	err := r.adapter.RunInTx(ctx, func(context.Context, *gorm.DB) error {
+		cancel()
+		return serializationFailure{}
+	})
+
+	r.ErrorIs(err, context.Canceled)
+}
+
+type serializationFailure struct{}
+
+func (serializationFailure) Error() string    { return "restart transaction" }
+func (serializationFailure) SQLState() string { return db.RetryableSQLState }
+
+func TestRepository(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/djmarrerajr/common-lib/services/db"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// NewAdapterFromEnv will instantiate and return a SQLite adapter for the database file
+// named within the environment... if no file is named an in-memory database is used
+func NewAdapterFromEnv(env utils.Environ, appCtx shared.ApplicationContext, options ...Option) (*SQLite, error) {
+	path, OK := env.Get(db.SQLitePathEnvKey)
+	if !OK || path == "" {
+		return NewInMemoryAdapter(appCtx, options...), nil
+	}
+
+	return NewAdapter(appCtx, path, options...), nil
+}
+
+// NewAdapter will instantiate and return a SQLite adapter for the database file at the
+// specified path (or DSN)
+func NewAdapter(appCtx shared.ApplicationContext, path string, options ...Option) *SQLite {
+	logger := appCtx.Logger.Named("db")
+	newopt := []Option{WithLogger(logger.WithCtx(appCtx.RootCtx))}
+
+	return newSQLite(appCtx, path, append(newopt, options...)...)
+}
+
+// NewInMemoryAdapter will instantiate and return an adapter for a private, in-memory,
+// database that exists only for as long as the adapter is running
+func NewInMemoryAdapter(appCtx shared.ApplicationContext, options ...Option) *SQLite {
+	path := fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString())
+
+	return NewAdapter(appCtx, path, options...)
+}
+
+func newSQLite(appCtx shared.ApplicationContext, path string, options ...Option) *SQLite {
+	db := SQLite{
+		AppCtx:   appCtx,
+		path:     path,
+		maxConn:  db.DefaultMaxOpenConnections,
+		idleConn: db.DefaultMaxIdleConnections,
+		retry:    db.DefaultRetryPolicy(),
+	}
+
+	for _, option := range options {
+		option(&db)
+	}
+
+	return &db
+}
@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"io/fs"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/services/db"
+	"github.com/djmarrerajr/common-lib/services/db/migrations"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+type Option func(*SQLite)
+
+func WithLogger(logger utils.Logger) Option {
+	return func(sd *SQLite) {
+		sd.logger = logger
+	}
+}
+
+func WithConnectionLimits(maxConn, idleConn, maxTime, idleTime int) Option {
+	return func(sd *SQLite) {
+		if maxConn != 0 {
+			sd.maxConn = maxConn
+		}
+		if idleConn != 0 {
+			sd.idleConn = idleConn
+		}
+		if maxTime != 0 {
+			sd.maxTime = time.Duration(maxTime) * time.Second
+		}
+		if idleTime != 0 {
+			sd.idleTime = time.Duration(idleTime) * time.Second
+		}
+	}
+}
+
+// WithRetryPolicy replaces the policy used to re-attempt transactions that fail due to contention
+func WithRetryPolicy(policy db.RetryPolicy) Option {
+	return func(sd *SQLite) {
+		sd.retry = policy
+	}
+}
+
+// WithMigrations will apply the migrations contained within the source (typically an
+// embed.FS) each time the adapter is started
+func WithMigrations(source fs.FS, options ...migrations.Option) Option {
+	return func(sd *SQLite) {
+		sd.migrations = source
+		sd.migrationOpts = options
+	}
+}
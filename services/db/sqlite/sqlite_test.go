@@ -0,0 +1,100 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/health"
+	"github.com/djmarrerajr/common-lib/services/db"
+	"github.com/djmarrerajr/common-lib/services/db/sqlite"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+type account struct {
+	ID      int `gorm:"primaryKey"`
+	Balance float64
+}
+
+type SQLiteTestSuite struct {
+	suite.Suite
+
+	ctx    context.Context
+	appCtx shared.ApplicationContext
+	source fstest.MapFS
+}
+
+func (s *SQLiteTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.appCtx = shared.ApplicationContext{
+		RootCtx: s.ctx,
+		Logger:  utils.NewLogger("INFO"),
+		Health:  health.NewRegistry(),
+	}
+	s.source = fstest.MapFS{
+		"0001_create_account.up.sql": {Data: []byte("CREATE TABLE account (id INTEGER PRIMARY KEY, balance REAL);")},
+	}
+}
+
+func (s *SQLiteTestSuite) TestInMemoryAdapter_MigratesAndServesRepository() {
+	adapter := s.start(sqlite.NewInMemoryAdapter(s.appCtx, sqlite.WithMigrations(s.source)))
+	repo := db.NewRepository[account](adapter)
+
+	s.NoError(repo.Create(s.ctx, &account{ID: 1, Balance: 10}))
+
+	found, err := repo.Get(s.ctx, 1)
+	s.NoError(err)
+	s.Equal(10.0, found.Balance)
+
+	s.Equal(health.StatusUp, s.appCtx.Health.Ready(s.ctx).Status)
+}
+
+func (s *SQLiteTestSuite) TestInMemoryAdapters_AreIsolated() {
+	first := s.start(sqlite.NewInMemoryAdapter(s.appCtx, sqlite.WithMigrations(s.source)))
+	second := s.start(sqlite.NewInMemoryAdapter(s.appCtx))
+
+	s.True(first.Conn(s.ctx).Migrator().HasTable("account"))
+	s.False(second.Conn(s.ctx).Migrator().HasTable("account"))
+}
+
+func (s *SQLiteTestSuite) TestRunInTx_RollsBackOnError() {
+	adapter := s.start(sqlite.NewInMemoryAdapter(s.appCtx, sqlite.WithMigrations(s.source)))
+	repo := db.NewRepository[account](adapter)
+
+	err := adapter.RunInTx(s.ctx, func(ctx context.Context, _ *gorm.DB) error {
+		s.Require().NoError(repo.Create(ctx, &account{ID: 1}))
+		return errs.New(errs.ErrTypeValidation, "insufficient funds")
+	})
+
+	s.Equal(errs.ErrTypeValidation, errs.GetType(err))
+
+	exists, _ := repo.Exists(s.ctx, db.Eq("id", 1))
+	s.False(exists)
+}
+
+func (s *SQLiteTestSuite) TestNewAdapterFromEnv_FileDatabase() {
+	env := utils.NewEnviron(map[string]string{db.SQLitePathEnvKey: s.T().TempDir() + "/test.db"})
+
+	adapter, err := sqlite.NewAdapterFromEnv(env, s.appCtx, sqlite.WithMigrations(s.source))
+	s.Require().NoError(err)
+
+	s.start(adapter)
+	s.True(adapter.Conn(s.ctx).Migrator().HasTable("account"))
+}
+
+func (s *SQLiteTestSuite) start(adapter *sqlite.SQLite) *sqlite.SQLite {
+	s.Require().NoError(adapter.Start(s.ctx, new(errgroup.Group)))
+	s.T().Cleanup(func() { _ = adapter.Stop() })
+
+	return adapter
+}
+
+func TestSQLite(t *testing.T) {
+	suite.Run(t, new(SQLiteTestSuite))
+}
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"strings"
+	"time"
+
+	driver "github.com/glebarez/sqlite"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+
+	"github.com/djmarrerajr/common-lib/observability/health"
+	"github.com/djmarrerajr/common-lib/services/db"
+	"github.com/djmarrerajr/common-lib/services/db/migrations"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+var _ db.Adapter = new(SQLite)
+
+// SQLite is a db.Adapter backed by an embedded (pure Go) SQLite database and is intended
+// for use by tests and local development where a CockroachDB cluster is not available
+type SQLite struct {
+	conn *gorm.DB
+	keep *sql.Conn // holds an in-memory database open for the life of the adapter
+
+	AppCtx shared.ApplicationContext
+	logger utils.Logger
+
+	path string
+
+	maxConn  int
+	idleConn int
+	maxTime  time.Duration
+	idleTime time.Duration
+
+	retry db.RetryPolicy
+
+	migrations    fs.FS
+	migrationOpts []migrations.Option
+}
+
+// Conn returns the database connection bound to the provided context
+func (d *SQLite) Conn(ctx context.Context) *gorm.DB {
+	return d.conn.WithContext(ctx)
+}
+
+// RunInTx will execute the TxFunc within a transaction
+func (d *SQLite) RunInTx(ctx context.Context, fn db.TxFunc) error {
+	return db.RunInTx(ctx, d.conn, d.retry, fn)
+}
+
+func (d *SQLite) Start(ctx context.Context, grp *errgroup.Group) error {
+	conn, err := gorm.Open(driver.Open(d.path),
+		&gorm.Config{
+			NamingStrategy: schema.NamingStrategy{
+				SingularTable: true,
+			},
+			Logger: logger.New(
+				db.NewGormLogger(d.logger.WithCtx(d.AppCtx.RootCtx)),
+				logger.Config{
+					LogLevel: logger.Error,
+				},
+			),
+		})
+	if err != nil {
+		d.logger.Errorf("unable to open database: %s", err)
+		return err
+	}
+
+	sqlDB, err := conn.DB()
+	if err != nil {
+		d.logger.Errorf("unable to configure database options: %s", err)
+		return err
+	}
+
+	sqlDB.SetMaxOpenConns(d.maxConn)
+	sqlDB.SetMaxIdleConns(d.idleConn)
+	sqlDB.SetConnMaxLifetime(d.maxTime)
+	sqlDB.SetConnMaxIdleTime(d.idleTime)
+
+	// an in-memory database is discarded once its last connection is closed...
+	if strings.Contains(d.path, "mode=memory") || strings.Contains(d.path, ":memory:") {
+		d.keep, err = sqlDB.Conn(ctx)
+		if err != nil {
+			d.logger.Errorf("unable to open database: %s", err)
+			return err
+		}
+	}
+
+	grp.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+
+	d.conn = conn
+
+	if d.migrations != nil {
+		err = d.migrate(ctx)
+		if err != nil {
+			d.logger.Errorf("unable to migrate database: %s", err)
+			return err
+		}
+	}
+
+	if d.AppCtx.Health != nil {
+		d.AppCtx.Health.Register(health.Check{
+			Name:     db.HealthCheckName,
+			Checker:  d.ping,
+			Timeout:  db.DefaultHealthCheckTimeout,
+			CacheTTL: db.DefaultHealthCheckCacheTTL,
+			Critical: true,
+		})
+	}
+
+	return nil
+}
+
+// migrate will apply any of the configured migrations that are pending
+func (d *SQLite) migrate(ctx context.Context) error {
+	options := append([]migrations.Option{migrations.WithLogger(d.logger)}, d.migrationOpts...)
+
+	migrator, err := migrations.New(d.conn, d.migrations, options...)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Up(ctx)
+}
+
+// ping is registered as a health check and will verify the database is reachable
+func (d *SQLite) ping(ctx context.Context) error {
+	sqlDB, err := d.conn.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
+func (d *SQLite) Stop() error {
+	if d.conn == nil {
+		return nil
+	}
+
+	if d.keep != nil {
+		_ = d.keep.Close()
+	}
+
+	sqlDB, err := d.conn.DB()
+	if err != nil {
+		return err
+	}
+
+	d.logger.Infof("database connection closed")
+
+	return sqlDB.Close()
+}
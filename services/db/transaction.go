@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/djmarrerajr/common-lib/errs"
+
+	stderr "errors"
+)
+
+type txKey struct{}
+
+// TxFunc is the unit of work executed by RunInTx... the provided context carries the
+// transaction so that any Repository invoked with it will participate in the transaction
+type TxFunc func(ctx context.Context, tx *gorm.DB) error
+
+// RetryPolicy determines how a transaction that failed with a retryable error is re-attempted
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	UseSavepoint   bool           // retry within the transaction using the cockroach_restart savepoint
+	OnRetry        func(error)    // invoked prior to each retry, i.e. to record metrics
+	Jitter         func() float64 // scales each backoff by a value in [0, 1), defaults to rand.Float64
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used unless otherwise configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     DefaultTxMaxRetries,
+		InitialBackoff: DefaultTxInitialBackoff,
+		MaxBackoff:     DefaultTxMaxBackoff,
+	}
+}
+
+// ContextWithTx returns a copy of the context that carries the transaction
+func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the transaction carried by the context, if there is one
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, OK := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, OK && tx != nil
+}
+
+// IsRetryable will determine whether or not the error indicates the transaction failed
+// due to contention and may succeed if re-attempted (i.e. SQLSTATE 40001)
+func IsRetryable(err error) bool {
+	var sqlErr interface{ SQLState() string }
+
+	if stderr.As(err, &sqlErr) {
+		return sqlErr.SQLState() == RetryableSQLState
+	}
+
+	return false
+}
+
+// RunInTx will execute the TxFunc within a transaction on the provided connection, which
+// is committed if the TxFunc succeeds and rolled back otherwise
+//
+// If the transaction fails with a retryable error it will be re-attempted, with exponential
+// backoff and full jitter, as directed by the RetryPolicy.  If the context already carries a transaction the
+// TxFunc simply joins it and any retry is left to the outermost RunInTx.
+func RunInTx(ctx context.Context, conn *gorm.DB, policy RetryPolicy, fn TxFunc) error {
+	if tx, OK := TxFromContext(ctx); OK {
+		return fn(ctx, tx)
+	}
+
+	if policy.UseSavepoint {
+		return wrapTxError(conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return runWithSavepoint(ctx, tx, policy, fn)
+		}))
+	}
+
+	backoff := policy.InitialBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(ContextWithTx(ctx, tx), tx)
+		})
+		if err == nil || !IsRetryable(err) || attempt >= policy.MaxRetries {
+			return wrapTxError(err)
+		}
+
+		if err = policy.wait(ctx, err, &backoff); err != nil {
+			return err
+		}
+	}
+}
+
+// runWithSavepoint implements CockroachDB's client-side retry protocol; on a retryable error
+// the transaction is rolled back to the savepoint and the TxFunc re-attempted
+func runWithSavepoint(ctx context.Context, tx *gorm.DB, policy RetryPolicy, fn TxFunc) error {
+	err := tx.SavePoint(SavepointName).Error
+	if err != nil {
+		return err
+	}
+
+	backoff := policy.InitialBackoff
+
+	for attempt := 0; ; attempt++ {
+		err = fn(ContextWithTx(ctx, tx), tx)
+		if err == nil {
+			err = tx.Exec("RELEASE SAVEPOINT " + SavepointName).Error
+		}
+		if err == nil || !IsRetryable(err) || attempt >= policy.MaxRetries {
+			return err
+		}
+
+		if rerr := tx.RollbackTo(SavepointName).Error; rerr != nil {
+			return rerr
+		}
+
+		if err = policy.wait(ctx, err, &backoff); err != nil {
+			return err
+		}
+	}
+}
+
+// wait will notify the OnRetry hook and then sleep for a random portion of the current backoff
+// (doubling it for next time) unless the context is done first... the jitter prevents contending
+// transactions from retrying in lockstep
+func (p RetryPolicy) wait(ctx context.Context, cause error, backoff *time.Duration) error {
+	if p.OnRetry != nil {
+		p.OnRetry(cause)
+	}
+
+	jitter := p.Jitter
+	if jitter == nil {
+		jitter = rand.Float64
+	}
+
+	timer := time.NewTimer(time.Duration(jitter() * float64(*backoff)))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return errs.Wrap(ctx.Err(), errs.ErrTypeTimeout, "transaction abandoned")
+	case <-timer.C:
+	}
+
+	*backoff *= 2
+	if p.MaxBackoff > 0 && *backoff > p.MaxBackoff {
+		*backoff = p.MaxBackoff
+	}
+
+	return nil
+}
+
+// wrapTxError will associate an ErrorType with a transaction failure, preserving any
+// type already assigned by the TxFunc
+func wrapTxError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return errs.WithTypeFallback(err, errs.ErrTypeDatabase)
+}
@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+)
+
+// ErrCircuitOpen is returned, without a request being made, while the circuit to a host is open
+var ErrCircuitOpen = errs.Sentinel(errs.ErrTypeUnavailable, "circuit breaker open")
+
+// breakers holds the circuit breaker of each host to which requests have been made
+type breakers struct {
+	mu        sync.Mutex
+	threshold int           // consecutive failures at which the circuit opens, zero disables
+	timeout   time.Duration // how long the circuit remains open before a trial request
+	hosts     map[string]*breaker
+	now       func() time.Time
+}
+
+// breaker is the state of the circuit to a single host... it is open while openUntil is in
+// the future, following which a single trial request is permitted (the circuit being
+// half-open) whose outcome either closes or re-opens it
+type breaker struct {
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// allow determines whether or not a request may be made to the host
+func (b *breakers) allow(host string) error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, exists := b.hosts[host]
+	if !exists || state.failures < b.threshold {
+		return nil
+	}
+
+	if b.now().Before(state.openUntil) || state.trial {
+		return ErrCircuitOpen.WithStack()
+	}
+
+	state.trial = true
+
+	return nil
+}
+
+// record updates the state of the host's circuit with the outcome of a request
+func (b *breakers) record(host string, failed bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, exists := b.hosts[host]
+	if !exists {
+		if !failed {
+			return
+		}
+
+		state = new(breaker)
+		b.hosts[host] = state
+	}
+
+	state.trial = false
+
+	if !failed {
+		delete(b.hosts, host)
+		return
+	}
+
+	state.failures++
+	if state.failures >= b.threshold {
+		state.openUntil = b.now().Add(b.timeout)
+	}
+}
+
+// release permits another trial request to the host, should the outcome of the trial have
+// been indeterminate (i.e. the caller cancelled the request)
+func (b *breakers) release(host string) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if state, exists := b.hosts[host]; exists {
+		state.trial = false
+	}
+}
@@ -0,0 +1,32 @@
+package httpclient
+
+import "time"
+
+// nolint: unused
+const (
+	DefaultTimeout          = 30 * time.Second // of each attempt
+	DefaultMaxRetries       = 2
+	DefaultBaseDelay        = 100 * time.Millisecond
+	DefaultMaxDelay         = 2 * time.Second
+	DefaultFailureThreshold = 5
+	DefaultOpenTimeout      = 30 * time.Second
+)
+
+// nolint: unused
+const (
+	TimeoutEnvKey          = "HTTP_CLIENT_TIMEOUT" // seconds
+	MaxRetriesEnvKey       = "HTTP_CLIENT_MAX_RETRIES"
+	BaseDelayEnvKey        = "HTTP_CLIENT_RETRY_BASE_DELAY" // milliseconds
+	MaxDelayEnvKey         = "HTTP_CLIENT_RETRY_MAX_DELAY"  // milliseconds
+	FailureThresholdEnvKey = "HTTP_CLIENT_BREAKER_THRESHOLD"
+	OpenTimeoutEnvKey      = "HTTP_CLIENT_BREAKER_TIMEOUT" // seconds
+)
+
+// nolint: unused
+const (
+	HeaderAccept         = "Accept"
+	HeaderContentType    = "Content-Type"
+	HeaderRequestId      = "X-Request-Id"
+	HeaderRetryAfter     = "Retry-After"
+	HeaderIdempotencyKey = "Idempotency-Key"
+)
@@ -0,0 +1,107 @@
+package httpclient
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// NewClientFromEnv will instantiate and return a Client whose timeout, retry policy and
+// circuit breaker are configured using the values retrieved from the environment
+func NewClientFromEnv(env utils.Environ, appCtx shared.ApplicationContext, options ...Option) (*Client, error) {
+	newopt := []Option{}
+
+	timeout, OK, err := env.GetInt(TimeoutEnvKey)
+	if err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
+	} else if OK {
+		newopt = append(newopt, WithTimeout(time.Duration(timeout)*time.Second))
+	}
+
+	policy := DefaultRetryPolicy()
+
+	maxRetries, OK, err := env.GetInt(MaxRetriesEnvKey)
+	if err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
+	} else if OK {
+		policy.MaxRetries = maxRetries
+	}
+
+	baseDelay, OK, err := env.GetInt(BaseDelayEnvKey)
+	if err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
+	} else if OK {
+		policy.BaseDelay = time.Duration(baseDelay) * time.Millisecond
+	}
+
+	maxDelay, OK, err := env.GetInt(MaxDelayEnvKey)
+	if err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
+	} else if OK {
+		policy.MaxDelay = time.Duration(maxDelay) * time.Millisecond
+	}
+
+	threshold, OK, err := env.GetInt(FailureThresholdEnvKey)
+	if err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
+	} else if !OK {
+		threshold = DefaultFailureThreshold
+	}
+
+	openTimeout, OK, err := env.GetInt(OpenTimeoutEnvKey)
+	if err != nil {
+		return nil, errs.WithType(err, errs.ErrTypeConfiguration)
+	}
+
+	openFor := DefaultOpenTimeout
+	if OK {
+		openFor = time.Duration(openTimeout) * time.Second
+	}
+
+	newopt = append(newopt, WithRetryPolicy(policy), WithCircuitBreaker(threshold, openFor))
+
+	return NewClient(appCtx, append(newopt, options...)...), nil
+}
+
+// NewClient will instantiate and return a Client that retries idempotent requests using
+// the DefaultRetryPolicy and opens the circuit to a host after DefaultFailureThreshold
+// consecutive failures, unless configured otherwise
+func NewClient(appCtx shared.ApplicationContext, options ...Option) *Client {
+	client := Client{
+		appCtx: appCtx,
+		client: &http.Client{Timeout: DefaultTimeout},
+		codecs: api.DefaultCodecRegistry(),
+		retry:  DefaultRetryPolicy(),
+		breakers: &breakers{
+			threshold: DefaultFailureThreshold,
+			timeout:   DefaultOpenTimeout,
+			hosts:     make(map[string]*breaker),
+			now:       time.Now,
+		},
+		now:    time.Now,
+		jitter: rand.Float64,
+	}
+
+	for _, option := range options {
+		option(&client)
+	}
+
+	client.metrics = newClientMetrics(appCtx)
+
+	return &client
+}
+
+// DefaultRetryPolicy returns the policy used to retry idempotent requests unless another
+// is provided using WithRetryPolicy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultBaseDelay,
+		MaxDelay:   DefaultMaxDelay,
+	}
+}
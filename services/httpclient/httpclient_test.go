@@ -0,0 +1,249 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/services/httpclient"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+type greeting struct {
+	Message string `json:"message" xml:"message"`
+}
+
+type HttpClientTestSuite struct {
+	suite.Suite
+
+	appctx shared.ApplicationContext
+	tracer *mocktracer.MockTracer
+	now    time.Time
+}
+
+func (c *HttpClientTestSuite) SetupTest() {
+	c.tracer = mocktracer.New()
+	c.now = time.Unix(1700000000, 0)
+
+	c.appctx = shared.ApplicationContext{
+		RootCtx: context.Background(),
+		Logger:  utils.NewLogger("INFO"),
+		Tracer:  c.tracer,
+	}
+}
+
+func (c *HttpClientTestSuite) TestCall_PropagatesRequestIdAndTrace() {
+	var headers http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := httpclient.NewClient(c.appctx, httpclient.WithBaseURL(server.URL+"/api"))
+
+	parent := c.tracer.StartSpan("handler")
+	ctx := opentracing.ContextWithSpan(utils.AddFieldToContext(context.Background(), shared.RequestIdContextKey, "req-123"), parent)
+
+	c.Require().NoError(client.Get(ctx, "/greeting", nil))
+	parent.Finish()
+
+	c.Equal("req-123", headers.Get(httpclient.HeaderRequestId))
+
+	spans := c.tracer.FinishedSpans()
+	c.Require().Len(spans, 2)
+
+	child := spans[0]
+	c.Equal("GET "+strings.TrimPrefix(server.URL, "http://"), child.OperationName)
+	c.Equal(parent.Context().(mocktracer.MockSpanContext).SpanID, child.ParentID)
+	c.Equal(uint16(http.StatusNoContent), child.Tag("http.status_code"))
+	c.Equal(headers.Get("Mockpfx-Ids-Spanid"), strconv.Itoa(child.SpanContext.SpanID))
+}
+
+func (c *HttpClientTestSuite) TestCall_DecodesUsingCodecs() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/xml" {
+			w.Header().Set(httpclient.HeaderContentType, "application/xml; charset=utf-8")
+			_, _ = w.Write([]byte(`<greeting><message>bonjour</message></greeting>`))
+			return
+		}
+
+		w.Header().Set(httpclient.HeaderContentType, "application/json")
+		_, _ = w.Write([]byte(`{"message":"hello"}`))
+	}))
+	defer server.Close()
+
+	client := httpclient.NewClient(c.appctx, httpclient.WithBaseURL(server.URL))
+
+	var resp greeting
+
+	c.Require().NoError(client.Post(context.Background(), "/json", greeting{"hi"}, &resp))
+	c.Equal("hello", resp.Message)
+
+	c.Require().NoError(client.Get(context.Background(), "/xml", &resp))
+	c.Equal("bonjour", resp.Message)
+}
+
+func (c *HttpClientTestSuite) TestCall_ErrorStatusesAreTyped() {
+	tests := []struct {
+		status  int
+		errType errs.ErrorType
+	}{
+		{status: http.StatusUnauthorized, errType: errs.ErrTypeAuthentication},
+		{status: http.StatusForbidden, errType: errs.ErrTypeAuthorization},
+		{status: http.StatusNotFound, errType: errs.ErrTypeNotFound},
+		{status: http.StatusUnprocessableEntity, errType: errs.ErrTypeValidation},
+		{status: http.StatusConflict, errType: errs.ErrTypeUnknown},
+	}
+
+	for _, test := range tests {
+		c.Run(http.StatusText(test.status), func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			err := httpclient.NewClient(c.appctx).Get(context.Background(), server.URL, nil)
+
+			c.Require().Error(err)
+			c.Equal(test.errType, errs.GetType(err))
+		})
+	}
+}
+
+func (c *HttpClientTestSuite) TestDo_RetriesIdempotentRequests() {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	collector, err := metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "test")
+	c.Require().NoError(err)
+
+	c.appctx.Collector = collector
+
+	client := httpclient.NewClient(c.appctx, httpclient.WithRetryPolicy(httpclient.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+
+	// a GET is retried until it succeeds...
+	c.Require().NoError(client.Get(context.Background(), server.URL, nil))
+	c.EqualValues(3, calls.Load())
+
+	// whereas a POST is not...
+	err = client.Post(context.Background(), server.URL, greeting{"hi"}, nil)
+	c.Equal(errs.ErrTypeUnavailable, errs.GetType(err))
+	c.EqualValues(4, calls.Load())
+
+	// unless it carries an Idempotency-Key (its body being sent with each attempt)
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"message":"hi"}`))
+	req.Header.Set(httpclient.HeaderIdempotencyKey, "key-1")
+
+	resp, err := client.Do(req)
+	c.Require().NoError(err)
+	resp.Body.Close()
+
+	c.Equal(http.StatusNoContent, resp.StatusCode)
+	c.EqualValues(6, calls.Load())
+
+	scrape := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	c.Contains(scrape.Body.String(), `test_client_requests_total{appName="",appVersion="",environ="",host="`+host+`",hostname="",method="GET",statusCode="503"} 2`)
+	c.Contains(scrape.Body.String(), `test_client_retries_total{appName="",appVersion="",environ="",host="`+host+`",hostname="",method="GET"} 2`)
+	c.Contains(scrape.Body.String(), `test_client_request_duration_seconds_count{appName="",appVersion="",environ="",host="`+host+`",hostname="",method="POST"} 3`)
+}
+
+func (c *HttpClientTestSuite) TestDo_CircuitBreakerOpensAfterFailures() {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	collector, err := metrics.NewCollectorFromEnv(utils.NewEnviron(map[string]string{}), "test")
+	c.Require().NoError(err)
+
+	c.appctx.Collector = collector
+
+	client := httpclient.NewClient(c.appctx,
+		httpclient.WithRetryPolicy(httpclient.RetryPolicy{}),
+		httpclient.WithCircuitBreaker(2, time.Minute),
+		httpclient.WithClock(func() time.Time { return c.now }),
+	)
+
+	for i := 0; i < 2; i++ {
+		c.Equal(errs.ErrTypeUnknown, errs.GetType(client.Get(context.Background(), server.URL, nil)))
+	}
+
+	// the circuit is now open so no request is made...
+	err = client.Get(context.Background(), server.URL, nil)
+	c.ErrorIs(err, httpclient.ErrCircuitOpen)
+	c.Equal(errs.ErrTypeUnavailable, errs.GetType(err))
+	c.EqualValues(2, calls.Load())
+
+	// until the timeout has elapsed, at which point a failed trial re-opens it...
+	c.now = c.now.Add(time.Minute)
+
+	c.Error(client.Get(context.Background(), server.URL, nil))
+	c.ErrorIs(client.Get(context.Background(), server.URL, nil), httpclient.ErrCircuitOpen)
+	c.EqualValues(3, calls.Load())
+
+	// while a successful trial closes it
+	c.now = c.now.Add(time.Minute)
+	healthy.Store(true)
+
+	c.NoError(client.Get(context.Background(), server.URL, nil))
+	c.NoError(client.Get(context.Background(), server.URL, nil))
+	c.EqualValues(5, calls.Load())
+
+	scrape := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	c.Contains(scrape.Body.String(), `test_client_request_errors_total{appName="",appVersion="",environ="",errorType="`+string(errs.ErrTypeUnavailable)+`",host="`+host+`",hostname="",method="GET"} 2`)
+}
+
+func (c *HttpClientTestSuite) TestConstructor_NewClientFromEnv() {
+	_, err := httpclient.NewClientFromEnv(utils.NewEnviron(map[string]string{
+		httpclient.MaxRetriesEnvKey: "lots",
+	}), c.appctx)
+	c.Equal(errs.ErrTypeConfiguration, errs.GetType(err))
+
+	client, err := httpclient.NewClientFromEnv(utils.NewEnviron(map[string]string{
+		httpclient.TimeoutEnvKey:    "5",
+		httpclient.MaxRetriesEnvKey: "0",
+	}), c.appctx)
+	c.Require().NoError(err)
+	c.NotNil(client)
+}
+
+func TestHttpClient(t *testing.T) {
+	suite.Run(t, new(HttpClientTestSuite))
+}
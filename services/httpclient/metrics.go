@@ -0,0 +1,63 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/observability/metrics"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+)
+
+// clientMetrics are the standard set of metrics recorded for each request made by a Client,
+// nil should the application have no metrics collector
+type clientMetrics struct {
+	requests metrics.DimensionedCounter
+	errors   metrics.DimensionedCounter
+	retries  metrics.DimensionedCounter
+	duration metrics.DimensionedHistogram
+	rootCtx  context.Context
+}
+
+func newClientMetrics(appCtx shared.ApplicationContext) *clientMetrics {
+	collector := appCtx.Collector
+	if collector == nil {
+		return nil
+	}
+
+	filterLabels := shared.MetricFilterLabels()
+
+	return &clientMetrics{
+		requests: collector.NewDimensionedCounter("client_requests_total", append(filterLabels, "host", "method", "statusCode")...),
+		errors:   collector.NewDimensionedCounter("client_request_errors_total", append(filterLabels, "host", "method", "errorType")...),
+		retries:  collector.NewDimensionedCounter("client_retries_total", append(filterLabels, "host", "method")...),
+		duration: collector.NewDimensionedHistogram("client_request_duration_seconds", api.DefaultLatencyBuckets, append(filterLabels, "host", "method")...),
+		rootCtx:  appCtx.RootCtx,
+	}
+}
+
+// observe records the outcome of a request, the status code being zero should no response
+// have been received
+func (m *clientMetrics) observe(host, method string, status int, err error, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+
+	filterValues := shared.MetricFilterValues(m.rootCtx)
+
+	m.requests.WithLabelValues(append(filterValues, host, method, fmt.Sprint(status))...).Inc()
+	m.duration.WithLabelValues(append(filterValues, host, method)...).Observe(elapsed.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(append(filterValues, host, method, string(errs.GetType(err)))...).Inc()
+	}
+}
+
+func (m *clientMetrics) retried(host, method string) {
+	if m == nil {
+		return
+	}
+
+	m.retries.WithLabelValues(append(shared.MetricFilterValues(m.rootCtx), host, method)...).Inc()
+}
@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/djmarrerajr/common-lib/services/api"
+)
+
+type Option func(*Client)
+
+// WithHTTPClient will replace the underlying http.Client, i.e. to provide a custom Transport
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithTimeout will limit the duration of each attempt at a request
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.client.Timeout = timeout
+	}
+}
+
+// WithBaseURL will resolve the (relative) URLs given to Call, Get et al. against the base URL
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithRetryPolicy will replace the policy by which idempotent requests are retried, a
+// MaxRetries of zero disabling retries entirely
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithCircuitBreaker will open the circuit to a host once the threshold of consecutive
+// failures is reached, refusing requests to it until the timeout has elapsed... a threshold
+// of zero disables the circuit breaker
+func WithCircuitBreaker(threshold int, timeout time.Duration) Option {
+	return func(c *Client) {
+		c.breakers.threshold = threshold
+		c.breakers.timeout = timeout
+	}
+}
+
+// WithCodecs will replace the registry of Codecs used to encode request bodies and to
+// decode responses
+func WithCodecs(codecs *api.CodecRegistry) Option {
+	return func(c *Client) {
+		c.codecs = codecs
+	}
+}
+
+// WithClock will replace the function used to obtain the current time
+func WithClock(now func() time.Time) Option {
+	return func(c *Client) {
+		c.now = now
+		c.breakers.now = now
+	}
+}
@@ -0,0 +1,375 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/djmarrerajr/common-lib/errs"
+	"github.com/djmarrerajr/common-lib/services/api"
+	"github.com/djmarrerajr/common-lib/shared"
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+var _ shared.HttpClient = new(Client)
+
+// Client makes outbound HTTP requests on behalf of the application... each request carries
+// the tracing headers and X-Request-Id of the context in which it is made, is counted by the
+// 'client_*' metrics and, if idempotent, is retried should it fail.  Requests to a host whose
+// circuit is open are refused with ErrCircuitOpen.
+type Client struct {
+	appCtx   shared.ApplicationContext
+	client   *http.Client
+	codecs   *api.CodecRegistry
+	baseURL  string
+	retry    RetryPolicy
+	breakers *breakers
+	metrics  *clientMetrics
+	now      func() time.Time
+	jitter   func() float64
+}
+
+// RetryPolicy describes how failed requests are retried... the delay before each retry is
+// chosen at random from zero up to the exponentially increasing BaseDelay (capped at the
+// MaxDelay) unless the response carries a Retry-After header
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// Do sends the request, retrying it should it be idempotent (or carry an Idempotency-Key)
+// and fail with a network error or an HTTP-429/502/503/504, returning the final response
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	req = req.Clone(ctx)
+
+	span := c.startSpan(ctx, req)
+	defer span.Finish()
+
+	if reqID, OK := utils.GetFieldValueFromContext[string](ctx, shared.RequestIdContextKey); OK && req.Header.Get(HeaderRequestId) == "" {
+		req.Header.Set(HeaderRequestId, reqID)
+	}
+
+	// nolint: errcheck
+	c.tracer().Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header))
+
+	retryable := isIdempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	var resp *http.Response
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, errs.Wrap(err, errs.ErrTypeInternal, "unable to rewind request body")
+			}
+		}
+
+		resp, err = c.attempt(req)
+
+		if !retryable || attempt >= c.retry.MaxRetries || !shouldRetry(resp, err) {
+			break
+		}
+
+		delay := c.retry.delay(attempt, resp, c.jitter())
+		discard(resp)
+
+		c.metrics.retried(req.URL.Host, req.Method)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		span.SetTag("retries", attempt+1)
+	}
+
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogKV("event", "error", "message", err.Error())
+		return nil, err
+	}
+
+	ext.HTTPStatusCode.Set(span, uint16(resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		ext.Error.Set(span, true)
+	}
+
+	return resp, nil
+}
+
+// Call will send a request, the body of which (if not nil) is encoded as JSON, and decode
+// the response into out (if not nil) using the Codec for its Content-Type... a response
+// with an error status is returned as an error whose type reflects the status
+func (c *Client) Call(ctx context.Context, method, target string, body, out any) error {
+	var reader io.Reader
+
+	if body != nil {
+		codec, _ := c.codecs.Lookup(api.ValueApplicationJson)
+
+		var buff bytes.Buffer
+		if err := codec.Encode(&buff, body); err != nil {
+			return errs.WithTypeFallback(err, errs.ErrTypeMarshal)
+		}
+
+		reader = &buff
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.resolve(target), reader)
+	if err != nil {
+		return errs.WithType(err, errs.ErrTypeConfiguration)
+	}
+
+	req.Header.Set(HeaderAccept, api.ValueApplicationJson)
+	if body != nil {
+		req.Header.Set(HeaderContentType, api.ValueApplicationJson)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer discard(resp)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return responseError(req, resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	return c.decode(resp, out)
+}
+
+// Get will send a GET request, decoding the response into out
+func (c *Client) Get(ctx context.Context, target string, out any) error {
+	return c.Call(ctx, http.MethodGet, target, nil, out)
+}
+
+// Post will send a POST request with the body, decoding the response into out
+func (c *Client) Post(ctx context.Context, target string, body, out any) error {
+	return c.Call(ctx, http.MethodPost, target, body, out)
+}
+
+// Put will send a PUT request with the body, decoding the response into out
+func (c *Client) Put(ctx context.Context, target string, body, out any) error {
+	return c.Call(ctx, http.MethodPut, target, body, out)
+}
+
+// Delete will send a DELETE request, decoding the response into out
+func (c *Client) Delete(ctx context.Context, target string, out any) error {
+	return c.Call(ctx, http.MethodDelete, target, nil, out)
+}
+
+// attempt makes a single attempt at the request, subject to the host's circuit breaker
+func (c *Client) attempt(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+
+	if err := c.breakers.allow(host); err != nil {
+		c.metrics.observe(host, req.Method, 0, err, 0)
+		return nil, err
+	}
+
+	st := c.now()
+	resp, err := c.client.Do(req)
+	elapsed := c.now().Sub(st)
+
+	if err != nil {
+		if req.Context().Err() != nil {
+			c.breakers.release(host)
+		} else {
+			c.breakers.record(host, true)
+		}
+
+		err = transportError(err)
+		c.metrics.observe(host, req.Method, 0, err, elapsed)
+
+		return nil, err
+	}
+
+	c.breakers.record(host, resp.StatusCode >= http.StatusInternalServerError)
+	c.metrics.observe(host, req.Method, resp.StatusCode, nil, elapsed)
+
+	return resp, nil
+}
+
+// decode will decode the response body into out using the Codec for its Content-Type
+func (c *Client) decode(resp *http.Response, out any) error {
+	mediaType := resp.Header.Get(HeaderContentType)
+	if mediaType == "" {
+		mediaType = api.ValueApplicationJson
+	}
+
+	codec, OK := c.codecs.Lookup(mediaType)
+	if !OK {
+		return errs.Errorf(errs.ErrTypeUnsupportedMediaType, "no codec for response of type %s", mediaType)
+	}
+
+	// the Codecs decode requests, so the response is presented as one...
+	if err := codec.Decode(&http.Request{Header: resp.Header, Body: resp.Body}, out); err != nil {
+		return errs.WithTypeFallback(err, errs.ErrTypeUnmarshal)
+	}
+
+	return nil
+}
+
+// resolve returns the target resolved against the base URL, if there is one
+func (c *Client) resolve(target string) string {
+	if c.baseURL == "" {
+		return target
+	}
+
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return target
+	}
+
+	ref, err := url.Parse(target)
+	if err != nil || ref.IsAbs() {
+		return target
+	}
+
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	ref.Path = strings.TrimPrefix(ref.Path, "/")
+
+	return base.ResolveReference(ref).String()
+}
+
+func (c *Client) startSpan(ctx context.Context, req *http.Request) opentracing.Span {
+	options := []opentracing.StartSpanOption{ext.SpanKindRPCClient}
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		options = append(options, opentracing.ChildOf(parent.Context()))
+	}
+
+	span := c.tracer().StartSpan(req.Method+" "+req.URL.Host, options...)
+
+	ext.HTTPMethod.Set(span, req.Method)
+	ext.HTTPUrl.Set(span, req.URL.String())
+	ext.PeerHostname.Set(span, req.URL.Hostname())
+
+	return span
+}
+
+// tracer returns the application's tracer, or the global tracer should it have none
+func (c *Client) tracer() opentracing.Tracer {
+	if c.appCtx.Tracer != nil {
+		return c.appCtx.Tracer
+	}
+
+	return opentracing.GlobalTracer()
+}
+
+// delay returns how long to wait before the retry following the attempt (numbered from zero)
+func (p RetryPolicy) delay(attempt int, resp *http.Response, jitter float64) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get(HeaderRetryAfter)); err == nil && seconds >= 0 {
+			return p.limit(time.Duration(seconds) * time.Second)
+		}
+	}
+
+	backoff := p.limit(time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(attempt))))
+
+	return time.Duration(jitter * float64(backoff))
+}
+
+// limit caps the delay at the MaxDelay, should there be one
+func (p RetryPolicy) limit(delay time.Duration) time.Duration {
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+
+	return delay
+}
+
+// isIdempotent determines whether or not the request may safely be sent more than once
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get(HeaderIdempotencyKey) != ""
+}
+
+// shouldRetry determines whether or not the outcome of an attempt warrants a retry
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, ErrCircuitOpen) && !errors.Is(err, context.Canceled)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// transportError returns the error, that occurred before a response was received, typed
+// as either a timeout or the service being unavailable
+func transportError(err error) error {
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return err
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return errs.WithType(err, errs.ErrTypeTimeout)
+	default:
+		return errs.WithType(err, errs.ErrTypeUnavailable)
+	}
+}
+
+// responseError returns an error, typed according to its status, for a response that
+// indicates the request failed
+func responseError(req *http.Request, resp *http.Response) error {
+	errType := errs.ErrTypeUnknown
+
+	switch resp.StatusCode {
+	case http.StatusUnprocessableEntity:
+		errType = errs.ErrTypeValidation
+	case http.StatusUnauthorized:
+		errType = errs.ErrTypeAuthentication
+	case http.StatusForbidden:
+		errType = errs.ErrTypeAuthorization
+	case http.StatusNotFound:
+		errType = errs.ErrTypeNotFound
+	case http.StatusRequestEntityTooLarge:
+		errType = errs.ErrTypeRequestTooLarge
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		errType = errs.ErrTypeTimeout
+	case http.StatusTooManyRequests:
+		errType = errs.ErrTypeRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		errType = errs.ErrTypeUnavailable
+	}
+
+	return errs.Errorf(errType, "%s %s returned HTTP-%d", req.Method, req.URL.Redacted(), resp.StatusCode)
+}
+
+// discard drains and closes the response body so that the connection may be reused
+func discard(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+
+	// nolint: errcheck
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
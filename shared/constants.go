@@ -12,4 +12,6 @@ const (
 	AppNameContextKey    = "appName"
 	AppVersionContextKey = "appVersion"
 	RequestIdContextKey  = "requestID"
+	SubjectContextKey    = "subject"
+	ClaimsContextKey     = "claims"
 )
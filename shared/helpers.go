@@ -0,0 +1,27 @@
+package shared
+
+import (
+	"context"
+
+	"github.com/djmarrerajr/common-lib/utils"
+)
+
+// MetricFilterLabels returns the labels, common to each of our standard metrics, by which
+// the metrics may be filtered
+func MetricFilterLabels() []string {
+	return []string{EnvironContextKey, HostnameContextKey, AppNameContextKey, AppVersionContextKey}
+}
+
+// MetricFilterValues returns the values of the MetricFilterLabels held within the context
+func MetricFilterValues(ctx context.Context) []string {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	envn, _ := utils.GetFieldValueFromContext[string](ctx, EnvironContextKey)
+	host, _ := utils.GetFieldValueFromContext[string](ctx, HostnameContextKey)
+	appl, _ := utils.GetFieldValueFromContext[string](ctx, AppNameContextKey)
+	vrsn, _ := utils.GetFieldValueFromContext[string](ctx, AppVersionContextKey)
+
+	return []string{envn, host, appl, vrsn}
+}
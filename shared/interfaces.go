@@ -15,4 +15,9 @@ type Servable interface {
 	DefineRequestHandler(string, RequestHandlerFunc, any, ...string)
 }
 
+// HttpClient is used to call other services (see httpclient.Client)
+type HttpClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
 type RequestHandlerFunc func(context.Context, *ApplicationContext, any) (any, int)
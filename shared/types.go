@@ -7,6 +7,7 @@ import (
 	"github.com/go-playground/validator"
 	"github.com/opentracing/opentracing-go"
 
+	"github.com/djmarrerajr/common-lib/observability/health"
 	"github.com/djmarrerajr/common-lib/observability/metrics"
 	"github.com/djmarrerajr/common-lib/services/db"
 	"github.com/djmarrerajr/common-lib/utils"
@@ -17,9 +18,11 @@ type ApplicationContext struct {
 	Logger    utils.Logger        // root logger for the application
 	Tracer    opentracing.Tracer  // tracing service (i.e. Jaeger)
 	Collector metrics.Collector   // metrics collector (i.e. Prometheus)
+	Health    *health.Registry    // registry of liveness/readiness checks
 	Validator *validator.Validate // struct validator
 	Server    Servable            // embedded HTTP/HTTPS server
 	Database  db.Adapter          // embedded Database adapter
+	Client    HttpClient          // instrumented client for outbound requests
 
 	Closer io.Closer
 }